@@ -0,0 +1,80 @@
+// Package analytics classifies scans of dynamic codes into coarse,
+// anonymous dimensions: the kind of device, its operating system and the
+// country. Nothing here keeps addresses or user agents; callers only
+// store counters.
+package analytics
+
+import "strings"
+
+// Device classes.
+const (
+	Mobile  = "mobile"
+	Tablet  = "tablet"
+	Desktop = "desktop"
+	Bot     = "bot"
+	Other   = "other"
+)
+
+// Devices lists the device classes in display order.
+var Devices = []string{Mobile, Tablet, Desktop, Bot, Other}
+
+// Unknown is the country of scans whose country cannot be told.
+const Unknown = "unknown"
+
+// Device returns the device class of a User-Agent header.
+func Device(ua string) string {
+	s := strings.ToLower(ua)
+	switch {
+	case s == "":
+		return Other
+	case containsAny(s, "bot", "crawler", "spider", "preview", "curl/", "wget/", "python-", "go-http-client", "facebookexternalhit", "whatsapp", "slackbot", "discordbot"):
+		return Bot
+	case containsAny(s, "ipad", "tablet", "kindle", "silk/", "playbook") ||
+		(strings.Contains(s, "android") && !strings.Contains(s, "mobile")):
+		return Tablet
+	case containsAny(s, "mobile", "iphone", "ipod", "android", "windows phone", "blackberry", "opera mini"):
+		return Mobile
+	case containsAny(s, "windows", "macintosh", "mac os x", "x11", "linux", "cros"):
+		return Desktop
+	}
+	return Other
+}
+
+// Operating systems.
+const (
+	IOS      = "ios"
+	Android  = "android"
+	Windows  = "windows"
+	MacOS    = "macos"
+	Linux    = "linux"
+	ChromeOS = "chromeos"
+)
+
+// OS returns the operating system of a User-Agent header, or Other.
+func OS(ua string) string {
+	s := strings.ToLower(ua)
+	switch {
+	case containsAny(s, "iphone", "ipad", "ipod"):
+		return IOS
+	case strings.Contains(s, "android"):
+		return Android
+	case strings.Contains(s, "windows"):
+		return Windows
+	case strings.Contains(s, "cros"):
+		return ChromeOS
+	case containsAny(s, "macintosh", "mac os x"):
+		return MacOS
+	case strings.Contains(s, "linux"):
+		return Linux
+	}
+	return Other
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+)
+
+// GeoDB maps IP addresses to ISO 3166 country codes using a local list of
+// address ranges. It is read-only and safe for concurrent use.
+type GeoDB struct {
+	ranges []geoRange
+}
+
+type geoRange struct {
+	start, end netip.Addr
+	country    string
+}
+
+// LoadGeoCSV reads a country database in the CSV layout of the free DB-IP
+// "IP to Country Lite" download: one range per line as
+// first_address,last_address,country_code, IPv4 and IPv6 alike.
+func LoadGeoCSV(path string) (*GeoDB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+	db := &GeoDB{}
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(rec) < 3 {
+			return nil, fmt.Errorf("%s:%d: want first_address,last_address,country_code", path, line)
+		}
+		start, err1 := netip.ParseAddr(strings.TrimSpace(rec[0]))
+		end, err2 := netip.ParseAddr(strings.TrimSpace(rec[1]))
+		if err1 != nil || err2 != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("%s:%d: invalid address range", path, line)
+		}
+		if start.Is4() != end.Is4() || end.Less(start) {
+			return nil, fmt.Errorf("%s:%d: invalid address range", path, line)
+		}
+		country := strings.ToUpper(strings.TrimSpace(rec[2]))
+		if len(country) != 2 {
+			country = Unknown
+		}
+		db.ranges = append(db.ranges, geoRange{start, end, country})
+	}
+	slices.SortFunc(db.ranges, func(a, b geoRange) int { return a.start.Compare(b.start) })
+	return db, nil
+}
+
+// Country returns the country code of addr, or Unknown. A nil GeoDB knows
+// no countries.
+func (db *GeoDB) Country(addr netip.Addr) string {
+	if db == nil || !addr.IsValid() {
+		return Unknown
+	}
+	addr = addr.Unmap()
+	i, found := slices.BinarySearchFunc(db.ranges, addr, func(r geoRange, a netip.Addr) int { return r.start.Compare(a) })
+	if !found {
+		i--
+	}
+	if i < 0 || addr.Less(db.ranges[i].start) || db.ranges[i].end.Less(addr) {
+		return Unknown
+	}
+	return db.ranges[i].country
+}
+
+// Len returns the number of ranges in the database.
+func (db *GeoDB) Len() int {
+	return len(db.ranges)
+}
@@ -0,0 +1,175 @@
+// Package barcode implements encoders for linear barcodes: Code 128 and
+// GS1-128, EAN-13 and UPC-A, Code 39 and ITF-14. Symbols are returned as
+// module runs with their human-readable text so they can be drawn at any
+// resolution, or as vectors.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Symbology is a linear barcode type.
+type Symbology int
+
+const (
+	Code128 Symbology = iota
+	GS1128
+	EAN13
+	UPCA
+	Code39
+	ITF14
+)
+
+// ParseSymbology parses the barcode type names accepted by the API.
+func ParseSymbology(s string) (Symbology, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "code128":
+		return Code128, nil
+	case "gs1-128", "gs1128", "ean128":
+		return GS1128, nil
+	case "ean13", "ean-13":
+		return EAN13, nil
+	case "upca", "upc-a", "upc":
+		return UPCA, nil
+	case "code39":
+		return Code39, nil
+	case "itf14", "itf-14":
+		return ITF14, nil
+	}
+	return Code128, fmt.Errorf("unsupported barcode type %q (use code128, gs1-128, ean13, upca, code39 or itf14)", s)
+}
+
+// String returns the API name of the symbology.
+func (s Symbology) String() string {
+	return [...]string{"code128", "gs1-128", "ean13", "upca", "code39", "itf14"}[s]
+}
+
+// ErrInvalidData is returned, wrapped, when the payload cannot be encoded
+// in the requested symbology or carries a wrong check digit.
+var ErrInvalidData = errors.New("invalid barcode data")
+
+// invalid returns an ErrInvalidData error with a formatted reason.
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidData, fmt.Sprintf(format, args...))
+}
+
+// Options controls the optional features of the symbologies.
+type Options struct {
+	// Checksum appends the optional modulo 43 check character to Code 39.
+	Checksum bool
+}
+
+// TextSpan is a piece of human-readable text centred under the modules
+// [Start, End). Spans may reach into the quiet zones, as the leading digit
+// of EAN-13 does.
+type TextSpan struct {
+	Text       string
+	Start, End int
+}
+
+// Code is an encoded linear symbol.
+type Code struct {
+	Symbology Symbology
+	// Data is the encoded payload including any computed check digits.
+	Data string
+	// Modules holds one entry per module, true for bars.
+	Modules []bool
+	// Guards marks the bars that extend into the text area (EAN/UPC guard
+	// patterns); nil when none do.
+	Guards []bool
+	// Text is the human-readable interpretation.
+	Text []TextSpan
+	// QuietLeft and QuietRight are the minimum quiet zones in modules.
+	QuietLeft, QuietRight int
+	// Bearer is the thickness in modules of the bearer bars above and below
+	// the symbol, 0 for none.
+	Bearer int
+	// Height is the recommended bar height in modules.
+	Height int
+}
+
+// Encode encodes data in the symbology. Check digits are computed when
+// data omits them and verified when it includes them.
+func Encode(sym Symbology, data string, opts Options) (*Code, error) {
+	if data == "" {
+		return nil, invalid("data is empty")
+	}
+	switch sym {
+	case GS1128:
+		return encodeGS1128(data)
+	case EAN13:
+		return encodeEAN13(data)
+	case UPCA:
+		return encodeUPCA(data)
+	case Code39:
+		return encodeCode39(data, opts.Checksum)
+	case ITF14:
+		return encodeITF14(data)
+	}
+	return encodeCode128(data)
+}
+
+// appendPattern appends the modules of a bar/space width pattern such as
+// "211214", starting with a bar.
+func appendPattern(modules []bool, widths string) []bool {
+	bar := true
+	for _, w := range widths {
+		for i := 0; i < int(w-'0'); i++ {
+			modules = append(modules, bar)
+		}
+		bar = !bar
+	}
+	return modules
+}
+
+// appendBits appends modules from a string of '1' (bar) and '0' (space).
+func appendBits(modules []bool, bits string) []bool {
+	for _, b := range bits {
+		modules = append(modules, b == '1')
+	}
+	return modules
+}
+
+// digitsOnly reports whether s is made of ASCII digits only.
+func digitsOnly(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// gs1CheckDigit returns the GS1 modulo 10 check digit of digits, weighting
+// them 3 and 1 alternately from the right.
+func gs1CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// withCheckDigit returns digits completed to length n with a GS1 check
+// digit, or verifies the check digit when digits already has length n.
+func withCheckDigit(name, digits string, n int) (string, error) {
+	if !digitsOnly(digits) {
+		return "", invalid("%s takes digits only", name)
+	}
+	switch len(digits) {
+	case n - 1:
+		return digits + string(gs1CheckDigit(digits)), nil
+	case n:
+		if want := gs1CheckDigit(digits[:n-1]); digits[n-1] != want {
+			return "", invalid("%s check digit is %c, expected %c", name, digits[n-1], want)
+		}
+		return digits, nil
+	}
+	return "", invalid("%s takes %d digits, or %d without the check digit", name, n, n-1)
+}
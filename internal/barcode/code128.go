@@ -0,0 +1,188 @@
+package barcode
+
+import "strings"
+
+// code128Patterns holds the bar/space widths of the Code 128 symbol values
+// 0-105 followed by the stop pattern.
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// Code 128 code sets and special symbol values.
+const (
+	setA = iota
+	setB
+	setC
+
+	valShift  = 98
+	valCodeC  = 99
+	valCodeB  = 100
+	valCodeA  = 101
+	valFNC1   = 102
+	valStartA = 103
+	valStop   = 106
+
+	// fnc1 marks a FNC1 function character in the input.
+	fnc1 = -1
+)
+
+// codeSetValues holds the value that switches to a code set from within
+// each of the others.
+var codeSetValues = [3]int{valCodeA, valCodeB, valCodeC}
+
+// code128Value returns the symbol value of ch in a code set, or -1 when the
+// set cannot encode it. In set C, ch is the two-digit number.
+func code128Value(set, ch int) int {
+	if ch == fnc1 {
+		return valFNC1
+	}
+	switch set {
+	case setA:
+		if ch < 32 {
+			return ch + 64
+		}
+		if ch < 96 {
+			return ch - 32
+		}
+	case setB:
+		if ch >= 32 && ch < 128 {
+			return ch - 32
+		}
+	}
+	return -1
+}
+
+// encodeCode128 encodes printable ASCII or control characters as Code 128.
+func encodeCode128(data string) (*Code, error) {
+	input := make([]int, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] >= 128 {
+			return nil, invalid("code128 supports ASCII characters only")
+		}
+		input = append(input, int(data[i]))
+	}
+	text := strings.Map(func(r rune) rune {
+		if r < 32 || r == 127 {
+			return ' '
+		}
+		return r
+	}, data)
+	return buildCode128(Code128, data, input, text)
+}
+
+// buildCode128 lays out input (ASCII values and fnc1 markers) with the
+// fewest symbols, adds the check symbol and returns the code.
+func buildCode128(sym Symbology, data string, input []int, text string) (*Code, error) {
+	values := code128Values(input)
+	sum := values[0]
+	for i, v := range values[1:] {
+		sum += (i + 1) * v
+	}
+	values = append(values, sum%103, valStop)
+
+	var modules []bool
+	for _, v := range values {
+		modules = appendPattern(modules, code128Patterns[v])
+	}
+	return &Code{
+		Symbology:  sym,
+		Data:       data,
+		Modules:    modules,
+		Text:       []TextSpan{{Text: text, Start: 0, End: len(modules)}},
+		QuietLeft:  10,
+		QuietRight: 10,
+		Height:     max(32, len(modules)*15/100),
+	}, nil
+}
+
+// code128Values returns the start symbol and data symbols for input, using
+// the code sets, switches and shifts that give the shortest symbol.
+func code128Values(input []int) []int {
+	n := len(input)
+	const inf = 1 << 30
+	// cost[i][s] is the number of symbols needed for input[i:] when in code
+	// set s at position i.
+	cost := make([][3]int, n+1)
+	// stay returns the cost of encoding the next character without leaving
+	// code set s.
+	stay := func(i, s int) int {
+		switch {
+		case s == setC && input[i] == fnc1:
+			return 1 + cost[i+1][s]
+		case s == setC && i+1 < n && isDigit(input[i]) && isDigit(input[i+1]):
+			return 1 + cost[i+2][s]
+		case s == setC:
+			return inf
+		case code128Value(s, input[i]) >= 0:
+			return 1 + cost[i+1][s]
+		case code128Value(1-s, input[i]) >= 0:
+			// Shift encodes a single character from the other of A and B.
+			return 2 + cost[i+1][s]
+		}
+		return inf
+	}
+	for i := n - 1; i >= 0; i-- {
+		var stays [3]int
+		for s := range 3 {
+			stays[s] = stay(i, s)
+		}
+		// Switching code sets costs one symbol.
+		for s := range 3 {
+			cost[i][s] = stays[s]
+			for t := range 3 {
+				if t != s && stays[t] < inf && 1+stays[t] < cost[i][s] {
+					cost[i][s] = 1 + stays[t]
+				}
+			}
+		}
+	}
+
+	set := setB
+	for s := range 3 {
+		if cost[0][s] < cost[0][set] {
+			set = s
+		}
+	}
+	values := []int{valStartA + set}
+	for i := 0; i < n; {
+		if stay(i, set) > cost[i][set] {
+			// A switch is cheaper: move to the set the cost table came from.
+			for t := range 3 {
+				if t != set && 1+stay(i, t) == cost[i][set] {
+					values = append(values, codeSetValues[t])
+					set = t
+					break
+				}
+			}
+			continue
+		}
+		switch {
+		case set == setC && input[i] == fnc1:
+			values = append(values, valFNC1)
+			i++
+		case set == setC:
+			values = append(values, (input[i]-'0')*10+input[i+1]-'0')
+			i += 2
+		case code128Value(set, input[i]) >= 0:
+			values = append(values, code128Value(set, input[i]))
+			i++
+		default:
+			values = append(values, valShift, code128Value(1-set, input[i]))
+			i++
+		}
+	}
+	return values
+}
+
+// isDigit reports whether ch is an ASCII digit.
+func isDigit(ch int) bool { return ch >= '0' && ch <= '9' }
@@ -0,0 +1,78 @@
+package barcode
+
+import "strings"
+
+// code39Chars lists the Code 39 characters in check value order.
+const code39Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-. $/+%"
+
+// code39Patterns holds the narrow (n) and wide (w) elements of each
+// character in code39Chars, alternating bar and space, followed by the
+// start/stop character '*'.
+var code39Patterns = [...]string{
+	"nnnwwnwnn", "wnnwnnnnw", "nnwwnnnnw", "wnwwnnnnn", "nnnwwnnnw",
+	"wnnwwnnnn", "nnwwwnnnn", "nnnwnnwnw", "wnnwnnwnn", "nnwwnnwnn",
+	"wnnnnwnnw", "nnwnnwnnw", "wnwnnwnnn", "nnnnwwnnw", "wnnnwwnnn",
+	"nnwnwwnnn", "nnnnnwwnw", "wnnnnwwnn", "nnwnnwwnn", "nnnnwwwnn",
+	"wnnnnnnww", "nnwnnnnww", "wnwnnnnwn", "nnnnwnnww", "wnnnwnnwn",
+	"nnwnwnnwn", "nnnnnnwww", "wnnnnnwwn", "nnwnnnwwn", "nnnnwnwwn",
+	"wwnnnnnnw", "nwwnnnnnw", "wwwnnnnnn", "nwnnwnnnw", "wwnnwnnnn",
+	"nwwnwnnnn", "nwnnnnwnw", "wwnnnnwnn", "nwwnnnwnn", "nwnwnwnnn",
+	"nwnwnnnwn", "nwnnnwnwn", "nnnwnwnwn",
+	"nwnnwnwnn",
+}
+
+// wideRatio is the width of wide elements in Code 39 and ITF-14, in
+// modules.
+const wideRatio = 3
+
+// appendNarrowWide appends the modules of a narrow/wide element pattern,
+// starting with a bar when bar is true.
+func appendNarrowWide(modules []bool, pattern string, bar bool) []bool {
+	for _, e := range pattern {
+		w := 1
+		if e == 'w' {
+			w = wideRatio
+		}
+		for i := 0; i < w; i++ {
+			modules = append(modules, bar)
+		}
+		bar = !bar
+	}
+	return modules
+}
+
+// encodeCode39 encodes uppercase letters, digits and "-. $/+%" as Code 39,
+// optionally followed by the modulo 43 check character.
+func encodeCode39(data string, checksum bool) (*Code, error) {
+	sum := 0
+	for i := 0; i < len(data); i++ {
+		v := strings.IndexByte(code39Chars, data[i])
+		if v < 0 {
+			return nil, invalid("code39 cannot encode %q (use 0-9, A-Z and \"-. $/+%%\")", data[i])
+		}
+		sum += v
+	}
+	if checksum {
+		data += string(code39Chars[sum%43])
+	}
+
+	start := code39Patterns[len(code39Patterns)-1]
+	modules := appendNarrowWide(nil, start, true)
+	for i := 0; i < len(data); i++ {
+		// Characters are separated by a narrow space.
+		modules = append(modules, false)
+		modules = appendNarrowWide(modules, code39Patterns[strings.IndexByte(code39Chars, data[i])], true)
+	}
+	modules = append(modules, false)
+	modules = appendNarrowWide(modules, start, true)
+
+	return &Code{
+		Symbology:  Code39,
+		Data:       data,
+		Modules:    modules,
+		Text:       []TextSpan{{Text: "*" + data + "*", Start: 0, End: len(modules)}},
+		QuietLeft:  10,
+		QuietRight: 10,
+		Height:     max(32, len(modules)*15/100),
+	}, nil
+}
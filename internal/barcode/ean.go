@@ -0,0 +1,110 @@
+package barcode
+
+// eanL holds the left-hand odd parity (set A) digit patterns; the even
+// parity (set B) patterns are their mirror images complemented and the
+// right-hand (set C) patterns their complements.
+var eanL = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+// eanParity holds, for each leading digit, the parity (A or B) of the six
+// left-hand digits.
+var eanParity = [10]string{
+	"AAAAAA", "AABABB", "AABBAB", "AABBBA", "ABAABB",
+	"ABBAAB", "ABBBAA", "ABABAB", "ABABBA", "ABBABA",
+}
+
+// eanPattern returns the modules of digit d in set A, B or C.
+func eanPattern(d byte, set byte) string {
+	p := []byte(eanL[d-'0'])
+	if set != 'A' {
+		for i := range p {
+			p[i] ^= 1 // '0' <-> '1'
+		}
+	}
+	if set == 'B' {
+		for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+			p[i], p[j] = p[j], p[i]
+		}
+	}
+	return string(p)
+}
+
+// encodeEAN13 encodes 12 digits plus a check digit as EAN-13.
+func encodeEAN13(data string) (*Code, error) {
+	digits, err := withCheckDigit("ean13", data, 13)
+	if err != nil {
+		return nil, err
+	}
+	code := buildEAN13(digits)
+	code.Symbology = EAN13
+	code.Text = []TextSpan{
+		{Text: digits[:1], Start: -8, End: -1},
+		{Text: digits[1:7], Start: 3, End: 45},
+		{Text: digits[7:], Start: 50, End: 92},
+	}
+	return code, nil
+}
+
+// encodeUPCA encodes 11 digits plus a check digit as UPC-A, which is an
+// EAN-13 symbol with a leading zero.
+func encodeUPCA(data string) (*Code, error) {
+	digits, err := withCheckDigit("upca", data, 12)
+	if err != nil {
+		return nil, err
+	}
+	code := buildEAN13("0" + digits)
+	code.Symbology = UPCA
+	code.Data = digits
+	// The bars of the number system and check digits are as long as the
+	// guard bars, and those digits sit outside the symbol.
+	for i := 3; i < 10; i++ {
+		code.Guards[i] = code.Modules[i]
+		code.Guards[85+i-3] = code.Modules[85+i-3]
+	}
+	code.QuietLeft, code.QuietRight = 9, 9
+	code.Text = []TextSpan{
+		{Text: digits[:1], Start: -8, End: -1},
+		{Text: digits[1:6], Start: 10, End: 45},
+		{Text: digits[6:11], Start: 50, End: 85},
+		{Text: digits[11:], Start: 96, End: 103},
+	}
+	return code, nil
+}
+
+// buildEAN13 lays out 13 digits with their guard patterns.
+func buildEAN13(digits string) *Code {
+	var modules []bool
+	guards := make([]bool, 95)
+	markGuard := func(from int) {
+		for i := from; i < len(modules); i++ {
+			guards[i] = modules[i]
+		}
+	}
+
+	modules = appendBits(modules, "101")
+	markGuard(0)
+	parity := eanParity[digits[0]-'0']
+	for i := 1; i <= 6; i++ {
+		modules = appendBits(modules, eanPattern(digits[i], parity[i-1]))
+	}
+	center := len(modules)
+	modules = appendBits(modules, "01010")
+	markGuard(center)
+	for i := 7; i <= 12; i++ {
+		modules = appendBits(modules, eanPattern(digits[i], 'C'))
+	}
+	end := len(modules)
+	modules = appendBits(modules, "101")
+	markGuard(end)
+
+	return &Code{
+		Data:       digits,
+		Modules:    modules,
+		Guards:     guards,
+		QuietLeft:  11,
+		QuietRight: 7,
+		Height:     69,
+	}
+}
@@ -0,0 +1,159 @@
+package barcode
+
+import (
+	"slices"
+	"strings"
+)
+
+// gs1AI describes the data field of a GS1 Application Identifier.
+type gs1AI struct {
+	min, max int // data length
+	numeric  bool
+	check    bool // the last digit is a GS1 check digit
+	date     bool // YYMMDD
+}
+
+// gs1AIs lists the Application Identifiers most used on trade items and
+// logistic units. The 4-digit measure AIs 310n-369n are handled by
+// lookupAI.
+var gs1AIs = map[string]gs1AI{
+	"00":   {min: 18, max: 18, numeric: true, check: true}, // SSCC
+	"01":   {min: 14, max: 14, numeric: true, check: true}, // GTIN
+	"02":   {min: 14, max: 14, numeric: true, check: true}, // GTIN of contained items
+	"10":   {min: 1, max: 20},                              // batch or lot
+	"11":   {min: 6, max: 6, numeric: true, date: true},    // production date
+	"12":   {min: 6, max: 6, numeric: true, date: true},    // due date
+	"13":   {min: 6, max: 6, numeric: true, date: true},    // packaging date
+	"15":   {min: 6, max: 6, numeric: true, date: true},    // best before
+	"16":   {min: 6, max: 6, numeric: true, date: true},    // sell by
+	"17":   {min: 6, max: 6, numeric: true, date: true},    // expiry
+	"20":   {min: 2, max: 2, numeric: true},                // variant
+	"21":   {min: 1, max: 20},                              // serial number
+	"22":   {min: 1, max: 20},                              // consumer product variant
+	"240":  {min: 1, max: 30},                              // additional product id
+	"241":  {min: 1, max: 30},                              // customer part number
+	"250":  {min: 1, max: 30},                              // secondary serial number
+	"251":  {min: 1, max: 30},                              // reference to source entity
+	"30":   {min: 1, max: 8, numeric: true},                // variable count
+	"37":   {min: 1, max: 8, numeric: true},                // count of trade items
+	"400":  {min: 1, max: 30},                              // customer purchase order
+	"401":  {min: 1, max: 30},                              // consignment number
+	"402":  {min: 17, max: 17, numeric: true, check: true}, // shipment id
+	"403":  {min: 1, max: 30},                              // routing code
+	"410":  {min: 13, max: 13, numeric: true, check: true}, // ship to GLN
+	"411":  {min: 13, max: 13, numeric: true, check: true}, // bill to GLN
+	"412":  {min: 13, max: 13, numeric: true, check: true}, // purchased from GLN
+	"413":  {min: 13, max: 13, numeric: true, check: true}, // ship for GLN
+	"414":  {min: 13, max: 13, numeric: true, check: true}, // location GLN
+	"415":  {min: 13, max: 13, numeric: true, check: true}, // invoicing party GLN
+	"420":  {min: 1, max: 20},                              // ship to postal code
+	"421":  {min: 4, max: 12},                              // ship to postal code with ISO country
+	"422":  {min: 3, max: 3, numeric: true},                // country of origin
+	"8005": {min: 6, max: 6, numeric: true},                // price per unit
+	"8020": {min: 1, max: 25},                              // payment slip reference
+	"90":   {min: 1, max: 30},                              // mutually agreed
+}
+
+// gs1Predefined holds the 2-digit AI prefixes whose fields have a length
+// fixed by the standard; all other fields end with FNC1 unless they are
+// last.
+var gs1Predefined = []string{
+	"00", "01", "02", "03", "04", "11", "12", "13", "14", "15", "16",
+	"17", "18", "19", "20", "31", "32", "33", "34", "35", "36", "41",
+}
+
+// lookupAI returns the definition of an Application Identifier.
+func lookupAI(ai string) (gs1AI, bool) {
+	if def, ok := gs1AIs[ai]; ok {
+		return def, true
+	}
+	if len(ai) == 4 && ai[0] == '3' && ai[1] >= '1' && ai[1] <= '6' {
+		// Trade measures: the fourth digit is the decimal point position.
+		return gs1AI{min: 6, max: 6, numeric: true}, true
+	}
+	if len(ai) == 2 && ai >= "91" && ai <= "99" {
+		// Company internal information.
+		return gs1AI{min: 1, max: 90}, true
+	}
+	return gs1AI{}, false
+}
+
+// encodeGS1128 encodes element strings written as "(01)09501101530003(10)AB12"
+// as GS1-128. GTIN, SSCC and GLN fields given without their check digit get
+// one appended.
+func encodeGS1128(data string) (*Code, error) {
+	if !strings.HasPrefix(data, "(") {
+		return nil, invalid("gs1-128 data must be written as (AI)value pairs, e.g. (01)09501101530003(10)AB12")
+	}
+	var input []int
+	var text strings.Builder
+	rest := data
+	for rest != "" {
+		end := strings.IndexByte(rest, ')')
+		if !strings.HasPrefix(rest, "(") || end < 0 {
+			return nil, invalid("gs1-128 data must be written as (AI)value pairs")
+		}
+		ai := rest[1:end]
+		rest = rest[end+1:]
+		value := rest
+		if next := strings.IndexByte(rest, '('); next >= 0 {
+			value, rest = rest[:next], rest[next:]
+		} else {
+			rest = ""
+		}
+
+		def, ok := lookupAI(ai)
+		if !digitsOnly(ai) || !ok {
+			return nil, invalid("unsupported application identifier (%s)", ai)
+		}
+		if def.check && len(value) == def.max-1 {
+			value += string(gs1CheckDigit(value))
+		}
+		if err := def.validate(ai, value); err != nil {
+			return nil, err
+		}
+
+		// FNC1 in first position marks the symbol as GS1-128; later ones
+		// end variable-length fields.
+		if len(input) == 0 {
+			input = append(input, fnc1)
+		}
+		for _, ch := range ai + value {
+			input = append(input, int(ch))
+		}
+		if !slices.Contains(gs1Predefined, ai[:2]) && rest != "" {
+			input = append(input, fnc1)
+		}
+		text.WriteString("(" + ai + ")" + value)
+	}
+	return buildCode128(GS1128, text.String(), input, text.String())
+}
+
+// validate checks a field value against the AI definition.
+func (def gs1AI) validate(ai, value string) error {
+	if len(value) < def.min || len(value) > def.max {
+		if def.min == def.max {
+			return invalid("(%s) takes %d characters", ai, def.min)
+		}
+		return invalid("(%s) takes %d to %d characters", ai, def.min, def.max)
+	}
+	if def.numeric && !digitsOnly(value) {
+		return invalid("(%s) takes digits only", ai)
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < 33 || value[i] > 126 {
+			return invalid("(%s) contains a character GS1 does not allow", ai)
+		}
+	}
+	if def.check {
+		if want := gs1CheckDigit(value[:len(value)-1]); value[len(value)-1] != want {
+			return invalid("(%s) check digit is %c, expected %c", ai, value[len(value)-1], want)
+		}
+	}
+	if def.date {
+		if month := value[2:4]; month < "01" || month > "12" || value[4:6] > "31" {
+			return invalid("(%s) is not a valid YYMMDD date", ai)
+		}
+	}
+	return nil
+}
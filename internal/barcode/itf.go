@@ -0,0 +1,40 @@
+package barcode
+
+// itfPatterns holds the narrow (n) and wide (w) elements of each digit in
+// Interleaved 2 of 5.
+var itfPatterns = [10]string{
+	"nnwwn", "wnnnw", "nwnnw", "wwnnn", "nnwnw",
+	"wnwnn", "nwwnn", "nnnww", "wnnwn", "nwnwn",
+}
+
+// encodeITF14 encodes 13 digits plus a check digit as ITF-14, the
+// Interleaved 2 of 5 symbol used for GTIN-14 on outer cases.
+func encodeITF14(data string) (*Code, error) {
+	digits, err := withCheckDigit("itf14", data, 14)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := appendNarrowWide(nil, "nnnn", true)
+	// Each pair of digits is interleaved: the first in the bars, the second
+	// in the spaces.
+	for i := 0; i < len(digits); i += 2 {
+		bars, spaces := itfPatterns[digits[i]-'0'], itfPatterns[digits[i+1]-'0']
+		for j := 0; j < 5; j++ {
+			modules = appendNarrowWide(modules, bars[j:j+1], true)
+			modules = appendNarrowWide(modules, spaces[j:j+1], false)
+		}
+	}
+	modules = appendNarrowWide(modules, "wnn", true)
+
+	return &Code{
+		Symbology:  ITF14,
+		Data:       digits,
+		Modules:    modules,
+		Text:       []TextSpan{{Text: digits, Start: 0, End: len(modules)}},
+		QuietLeft:  10,
+		QuietRight: 10,
+		Bearer:     4,
+		Height:     40,
+	}, nil
+}
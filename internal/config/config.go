@@ -0,0 +1,231 @@
+// Package config holds the settings of the server. Each setting has a
+// default, and can be set in a TOML file, by an environment variable and
+// by a command-line flag, each overriding the one before.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/logging"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/screen"
+)
+
+// Config is the configuration of the server. The toml tags name the keys
+// of the file, env the environment variables and comment describes the
+// setting in -help and --print-config. Settings tagged secret are not
+// printed.
+type Config struct {
+	Server  Server  `toml:"server"`
+	Storage Storage `toml:"storage"`
+	Render  Render  `toml:"render"`
+	Limits  Limits  `toml:"limits"`
+	URLs    URLs    `toml:"urls"`
+	Keys    Keys    `toml:"keys"`
+	Log     Log     `toml:"log"`
+	Metrics Metrics `toml:"metrics"`
+}
+
+// Server is where and how the server listens.
+type Server struct {
+	Host           string   `toml:"host" env:"HOST" comment:"Address to listen on; empty listens on all."`
+	Port           int      `toml:"port" env:"PORT" comment:"Port to listen on."`
+	StaticDir      string   `toml:"static_dir" env:"STATIC_DIR" comment:"Directory served at /web/static."`
+	TrustedProxies []string `toml:"trusted_proxies" env:"TRUSTED_PROXIES" comment:"Addresses and CIDR ranges whose X-Forwarded-For is believed."`
+
+	ReadHeaderTimeout Duration `toml:"read_header_timeout" env:"READ_HEADER_TIMEOUT" comment:"Time to read the headers of a request."`
+	ReadTimeout       Duration `toml:"read_timeout" env:"READ_TIMEOUT" comment:"Time to read a whole request, body included."`
+	WriteTimeout      Duration `toml:"write_timeout" env:"WRITE_TIMEOUT" comment:"Time to answer a request once its headers are read; long renders need it generous."`
+	IdleTimeout       Duration `toml:"idle_timeout" env:"IDLE_TIMEOUT" comment:"How long a keep-alive connection waits for the next request."`
+	ShutdownDelay     Duration `toml:"shutdown_delay" env:"SHUTDOWN_DELAY" comment:"Time between failing /readyz and closing the listener on SIGTERM, for load balancers to notice."`
+	ShutdownTimeout   Duration `toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" comment:"Time requests and jobs in progress each get to finish on SIGTERM; both start winding down at once."`
+}
+
+// Storage is where the server keeps its data.
+type Storage struct {
+	DBPath     string   `toml:"db_path" env:"DB_PATH" comment:"bbolt database of presets, links, campaigns and API keys."`
+	JobsDir    string   `toml:"jobs_dir" env:"JOBS_DIR" comment:"Directory of job states and artifacts."`
+	JobTTL     Duration `toml:"job_ttl" env:"JOB_TTL" comment:"How long finished jobs are kept."`
+	JobWorkers int      `toml:"job_workers" env:"JOB_WORKERS" comment:"Jobs run at once."`
+	UploadsDir string   `toml:"uploads_dir" env:"UPLOADS_DIR" comment:"Directory of logo PNGs."`
+	GeoIPDB    string   `toml:"geoip_db" env:"GEOIP_DB" comment:"CSV of IP ranges and countries for scan analytics; empty counts countries as unknown."`
+}
+
+// Render shapes rendered images and how they are cached.
+type Render struct {
+	DownloadSize   int      `toml:"download_size" env:"DOWNLOAD_SIZE" comment:"Minimum edge in pixels of download-size images."`
+	PaddingPercent int      `toml:"padding_percent" env:"PADDING_PERCENT" comment:"Padding around QR codes, in percent of their edge."`
+	ImageMaxAge    Duration `toml:"image_max_age" env:"IMAGE_MAX_AGE" comment:"Cache-Control max-age of images."`
+	MaxURLLength   int      `toml:"max_url_length" env:"MAX_URL_LENGTH" comment:"Longest URL encoded, campaign parameters included."`
+	Concurrency    int      `toml:"concurrency" env:"RENDER_CONCURRENCY" comment:"Images rendered at once; 0 is no bound."`
+	CacheMB        int      `toml:"cache_mb" env:"RENDER_CACHE_MB" comment:"Memory of the render cache in MiB; 0 turns the cache off."`
+	CacheDir       string   `toml:"cache_dir" env:"RENDER_CACHE_DIR" comment:"Directory of the disk tier of the render cache; empty keeps it in memory only."`
+	CacheDiskMB    int      `toml:"cache_disk_mb" env:"RENDER_CACHE_DISK_MB" comment:"Size of the disk tier of the render cache in MiB."`
+}
+
+// Limits are the rate limits, as name=N/period:burst policies.
+type Limits struct {
+	Rate    string `toml:"rate" env:"RATE_LIMITS" comment:"Rate limits per client address over the defaults, like render=600/1m:200,batch=off."`
+	KeyRate string `toml:"key_rate" env:"KEY_RATE_LIMITS" comment:"Rate limits per API key over the defaults."`
+}
+
+// URLs configures the screening of the URLs to encode.
+type URLs struct {
+	Screen    string   `toml:"screen" env:"URL_SCREEN" comment:"Actions of the URL checks, like ip=block,credentials=allow."`
+	Blocklist []string `toml:"blocklist" env:"URL_BLOCKLIST" comment:"Blocklist files of hosts and URLs."`
+}
+
+// Keys are the secrets of the server.
+type Keys struct {
+	AdminToken string `toml:"admin_token" env:"ADMIN_TOKEN" secret:"true" comment:"Bearer token of the API key management; empty disables it."`
+	TokenKeys  string `toml:"token_keys" env:"TOKEN_KEYS" secret:"true" comment:"Keys of design tokens as id:secret pairs, the first one signing."`
+}
+
+// Log configures the logger.
+type Log struct {
+	Level   string `toml:"level" env:"LOG_LEVEL" comment:"debug, info, warn or error."`
+	Format  string `toml:"format" env:"LOG_FORMAT" comment:"json or text."`
+	Redact  string `toml:"redact" env:"LOG_REDACT" comment:"How payloads are logged: redact, hash or off."`
+	HashKey string `toml:"hash_key" env:"LOG_HASH_KEY" secret:"true" comment:"Key of the payload hashes; empty makes one up at start."`
+}
+
+// Metrics configures the /metrics endpoint.
+type Metrics struct {
+	Addr  string `toml:"addr" env:"METRICS_ADDR" comment:"Separate address serving /metrics; empty serves it on the main listener."`
+	Token string `toml:"token" env:"METRICS_TOKEN" secret:"true" comment:"Bearer token required by /metrics; empty requires none."`
+}
+
+// Default returns the configuration used for the settings that are not
+// set.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:      8080,
+			StaticDir: "web/static",
+
+			ReadHeaderTimeout: Duration(10 * time.Second),
+			ReadTimeout:       Duration(30 * time.Second),
+			WriteTimeout:      Duration(2 * time.Minute),
+			IdleTimeout:       Duration(2 * time.Minute),
+			ShutdownTimeout:   Duration(30 * time.Second),
+		},
+		Storage: Storage{
+			DBPath:     "data/qrcreator.db",
+			JobsDir:    "data/jobs",
+			JobTTL:     Duration(24 * time.Hour),
+			JobWorkers: 2,
+			UploadsDir: "uploads",
+		},
+		Render: Render{
+			DownloadSize:   2000,
+			PaddingPercent: 7,
+			ImageMaxAge:    Duration(time.Hour),
+			MaxURLLength:   4096,
+			Concurrency:    runtime.NumCPU(),
+			CacheMB:        64,
+			CacheDiskMB:    1024,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "json",
+			Redact: logging.RedactMask,
+		},
+	}
+}
+
+// Addr returns the address to listen on.
+func (c *Config) Addr() string {
+	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
+}
+
+// Validate reports every setting that is out of range.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+		}
+	}
+	check(c.Server.Port > 0 && c.Server.Port < 1<<16, "server.port", "%d is not a port", c.Server.Port)
+	check(c.Server.StaticDir != "", "server.static_dir", "is required")
+	check(c.Server.ReadHeaderTimeout >= 0, "server.read_header_timeout", "must not be negative")
+	check(c.Server.ReadTimeout >= 0, "server.read_timeout", "must not be negative")
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout", "must not be negative")
+	check(c.Server.IdleTimeout >= 0, "server.idle_timeout", "must not be negative")
+	check(c.Server.ShutdownDelay >= 0, "server.shutdown_delay", "must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	check(c.Storage.DBPath != "", "storage.db_path", "is required")
+	check(c.Storage.JobsDir != "", "storage.jobs_dir", "is required")
+	check(c.Storage.JobTTL > 0, "storage.job_ttl", "must be positive")
+	check(c.Storage.JobWorkers > 0, "storage.job_workers", "must be at least 1")
+	check(c.Storage.UploadsDir != "", "storage.uploads_dir", "is required")
+	check(c.Render.DownloadSize >= 100 && c.Render.DownloadSize <= 10000, "render.download_size", "must be between 100 and 10000 pixels")
+	check(c.Render.PaddingPercent >= 0 && c.Render.PaddingPercent <= 50, "render.padding_percent", "must be between 0 and 50")
+	check(c.Render.ImageMaxAge >= 0, "render.image_max_age", "must not be negative")
+	check(c.Render.MaxURLLength >= 16, "render.max_url_length", "must be at least 16")
+	check(c.Render.Concurrency >= 0, "render.concurrency", "must not be negative")
+	check(c.Render.CacheMB >= 0, "render.cache_mb", "must not be negative")
+	check(c.Render.CacheDiskMB >= 0, "render.cache_disk_mb", "must not be negative")
+	if dir := c.Render.CacheDir; dir != "" {
+		check(c.Render.CacheDiskMB > 0, "render.cache_disk_mb", "must be positive with render.cache_dir set")
+		// The disk tier evicts files, so it must not share a directory
+		// with data the server keeps
+		for _, s := range []struct{ key, path string }{
+			{"storage.db_path", c.Storage.DBPath},
+			{"storage.jobs_dir", c.Storage.JobsDir},
+			{"storage.uploads_dir", c.Storage.UploadsDir},
+		} {
+			check(s.path == "" || !within(s.path, dir), "render.cache_dir", "must not be or contain %s", s.key)
+		}
+	}
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format", "%q is not json or text", c.Log.Format)
+	switch c.Log.Redact {
+	case logging.RedactMask, logging.RedactHash, logging.RedactOff:
+	default:
+		errs = append(errs, fmt.Errorf("log.redact: %q is not %s, %s or %s", c.Log.Redact, logging.RedactMask, logging.RedactHash, logging.RedactOff))
+	}
+	if _, err := screen.ParseActions(c.URLs.Screen); err != nil {
+		errs = append(errs, fmt.Errorf("urls.screen: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// within reports whether path is dir or lies inside it.
+func within(path, dir string) bool {
+	p, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	d, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(d, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Duration is a time.Duration written as text, like "24h", in files,
+// variables and flags.
+type Duration time.Duration
+
+// MarshalText writes d as time.Duration.String does.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText reads a duration as time.ParseDuration does.
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// FileEnv names the environment variable of the configuration file, when
+// --config is not given.
+const FileEnv = "CONFIG_FILE"
+
+// Load reads the configuration from the defaults, the TOML file named by
+// --config or CONFIG_FILE, the environment and the flags in args, in that
+// order, and validates it. Every setting has a flag named after its key,
+// like --render.download-size. printConfig reports whether
+// --print-config was given. With -help it returns flag.ErrHelp after
+// printing the flags.
+func Load(args []string, getenv func(string) string) (cfg *Config, printConfig bool, err error) {
+	fs := flag.NewFlagSet("qrcreator", flag.ContinueOnError)
+	file := fs.String("config", getenv(FileEnv), "TOML `file` of settings (env "+FileEnv+")")
+	fs.BoolVar(&printConfig, "print-config", false, "print the configuration as TOML and exit")
+	flagged := Default()
+	byFlag := map[string]int{}
+	for i, s := range flagged.settings() {
+		fs.Var(s, s.flag(), s.usage())
+		byFlag[s.flag()] = i
+	}
+	// Errors are reported by the caller; only -help prints the flags
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(os.Stderr)
+			fs.Usage()
+		}
+		return nil, false, err
+	}
+	if fs.NArg() > 0 {
+		return nil, false, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	cfg = Default()
+	if *file != "" {
+		if err := cfg.readFile(*file); err != nil {
+			return nil, false, err
+		}
+	}
+	settings := cfg.settings()
+	for _, s := range settings {
+		if v := getenv(s.env); v != "" {
+			if err := s.Set(v); err != nil {
+				return nil, false, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+	from := flagged.settings()
+	fs.Visit(func(f *flag.Flag) {
+		if i, ok := byFlag[f.Name]; ok {
+			settings[i].v.Set(from[i].v)
+		}
+	})
+	return cfg, printConfig, cfg.Validate()
+}
+
+// readFile sets the settings of the TOML file at path. Unknown keys are
+// errors, so a misspelt setting is not silently ignored.
+func (c *Config) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = toml.NewDecoder(f).DisallowUnknownFields().Decode(c)
+	var strict *toml.StrictMissingError
+	var decode *toml.DecodeError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &strict):
+		keys := make([]string, len(strict.Errors))
+		for i, e := range strict.Errors {
+			keys[i] = strings.Join(e.Key(), ".")
+		}
+		return fmt.Errorf("%s: unknown settings %s", path, strings.Join(keys, ", "))
+	case errors.As(err, &decode):
+		row, col := decode.Position()
+		return fmt.Errorf("%s:%d:%d: %v", path, row, col, err)
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// Write writes c as a TOML file, with the secrets that are set replaced
+// by "[redacted]".
+func (c *Config) Write(w io.Writer) error {
+	out := *c
+	for _, s := range out.settings() {
+		if s.secret && s.v.String() != "" {
+			s.v.SetString("[redacted]")
+		}
+	}
+	enc := toml.NewEncoder(w)
+	enc.SetIndentTables(false)
+	return enc.Encode(out)
+}
+
+// setting is one field of a Config, as a flag.Value.
+type setting struct {
+	key     string // section.key, as in the file
+	env     string
+	comment string
+	secret  bool
+	v       reflect.Value
+}
+
+// settings lists the settings of c in the order of the struct fields.
+func (c *Config) settings() []*setting {
+	var list []*setting
+	root := reflect.ValueOf(c).Elem()
+	for i := range root.NumField() {
+		section := root.Type().Field(i).Tag.Get("toml")
+		sv := root.Field(i)
+		for j := range sv.NumField() {
+			f := sv.Type().Field(j)
+			list = append(list, &setting{
+				key:     section + "." + f.Tag.Get("toml"),
+				env:     f.Tag.Get("env"),
+				comment: f.Tag.Get("comment"),
+				secret:  f.Tag.Get("secret") == "true",
+				v:       sv.Field(j),
+			})
+		}
+	}
+	return list
+}
+
+func (s *setting) flag() string {
+	return strings.ReplaceAll(s.key, "_", "-")
+}
+
+func (s *setting) usage() string {
+	return fmt.Sprintf("%s (env %s)", s.comment, s.env)
+}
+
+// String returns the value as it is set, lists comma-separated.
+func (s *setting) String() string {
+	if s == nil || !s.v.IsValid() {
+		return ""
+	}
+	if m, ok := s.v.Interface().(encoding.TextMarshaler); ok {
+		b, _ := m.MarshalText()
+		return string(b)
+	}
+	if s.v.Kind() == reflect.Slice {
+		return strings.Join(s.v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(s.v.Interface())
+}
+
+// Set parses text into the setting. Lists are comma-separated.
+func (s *setting) Set(text string) error {
+	if u, ok := s.v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(text))
+	}
+	switch s.v.Kind() {
+	case reflect.String:
+		s.v.SetString(text)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", text)
+		}
+		s.v.SetInt(int64(n))
+	case reflect.Slice:
+		var list []string
+		for item := range strings.SplitSeq(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		s.v.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.v.Type())
+	}
+	return nil
+}
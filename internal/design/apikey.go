@@ -0,0 +1,39 @@
+package design
+
+import "slices"
+
+// API key scopes.
+const (
+	// ScopeRender allows the image endpoints.
+	ScopeRender = "render"
+	// ScopeBatch allows batches and sheets.
+	ScopeBatch = "batch"
+	// ScopeLinks allows creating and managing dynamic codes.
+	ScopeLinks = "links"
+	// ScopePresets allows saving and deleting presets.
+	ScopePresets = "presets"
+)
+
+// APIKey is the editable part of an API key.
+type APIKey struct {
+	Name       string   `json:"name" required:"true" maxLength:"80"`
+	Scopes     []string `json:"scopes" enum:"render,batch,links,presets" doc:"What the key may be used for: render images, run batches and sheets, manage dynamic codes, save presets."`
+	DailyQuota int      `json:"dailyQuota,omitempty" min:"0" doc:"Units the key may use per day (UTC), counted like the rate limits; 0 is no quota."`
+}
+
+// Validate requires at least one scope, each once.
+func (k *APIKey) Validate() []FieldError {
+	if len(k.Scopes) == 0 {
+		return []FieldError{{"scopes", "must contain at least one scope"}}
+	}
+	sorted := slices.Sorted(slices.Values(k.Scopes))
+	if len(slices.Compact(sorted)) != len(k.Scopes) {
+		return []FieldError{{"scopes", "must not repeat a scope"}}
+	}
+	return nil
+}
+
+// HasScope reports whether the key may be used for scope.
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
@@ -0,0 +1,258 @@
+package design
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxBatchRows bounds the number of rows of a batch.
+const MaxBatchRows = 1000
+
+// Batch is a list of codes rendered with a shared style.
+type Batch struct {
+	Design   Style      `json:"design" doc:"Style shared by every row."`
+	Filename string     `json:"filename" default:"{index}" maxLength:"200" doc:"File name template without extension. {index} is the zero-padded row number, {type} and {content} come from the row, and any other {name} from the row's fields (CSV columns)."`
+	Rows     []BatchRow `json:"rows" required:"true" independent:"true" doc:"Up to 1000 rows. Rows that fail are reported in the manifest and do not stop the batch."`
+	Campaign *Campaign  `json:"campaign,omitempty" doc:"Tags added to the URL of every url row that has no campaign of its own. Values take the placeholders of the filename template."`
+}
+
+// BatchRow is one code of a batch.
+type BatchRow struct {
+	Content   Content           `json:"content" required:"true"`
+	Overrides map[string]any    `json:"overrides,omitempty" doc:"Style fields to change for this row, merged over the shared design (JSON merge patch)."`
+	Filename  string            `json:"filename,omitempty" maxLength:"200" doc:"File name without extension; replaces the template."`
+	Fields    map[string]string `json:"fields,omitempty" doc:"Values for {name} placeholders in the filename template."`
+}
+
+// Validate checks the number of rows.
+func (b *Batch) Validate() []FieldError {
+	switch {
+	case len(b.Rows) == 0:
+		return []FieldError{{"rows", "must contain at least one row"}}
+	case len(b.Rows) > MaxBatchRows:
+		return []FieldError{{"rows", fmt.Sprintf("must contain at most %d rows", MaxBatchRows)}}
+	}
+	return nil
+}
+
+// RowDesign returns the design of row index (0-based): its content with
+// the shared campaign, and the shared style with the row's overrides
+// merged over it, validated like a single design.
+func (b *Batch) RowDesign(index int, row BatchRow) (*Design, error) {
+	return mergeDesign(b.Design, withCampaign(row.Content, b.Campaign, index, len(b.Rows), row.Fields), row.Overrides)
+}
+
+// withCampaign gives url content (the default type) without a campaign of
+// its own the shared campaign c, expanded for item index of n.
+func withCampaign(content Content, c *Campaign, index, n int, fields map[string]string) Content {
+	if c == nil || (content.Type != "" && content.Type != "url") || content.Campaign != nil {
+		return content
+	}
+	expanded := c.Expand(index, n, content, fields)
+	content.Campaign = &expanded
+	return content
+}
+
+// mergeDesign returns the design with content and the style with
+// overrides merged over it, validated like a single design.
+func mergeDesign(style Style, content Content, overrides map[string]any) (*Design, error) {
+	d := &Design{Content: content, Style: style}
+	if len(overrides) > 0 {
+		base, err := json.Marshal(style)
+		if err != nil {
+			return nil, err
+		}
+		var merged map[string]any
+		if err := json.Unmarshal(base, &merged); err != nil {
+			return nil, err
+		}
+		patched, err := json.Marshal(mergePatch(merged, overrides))
+		if err != nil {
+			return nil, err
+		}
+		var patchedStyle Style
+		if err := Decode(bytes.NewReader(patched), &patchedStyle); err != nil {
+			return nil, PrefixFields(err, "overrides")
+		}
+		d.Style = patchedStyle
+	}
+	if err := Check(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// mergePatch applies an RFC 7386 merge patch to target: objects are
+// merged recursively and null removes a member.
+func mergePatch(target map[string]any, patch map[string]any) map[string]any {
+	for k, v := range patch {
+		switch pv := v.(type) {
+		case nil:
+			delete(target, k)
+		case map[string]any:
+			tv, _ := target[k].(map[string]any)
+			if tv == nil {
+				tv = map[string]any{}
+			}
+			target[k] = mergePatch(tv, pv)
+		default:
+			target[k] = v
+		}
+	}
+	return target
+}
+
+// PrefixFields puts the field paths of a validation error under prefix,
+// for documents decoded as part of a larger request.
+func PrefixFields(err error, prefix string) error {
+	invalid, ok := err.(*ValidationError)
+	if !ok {
+		return err
+	}
+	for i := range invalid.Fields {
+		invalid.Fields[i].Field = joinPath(prefix, invalid.Fields[i].Field)
+	}
+	return invalid
+}
+
+var placeholder = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// RowFilename expands the filename template for row index (0-based) of a
+// batch of n rows. The result is reduced to characters that are safe in
+// ZIP archives on every platform.
+func (b *Batch) RowFilename(index, n int, row BatchRow) string {
+	name := row.Filename
+	if name == "" {
+		name = expand(b.Filename, index, n, row.Content, row.Fields)
+	}
+	name = strings.Trim(safeName.ReplaceAllString(name, "-"), "-.")
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	if name == "" {
+		name = fmt.Sprintf("%0*d", len(strconv.Itoa(n)), index+1)
+	}
+	return name
+}
+
+var safeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// expand fills the placeholders of a filename or caption template for
+// item index (0-based) of n: {index} is the zero-padded item number,
+// {type} and {content} come from the content, and any other {name} from
+// fields.
+func expand(tmpl string, index, n int, content Content, fields map[string]string) string {
+	width := len(strconv.Itoa(n))
+	return placeholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		key := m[1 : len(m)-1]
+		switch key {
+		case "index":
+			return fmt.Sprintf("%0*d", width, index+1)
+		case "type":
+			return content.Type
+		case "content":
+			return content.Payload()
+		}
+		return fields[key]
+	})
+}
+
+// csvOverrides maps CSV columns to the style fields they override.
+var csvOverrides = map[string][]string{
+	"foreground": {"colors", "foreground"},
+	"background": {"colors", "background"},
+	"shape":      {"shape"},
+	"ecc":        {"encoding", "ecc"},
+	"symbology":  {"encoding", "symbology"},
+	"format":     {"output", "format"},
+}
+
+// ParseCSVRows reads batch rows from CSV with a header line. The content
+// column is required; type (default url) says what it holds: a URL, text,
+// a phone number, an email address or an SMS number. A filename column
+// names the file, the columns in csvOverrides override the style, and
+// every other column is available to the filename template.
+func ParseCSVRows(r io.Reader) ([]BatchRow, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %v", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+	}
+	if !slices.Contains(header, "content") {
+		return nil, fmt.Errorf("CSV header must have a content column")
+	}
+
+	var rows []BatchRow
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %v", err)
+		}
+		if len(rows) == MaxBatchRows {
+			return nil, fmt.Errorf("CSV has more than %d rows", MaxBatchRows)
+		}
+		row := BatchRow{Fields: map[string]string{}}
+		var value string
+		for i, col := range header {
+			v := strings.TrimSpace(rec[i])
+			switch col {
+			case "content":
+				value = v
+			case "type":
+				row.Content.Type = v
+			case "filename":
+				row.Filename = v
+			default:
+				if path, ok := csvOverrides[col]; ok {
+					if v != "" {
+						row.Overrides = setPath(row.Overrides, path, v)
+					}
+					continue
+				}
+				row.Fields[col] = v
+			}
+		}
+		switch row.Content.Type {
+		case "", "url":
+			row.Content.URL = value
+		case "text":
+			row.Content.Text = value
+		case "phone":
+			row.Content.Phone = value
+		case "email":
+			row.Content.Email = &Email{To: value}
+		case "sms":
+			row.Content.SMS = &SMS{Number: value}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// setPath sets a nested member of a JSON object, creating it if needed.
+func setPath(m map[string]any, path []string, v any) map[string]any {
+	if m == nil {
+		m = map[string]any{}
+	}
+	if len(path) == 1 {
+		m[path[0]] = v
+		return m
+	}
+	child, _ := m[path[0]].(map[string]any)
+	m[path[0]] = setPath(child, path[1:], v)
+	return m
+}
@@ -0,0 +1,176 @@
+package design
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// MaxCampaignParams bounds the custom parameters of a campaign.
+const MaxCampaignParams = 20
+
+// Campaign tags a URL with UTM and custom query parameters. Values may use
+// the placeholders of a batch filename, filled per row in batches and
+// sheets.
+type Campaign struct {
+	Template string            `json:"template,omitempty" maxLength:"64" doc:"ID of a saved campaign template whose values fill the fields left empty here."`
+	Source   string            `json:"source,omitempty" maxLength:"200" doc:"utm_source: where the traffic comes from, e.g. flyer."`
+	Medium   string            `json:"medium,omitempty" maxLength:"200" doc:"utm_medium, e.g. print."`
+	Name     string            `json:"campaign,omitempty" maxLength:"200" doc:"utm_campaign."`
+	Term     string            `json:"term,omitempty" maxLength:"200" doc:"utm_term."`
+	Content  string            `json:"content,omitempty" maxLength:"200" doc:"utm_content, e.g. to tell apart the codes of one campaign."`
+	Params   map[string]string `json:"params,omitempty" doc:"Other query parameters to set, e.g. ref."`
+}
+
+// CampaignTemplate is a named campaign saved for reuse.
+type CampaignTemplate struct {
+	Name     string   `json:"name" required:"true" maxLength:"80"`
+	Campaign Campaign `json:"campaign"`
+}
+
+// Validate checks the custom parameters.
+func (c *Campaign) Validate() []FieldError {
+	var errs []FieldError
+	if len(c.Params) > MaxCampaignParams {
+		errs = append(errs, FieldError{"params", fmt.Sprintf("must contain at most %d parameters", MaxCampaignParams)})
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Params)) {
+		switch {
+		case k == "" || len(k) > 64:
+			errs = append(errs, FieldError{"params", "names must be 1 to 64 characters"})
+		case strings.HasPrefix(k, "utm_"):
+			errs = append(errs, FieldError{"params." + k, "use the source, medium, campaign, term and content fields"})
+		case len(c.Params[k]) > 200:
+			errs = append(errs, FieldError{"params." + k, "must be at most 200 characters"})
+		}
+	}
+	return errs
+}
+
+// Validate rejects templates that point to another template.
+func (t *CampaignTemplate) Validate() []FieldError {
+	if t.Campaign.Template != "" {
+		return []FieldError{{"campaign.template", "templates cannot use other templates"}}
+	}
+	return nil
+}
+
+// Fill returns the campaign with its empty fields taken from t. Custom
+// parameters are merged, the campaign's own winning.
+func (c Campaign) Fill(t Campaign) Campaign {
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&c.Source, t.Source}, {&c.Medium, t.Medium}, {&c.Name, t.Name}, {&c.Term, t.Term}, {&c.Content, t.Content},
+	} {
+		if *f.dst == "" {
+			*f.dst = f.src
+		}
+	}
+	if len(t.Params) > 0 {
+		params := maps.Clone(t.Params)
+		maps.Copy(params, c.Params)
+		c.Params = params
+	}
+	return c
+}
+
+// Expand fills the placeholders of the campaign's values for item index
+// (0-based) of n.
+func (c Campaign) Expand(index, n int, content Content, fields map[string]string) Campaign {
+	for _, s := range []*string{&c.Source, &c.Medium, &c.Name, &c.Term, &c.Content} {
+		*s = expand(*s, index, n, content, fields)
+	}
+	if len(c.Params) > 0 {
+		params := make(map[string]string, len(c.Params))
+		for k, v := range c.Params {
+			params[k] = expand(v, index, n, content, fields)
+		}
+		c.Params = params
+	}
+	return c
+}
+
+// pairs returns the query parameters of the campaign in a stable order:
+// the UTM parameters, then the custom ones by name.
+func (c *Campaign) pairs() [][2]string {
+	var pairs [][2]string
+	for _, p := range [][2]string{
+		{"utm_source", c.Source}, {"utm_medium", c.Medium}, {"utm_campaign", c.Name}, {"utm_term", c.Term}, {"utm_content", c.Content},
+	} {
+		if p[1] != "" {
+			pairs = append(pairs, p)
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Params)) {
+		if c.Params[k] != "" {
+			pairs = append(pairs, [2]string{k, c.Params[k]})
+		}
+	}
+	return pairs
+}
+
+// Tag adds the parameters of the campaign to an absolute URL. Parameters
+// the URL already has with the same name are replaced where they stand;
+// the others, their order and encoding, and the fragment are kept.
+func (c *Campaign) Tag(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	pairs := c.pairs()
+	if len(pairs) == 0 {
+		return rawURL, nil
+	}
+	value := map[string]string{}
+	for _, p := range pairs {
+		value[p[0]] = p[1]
+	}
+
+	var parts []string
+	done := map[string]bool{}
+	if u.RawQuery != "" {
+		for _, part := range strings.Split(u.RawQuery, "&") {
+			rawKey, _, _ := strings.Cut(part, "=")
+			key, err := url.QueryUnescape(rawKey)
+			if err != nil {
+				key = rawKey
+			}
+			v, tagged := value[key]
+			switch {
+			case !tagged:
+				parts = append(parts, part)
+			case !done[key]:
+				parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(v))
+				done[key] = true
+			}
+		}
+	}
+	for _, p := range pairs {
+		if !done[p[0]] {
+			parts = append(parts, url.QueryEscape(p[0])+"="+url.QueryEscape(p[1]))
+		}
+	}
+	u.RawQuery = strings.Join(parts, "&")
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+// CampaignFromQuery reads the utm_* parameters of a GET /api/qr request,
+// or returns nil when there are none.
+func CampaignFromQuery(get func(string) string) *Campaign {
+	c := &Campaign{
+		Source:  get("utm_source"),
+		Medium:  get("utm_medium"),
+		Name:    get("utm_campaign"),
+		Term:    get("utm_term"),
+		Content: get("utm_content"),
+	}
+	if len(c.pairs()) == 0 {
+		return nil
+	}
+	return c
+}
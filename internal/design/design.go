@@ -0,0 +1,351 @@
+// Package design defines the JSON design document accepted by the v1 API:
+// what a code encodes and how it is drawn. The same Go types drive strict
+// request validation and the generated OpenAPI schema, so struct tags are
+// the single source of truth for enums, defaults and limits:
+//
+//	json      field name
+//	doc       description
+//	enum      comma-separated allowed values
+//	default   value used when the field is omitted
+//	pattern   regular expression the value must match
+//	maxLength maximum string length
+//	min, max  numeric bounds
+//	required  "true" when the field must be present
+//	independent "true" on a slice whose items the caller validates one by
+//	          one, so a bad item does not reject the whole document
+//
+// On a []string field, enum, pattern and maxLength apply to every item.
+package design
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Design is a complete description of a QR code: its content and style.
+type Design struct {
+	Content Content `json:"content" required:"true" doc:"What the code encodes."`
+	Style
+}
+
+// Style is everything about a QR code but its content, shared by the codes
+// of a batch.
+type Style struct {
+	Colors   Colors   `json:"colors" doc:"Foreground, background and gradient colors."`
+	Shape    string   `json:"shape" enum:"rectangle,circle,liquid,chain,hstripe,vstripe" default:"rectangle" doc:"Module shape."`
+	Frame    Frame    `json:"frame" doc:"Frame drawn around the code."`
+	Logo     *Logo    `json:"logo,omitempty" doc:"Logo placed in the centre of the code. QR symbology only."`
+	Encoding Encoding `json:"encoding" doc:"Symbology and encoder settings."`
+	Output   Output   `json:"output" doc:"Image format and size."`
+}
+
+// Content is the payload of the code. Type selects which of the other
+// fields is used; exactly that one must be set.
+type Content struct {
+	Type  string `json:"type" enum:"url,text,email,phone,sms,wifi,vcard" default:"url" doc:"Payload type."`
+	URL   string `json:"url,omitempty" maxLength:"$maxURLLength" doc:"http or https URL; https is assumed when the scheme is missing. Used with type url."`
+	Text  string `json:"text,omitempty" maxLength:"4096" doc:"Free text, encoded as is. Used with type text."`
+	Email *Email `json:"email,omitempty" doc:"Used with type email."`
+	Phone string `json:"phone,omitempty" pattern:"^\\+?[0-9 ()./-]{3,32}$" doc:"Phone number to call. Used with type phone."`
+	SMS   *SMS   `json:"sms,omitempty" doc:"Used with type sms."`
+	WiFi  *WiFi  `json:"wifi,omitempty" doc:"Used with type wifi."`
+	VCard *VCard `json:"vcard,omitempty" doc:"Used with type vcard."`
+
+	Campaign *Campaign `json:"campaign,omitempty" doc:"UTM and other parameters added to the encoded URL. Used with type url."`
+}
+
+// Email is a mailto: link with an optional subject and body.
+type Email struct {
+	To      string `json:"to" required:"true" pattern:"^[^@\\s]+@[^@\\s]+$" maxLength:"254" doc:"Recipient address."`
+	Subject string `json:"subject,omitempty" maxLength:"255"`
+	Body    string `json:"body,omitempty" maxLength:"2000"`
+}
+
+// SMS is a text message to a phone number.
+type SMS struct {
+	Number  string `json:"number" required:"true" pattern:"^\\+?[0-9 ()./-]{3,32}$" doc:"Recipient phone number."`
+	Message string `json:"message,omitempty" maxLength:"918"`
+}
+
+// WiFi holds network credentials in the format read by phone cameras.
+type WiFi struct {
+	SSID     string `json:"ssid" required:"true" maxLength:"32" doc:"Network name."`
+	Password string `json:"password,omitempty" maxLength:"63" doc:"Required unless security is nopass."`
+	Security string `json:"security" enum:"WPA,WEP,nopass" default:"WPA"`
+	Hidden   bool   `json:"hidden,omitempty" doc:"The network does not broadcast its SSID."`
+}
+
+// VCard is a contact card (vCard 3.0).
+type VCard struct {
+	Name         string `json:"name" required:"true" maxLength:"128" doc:"Full name."`
+	Organization string `json:"organization,omitempty" maxLength:"128"`
+	Title        string `json:"title,omitempty" maxLength:"128"`
+	Phone        string `json:"phone,omitempty" pattern:"^\\+?[0-9 ()./-]{3,32}$"`
+	Email        string `json:"email,omitempty" pattern:"^[^@\\s]+@[^@\\s]+$" maxLength:"254"`
+	URL          string `json:"url,omitempty" maxLength:"1024"`
+	Address      string `json:"address,omitempty" maxLength:"256" doc:"Street address on one line."`
+}
+
+// Colors holds the fill of the modules and the background.
+type Colors struct {
+	Mode       string    `json:"mode" enum:"flat,gradient" default:"flat"`
+	Foreground string    `json:"foreground" pattern:"^#[0-9a-fA-F]{6}$" default:"#000000" doc:"Module color in flat mode."`
+	Background string    `json:"background" pattern:"^(#[0-9a-fA-F]{6}|transparent)$" default:"#ffffff" doc:"Background color, or transparent."`
+	Gradient   *Gradient `json:"gradient,omitempty" doc:"Required in gradient mode."`
+}
+
+// Gradient is the 45° three-stop gradient applied to the modules.
+type Gradient struct {
+	Start  string `json:"start" required:"true" pattern:"^#[0-9a-fA-F]{6}$"`
+	Middle string `json:"middle" required:"true" pattern:"^#[0-9a-fA-F]{6}$"`
+	End    string `json:"end" required:"true" pattern:"^#[0-9a-fA-F]{6}$"`
+}
+
+// Frame is the border drawn around the code.
+type Frame struct {
+	Style   string `json:"style" enum:"none,straight,rounded" default:"none"`
+	Pattern string `json:"pattern" enum:"simple,irregular,dotted,dashed,double,diagonal,grid" default:"simple"`
+	Color   string `json:"color,omitempty" pattern:"^#[0-9a-fA-F]{6}$" doc:"Defaults to the foreground or gradient start color."`
+}
+
+// Logo refers to an uploaded PNG placed in the centre of the code.
+type Logo struct {
+	File string `json:"file" required:"true" pattern:"^[A-Za-z0-9][A-Za-z0-9._-]*\\.png$" doc:"Name of a PNG in the uploads directory."`
+}
+
+// Encoding controls the symbology and how the payload is encoded.
+type Encoding struct {
+	Symbology  string `json:"symbology" enum:"qr,micro,rmqr" default:"qr"`
+	ECC        string `json:"ecc" enum:"L,M,Q,H" doc:"Error correction level; Q by default, M for micro."`
+	Mode       string `json:"mode" enum:"auto,numeric,alphanumeric,byte,kanji" default:"auto" doc:"Segment mode."`
+	ECI        string `json:"eci,omitempty" enum:"utf8,iso8859-1,shift-jis" doc:"Character set announced with an ECI header."`
+	MinVersion *int   `json:"minVersion,omitempty" min:"1" max:"40"`
+	MaxVersion *int   `json:"maxVersion,omitempty" min:"1" max:"40"`
+	Mask       *int   `json:"mask,omitempty" min:"0" max:"7" doc:"Forced mask pattern; omitted picks the best one."`
+}
+
+// Output selects the image format and resolution.
+type Output struct {
+	Format      string `json:"format" enum:"png,jpg,svg" default:"png"`
+	Size        string `json:"size" enum:"preview,download" default:"download" doc:"preview renders a small image, download a print-quality one."`
+	PreviewSize *int   `json:"previewSize,omitempty" min:"64" max:"2048" doc:"Exact edge in pixels of a preview image."`
+}
+
+// Validate checks the rules that involve more than one field, and fills
+// the ecc default, which depends on the symbology.
+func (d *Style) Validate() []FieldError {
+	if d.Encoding.ECC == "" {
+		d.Encoding.ECC = "Q"
+		if d.Encoding.Symbology == "micro" {
+			d.Encoding.ECC = "M"
+		}
+	}
+	var errs []FieldError
+	if d.Logo != nil && d.Encoding.Symbology != "qr" {
+		errs = append(errs, FieldError{"logo", "a logo is only supported with the qr symbology"})
+	}
+	if d.Output.Format == "svg" {
+		if d.Colors.Mode != "flat" {
+			errs = append(errs, FieldError{"output.format", "svg requires flat colors"})
+		}
+		if d.Frame.Style != "none" && d.Frame.Pattern != "simple" {
+			errs = append(errs, FieldError{"output.format", "svg requires no frame or the simple frame pattern"})
+		}
+	}
+	if d.Output.PreviewSize != nil && d.Output.Size != "preview" {
+		errs = append(errs, FieldError{"output.previewSize", "only applies when size is preview"})
+	}
+	return errs
+}
+
+// Validate checks that the field selected by Type is set and no other.
+func (ct *Content) Validate() []FieldError {
+	set := map[string]bool{
+		"url":   ct.URL != "",
+		"text":  ct.Text != "",
+		"email": ct.Email != nil,
+		"phone": ct.Phone != "",
+		"sms":   ct.SMS != nil,
+		"wifi":  ct.WiFi != nil,
+		"vcard": ct.VCard != nil,
+	}
+	var errs []FieldError
+	for _, name := range []string{"url", "text", "email", "phone", "sms", "wifi", "vcard"} {
+		switch {
+		case name == ct.Type && !set[name]:
+			errs = append(errs, FieldError{name, fmt.Sprintf("is required when type is %s", ct.Type)})
+		case name != ct.Type && set[name]:
+			errs = append(errs, FieldError{name, fmt.Sprintf("is not used when type is %s", ct.Type)})
+		}
+	}
+	if ct.Campaign != nil && ct.Type != "url" {
+		errs = append(errs, FieldError{"campaign", "is only used when type is url"})
+	}
+	return errs
+}
+
+// Validate checks that secured networks have a password.
+func (w *WiFi) Validate() []FieldError {
+	if w.Security != "nopass" && w.Password == "" {
+		return []FieldError{{"password", "is required unless security is nopass"}}
+	}
+	if w.Security == "nopass" && w.Password != "" {
+		return []FieldError{{"password", "is not used when security is nopass"}}
+	}
+	return nil
+}
+
+// Validate checks that a gradient is given exactly in gradient mode.
+func (cl *Colors) Validate() []FieldError {
+	if cl.Mode == "gradient" && cl.Gradient == nil {
+		return []FieldError{{"gradient", "is required when mode is gradient"}}
+	}
+	if cl.Mode == "flat" && cl.Gradient != nil {
+		return []FieldError{{"gradient", "is not used when mode is flat"}}
+	}
+	return nil
+}
+
+// Validate rejects frame settings that have no effect.
+func (f *Frame) Validate() []FieldError {
+	if f.Style != "none" {
+		return nil
+	}
+	var errs []FieldError
+	if f.Pattern != "simple" {
+		errs = append(errs, FieldError{"pattern", "needs a straight or rounded frame style"})
+	}
+	if f.Color != "" {
+		errs = append(errs, FieldError{"color", "needs a straight or rounded frame style"})
+	}
+	return errs
+}
+
+// Payload returns the text encoded in the code. URLs are returned as given
+// and are normalized by the caller.
+func (ct *Content) Payload() string {
+	switch ct.Type {
+	case "text":
+		return ct.Text
+	case "email":
+		q := url.Values{}
+		if ct.Email.Subject != "" {
+			q.Set("subject", ct.Email.Subject)
+		}
+		if ct.Email.Body != "" {
+			q.Set("body", ct.Email.Body)
+		}
+		u := "mailto:" + ct.Email.To
+		if len(q) > 0 {
+			// mailto wants %20 for spaces, not +
+			u += "?" + strings.ReplaceAll(q.Encode(), "+", "%20")
+		}
+		return u
+	case "phone":
+		return "tel:" + compactPhone(ct.Phone)
+	case "sms":
+		return "SMSTO:" + compactPhone(ct.SMS.Number) + ":" + ct.SMS.Message
+	case "wifi":
+		w := ct.WiFi
+		s := "WIFI:T:" + w.Security + ";S:" + wifiEscape(w.SSID) + ";"
+		if w.Security != "nopass" {
+			s += "P:" + wifiEscape(w.Password) + ";"
+		}
+		if w.Hidden {
+			s += "H:true;"
+		}
+		return s + ";"
+	case "vcard":
+		return ct.VCard.String()
+	}
+	return ct.URL
+}
+
+// String formats the card as vCard 3.0.
+func (v *VCard) String() string {
+	var b strings.Builder
+	line := func(name, value string) {
+		if value != "" {
+			b.WriteString(name + ":" + vcardEscape(value) + "\r\n")
+		}
+	}
+	b.WriteString("BEGIN:VCARD\r\nVERSION:3.0\r\n")
+	line("FN", v.Name)
+	line("ORG", v.Organization)
+	line("TITLE", v.Title)
+	if v.Phone != "" {
+		b.WriteString("TEL:" + compactPhone(v.Phone) + "\r\n")
+	}
+	line("EMAIL", v.Email)
+	line("URL", v.URL)
+	if v.Address != "" {
+		b.WriteString("ADR:;;" + vcardEscape(v.Address) + ";;;;\r\n")
+	}
+	b.WriteString("END:VCARD")
+	return b.String()
+}
+
+// Query returns the style as the query parameters of GET /api/qr, so it
+// is rendered by the same code.
+func (d *Style) Query() url.Values {
+	q := url.Values{}
+	q.Set("qrShape", d.Shape)
+	q.Set("colorMode", d.Colors.Mode)
+	q.Set("bg", d.Colors.Background)
+	if d.Colors.Mode == "gradient" {
+		q.Set("gradientStart", d.Colors.Gradient.Start)
+		q.Set("gradientMiddle", d.Colors.Gradient.Middle)
+		q.Set("gradientEnd", d.Colors.Gradient.End)
+	} else {
+		q.Set("fg", d.Colors.Foreground)
+	}
+	q.Set("cornerStyle", d.Frame.Style)
+	q.Set("borderPattern", d.Frame.Pattern)
+	if d.Frame.Color != "" {
+		q.Set("borderColor", d.Frame.Color)
+	}
+	if d.Logo != nil {
+		q.Set("centerLogo", "true")
+		q.Set("logoFile", d.Logo.File)
+	}
+
+	q.Set("symbology", d.Encoding.Symbology)
+	q.Set("ecc", d.Encoding.ECC)
+	q.Set("encoding", d.Encoding.Mode)
+	q.Set("eci", d.Encoding.ECI)
+	setInt := func(name string, v *int) {
+		if v != nil {
+			q.Set(name, strconv.Itoa(*v))
+		}
+	}
+	setInt("minVersion", d.Encoding.MinVersion)
+	setInt("maxVersion", d.Encoding.MaxVersion)
+	setInt("mask", d.Encoding.Mask)
+
+	q.Set("format", d.Output.Format)
+	q.Set("size", d.Output.Size)
+	setInt("previewSize", d.Output.PreviewSize)
+	return q
+}
+
+// compactPhone drops the formatting characters allowed in phone numbers.
+func compactPhone(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '+' || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// wifiEscape escapes the characters with a meaning in WIFI: payloads.
+func wifiEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`, `"`, `\"`).Replace(s)
+}
+
+// vcardEscape escapes the characters with a meaning in vCard values.
+func vcardEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\n", `\n`).Replace(s)
+}
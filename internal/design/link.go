@@ -0,0 +1,107 @@
+package design
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxLinkRules bounds the number of rules of a dynamic code.
+const MaxLinkRules = 50
+
+// Link is the editable part of a dynamic code: where it redirects and
+// whether it does.
+type Link struct {
+	Target    string     `json:"target" required:"true" maxLength:"$maxURLLength" doc:"Where the code redirects when no rule matches: an http or https URL. The scheme defaults to https."`
+	Rules     []Rule     `json:"rules,omitempty" doc:"Rules tried in order; the first whose conditions all hold picks the destination."`
+	Campaign  *Campaign  `json:"campaign,omitempty" doc:"UTM and other parameters added on redirect to whichever destination is picked, so the encoded short URL stays the same."`
+	Paused    bool       `json:"paused,omitempty" doc:"Stop redirecting without deleting the code."`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" doc:"When the code stops redirecting (RFC 3339)."`
+	MaxScans  int        `json:"maxScans,omitempty" min:"0" max:"1000000000" doc:"Stop redirecting after this many scans; 0 means no limit."`
+	Passcode  *string    `json:"passcode,omitempty" maxLength:"72" doc:"Visitors must enter it before being redirected. It is stored hashed and never returned; omit it to keep the current one, or send an empty string to remove it."`
+
+	ExpiredMessage string `json:"expiredMessage,omitempty" maxLength:"500" doc:"Message of the page shown once the code has expired or used up its scans."`
+}
+
+// Rule sends the scans that meet its conditions to its own target, or
+// splits them between weighted variants.
+type Rule struct {
+	When   Condition `json:"when" doc:"Conditions that must all hold; an empty condition always holds."`
+	Target string    `json:"target,omitempty" maxLength:"$maxURLLength" doc:"Destination of the rule. Exactly one of target and split is set."`
+	Split  []Variant `json:"split,omitempty" doc:"Weighted destinations for A/B tests. A visitor keeps getting the same variant."`
+}
+
+// Condition restricts a rule to a schedule, devices or languages.
+type Condition struct {
+	Start     *time.Time `json:"start,omitempty" doc:"Rule applies from this instant on (RFC 3339)."`
+	End       *time.Time `json:"end,omitempty" doc:"Rule no longer applies from this instant on (RFC 3339)."`
+	Days      []string   `json:"days,omitempty" enum:"mon,tue,wed,thu,fri,sat,sun" doc:"Days of the week, in timeZone."`
+	From      string     `json:"from,omitempty" pattern:"^([01][0-9]|2[0-3]):[0-5][0-9]$" doc:"Daily window start as HH:MM in timeZone."`
+	Until     string     `json:"until,omitempty" pattern:"^([01][0-9]|2[0-3]):[0-5][0-9]$" doc:"Daily window end as HH:MM in timeZone, exclusive. A window ending before it starts runs past midnight."`
+	TimeZone  string     `json:"timeZone,omitempty" maxLength:"64" default:"UTC" doc:"IANA time zone of days, from and until, e.g. Europe/Madrid."`
+	Devices   []string   `json:"devices,omitempty" enum:"mobile,tablet,desktop" doc:"Device classes, from the User-Agent."`
+	OS        []string   `json:"os,omitempty" enum:"ios,android,windows,macos,linux,chromeos" doc:"Operating systems, from the User-Agent."`
+	Languages []string   `json:"languages,omitempty" pattern:"^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$" doc:"Language tags matched against the visitor's preferred language: es matches es and es-AR, pt-BR only pt-BR."`
+}
+
+// Variant is one destination of a split.
+type Variant struct {
+	Target string `json:"target" required:"true" maxLength:"$maxURLLength"`
+	Weight int    `json:"weight,omitempty" min:"0" max:"10000" doc:"Share of the split relative to the other variants; 0 or omitted counts as 1."`
+}
+
+// Share returns the weight of the variant in its split.
+func (v Variant) Share() int {
+	return max(v.Weight, 1)
+}
+
+// NewLink creates a dynamic code.
+type NewLink struct {
+	Slug string `json:"slug,omitempty" pattern:"^[A-Za-z0-9_-]{3,64}$" doc:"Path of the short URL, /r/<slug>. A random one is picked when omitted."`
+	Link
+}
+
+// Validate checks the number of rules and the passcode.
+func (l *Link) Validate() []FieldError {
+	var errs []FieldError
+	if len(l.Rules) > MaxLinkRules {
+		errs = append(errs, FieldError{"rules", fmt.Sprintf("must contain at most %d rules", MaxLinkRules)})
+	}
+	if p := l.Passcode; p != nil && *p != "" {
+		switch {
+		case utf8.RuneCountInString(*p) < 4:
+			errs = append(errs, FieldError{"passcode", "must be at least 4 characters"})
+		case len(*p) > 72:
+			errs = append(errs, FieldError{"passcode", "must be at most 72 bytes"})
+		}
+	}
+	return errs
+}
+
+// Validate checks that the rule has one kind of destination.
+func (r *Rule) Validate() []FieldError {
+	switch {
+	case r.Target == "" && len(r.Split) == 0:
+		return []FieldError{{"target", "is required unless split is set"}}
+	case r.Target != "" && len(r.Split) > 0:
+		return []FieldError{{"split", "cannot be set with target"}}
+	}
+	return nil
+}
+
+// Validate checks the time zone and that the windows are not empty.
+func (c *Condition) Validate() []FieldError {
+	var errs []FieldError
+	if _, err := time.LoadLocation(c.TimeZone); err != nil {
+		errs = append(errs, FieldError{"timeZone", "unknown time zone"})
+	}
+	if c.Start != nil && c.End != nil && !c.Start.Before(*c.End) {
+		errs = append(errs, FieldError{"end", "must be after start"})
+	}
+	if (c.From == "") != (c.Until == "") {
+		errs = append(errs, FieldError{"until", "from and until must be set together"})
+	} else if c.From != "" && c.From == c.Until {
+		errs = append(errs, FieldError{"until", "must differ from from"})
+	}
+	return errs
+}
@@ -0,0 +1,203 @@
+package design
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation describes one endpoint of the v1 API for the OpenAPI document.
+type Operation struct {
+	Method, Path string
+	Summary      string
+	// Request is a value of the JSON request body type, nil for none.
+	Request any
+	// Responses maps a status code to the media types returned with it; a
+	// media type mapped to a Go value is described by its schema, one
+	// mapped to nil is a binary body.
+	Responses map[string]map[string]any
+}
+
+// OpenAPI builds an OpenAPI 3 document for ops, deriving every schema from
+// the Go types and their tags. Limits of the server configuration, tagged
+// like maxLength:"$maxURLLength", are taken from vars.
+func OpenAPI(title, version string, ops []Operation, vars map[string]int) map[string]any {
+	g := &schemaGen{schemas: map[string]any{}, vars: vars}
+	paths := map[string]any{}
+	for _, op := range ops {
+		item, _ := paths[op.Path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[op.Path] = item
+		}
+		o := map[string]any{"summary": op.Summary}
+		if op.Request != nil {
+			o["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.Request))}},
+			}
+		}
+		responses := map[string]any{}
+		for status, media := range op.Responses {
+			content := map[string]any{}
+			for mediaType, body := range media {
+				if body == nil {
+					content[mediaType] = map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}
+				} else {
+					content[mediaType] = map[string]any{"schema": g.schema(reflect.TypeOf(body))}
+				}
+			}
+			response := map[string]any{"description": statusDescription(status)}
+			if len(content) > 0 {
+				response["content"] = content
+			}
+			responses[status] = response
+		}
+		o["responses"] = responses
+		item[strings.ToLower(op.Method)] = o
+	}
+	return map[string]any{
+		"openapi":    "3.0.3",
+		"info":       map[string]any{"title": title, "version": version},
+		"paths":      paths,
+		"components": map[string]any{"schemas": g.schemas},
+	}
+}
+
+func statusDescription(status string) string {
+	switch status {
+	case "200":
+		return "OK"
+	case "201":
+		return "Created"
+	case "202":
+		return "Accepted"
+	case "204":
+		return "No content"
+	case "400":
+		return "Invalid request; see fields"
+	case "401":
+		return "Authentication required"
+	case "403":
+		return "Not allowed"
+	case "404":
+		return "Not found"
+	case "409":
+		return "Conflict with the current state"
+	case "410":
+		return "Gone"
+	case "429":
+		return "Rate limit or quota exceeded; see Retry-After"
+	}
+	return status
+}
+
+// schemaGen collects the named schemas of struct types.
+type schemaGen struct {
+	schemas map[string]any
+	vars    map[string]int
+}
+
+// schema returns the schema of t; structs are added to the components and
+// referenced by name.
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, ok := g.schemas[t.Name()]; !ok {
+			g.schemas[t.Name()] = nil // guard against recursive types
+			g.schemas[t.Name()] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	}
+	return map[string]any{}
+}
+
+// object describes a struct: its properties with the constraints from the
+// field tags. Unknown properties are rejected, as Decode does.
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name := jsonName(f)
+		if name == "" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			// Promoted fields are properties of the outer object.
+			embedded := g.object(f.Type)
+			for k, v := range embedded["properties"].(map[string]any) {
+				props[k] = v
+			}
+			if req, ok := embedded["required"].([]string); ok {
+				required = append(required, req...)
+			}
+			continue
+		}
+		s := g.schema(f.Type)
+		ref, isRef := s["$ref"]
+		if doc := f.Tag.Get("doc"); doc != "" {
+			s["description"] = doc
+		}
+		// String constraints of a []string field apply to its items
+		str := s
+		if f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.String {
+			str = s["items"].(map[string]any)
+		}
+		if enum, ok := f.Tag.Lookup("enum"); ok {
+			str["enum"] = strings.Split(enum, ",")
+		}
+		if def, ok := f.Tag.Lookup("default"); ok {
+			s["default"] = def
+		}
+		if p, ok := f.Tag.Lookup("pattern"); ok {
+			str["pattern"] = p
+		}
+		if v, ok := f.Tag.Lookup("maxLength"); ok {
+			n, _ := strconv.ParseFloat(v, 64)
+			if name, ok := strings.CutPrefix(v, "$"); ok {
+				n = float64(g.vars[name])
+			}
+			str["maxLength"] = n
+		}
+		for tag, key := range map[string]string{"min": "minimum", "max": "maximum"} {
+			if v, ok := f.Tag.Lookup(tag); ok {
+				n, _ := strconv.ParseFloat(v, 64)
+				s[key] = n
+			}
+		}
+		if isRef && len(s) > 1 {
+			// Siblings of $ref are ignored in OpenAPI 3.0, so wrap it.
+			delete(s, "$ref")
+			s["allOf"] = []any{map[string]any{"$ref": ref}}
+		}
+		props[name] = s
+		if f.Tag.Get("required") == "true" {
+			required = append(required, name)
+		}
+	}
+	obj := map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	if len(required) > 0 {
+		obj["required"] = required
+	}
+	return obj
+}
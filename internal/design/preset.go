@@ -0,0 +1,8 @@
+package design
+
+// Preset is a named style saved for reuse.
+type Preset struct {
+	Name   string `json:"name" required:"true" maxLength:"80"`
+	Kind   string `json:"kind" enum:"preset,brand" default:"preset" doc:"brand marks a brand kit: an organization's colors, frame and logo."`
+	Design Style  `json:"design" doc:"Design document without content, logo reference included."`
+}
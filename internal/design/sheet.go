@@ -0,0 +1,92 @@
+package design
+
+import (
+	"fmt"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/sheet"
+)
+
+// MaxSheetLabels bounds the number of labels of a sheet, copies included.
+const MaxSheetLabels = 5000
+
+// Sheet is a set of codes printed on label stock.
+type Sheet struct {
+	Design         Style           `json:"design" doc:"Style shared by every label."`
+	Template       string          `json:"template,omitempty" maxLength:"60" doc:"Name of a bundled label template; see GET /api/v1/sheet/templates. Ignored when customTemplate is set."`
+	CustomTemplate *sheet.Template `json:"customTemplate,omitempty" doc:"Label template for stock that is not in the catalog."`
+	Caption        string          `json:"caption,omitempty" maxLength:"200" doc:"Caption template printed on every label, with the placeholders of a batch filename. A label's own caption replaces it."`
+	Labels         []SheetLabel    `json:"labels" required:"true" independent:"true" doc:"Labels in reading order."`
+	Copies         int             `json:"copies,omitempty" min:"0" max:"1000" doc:"How many times each label is printed; 0 means once."`
+	Skip           int             `json:"skip,omitempty" min:"0" max:"1000" doc:"Positions to leave empty at the start of the first page, to reuse a partly used sheet."`
+	CutLines       bool            `json:"cutLines,omitempty" doc:"Draw a hairline around every label."`
+	Bleed          float64         `json:"bleed,omitempty" min:"0" max:"5" doc:"How far the background color extends past each label, in mm."`
+	Padding        *float64        `json:"padding,omitempty" min:"0" max:"20" doc:"Space between the edge of a label and its content, in mm. Defaults to 2."`
+	FontSize       float64         `json:"fontSize,omitempty" min:"0" max:"36" doc:"Caption size in points; 0 picks one from the label height."`
+	Campaign       *Campaign       `json:"campaign,omitempty" doc:"Tags added to the URL of every url label that has no campaign of its own. Values take the placeholders of the caption template."`
+}
+
+// SheetLabel is one code of a sheet.
+type SheetLabel struct {
+	Content   Content           `json:"content" required:"true"`
+	Overrides map[string]any    `json:"overrides,omitempty" doc:"Style fields to change for this label, merged over the shared design (JSON merge patch)."`
+	Caption   string            `json:"caption,omitempty" maxLength:"200" doc:"Caption of this label; replaces the caption template."`
+	Fields    map[string]string `json:"fields,omitempty" doc:"Values for {name} placeholders in the caption template."`
+}
+
+// Validate resolves the template and checks that the labels fit.
+func (s *Sheet) Validate() []FieldError {
+	var errs []FieldError
+	if s.CustomTemplate != nil {
+		if err := s.CustomTemplate.Check(); err != nil {
+			errs = append(errs, FieldError{"customTemplate", err.Error()})
+		}
+	} else if s.Template == "" {
+		errs = append(errs, FieldError{"template", "is required unless customTemplate is set"})
+	} else if _, ok := sheet.Lookup(s.Template); !ok {
+		errs = append(errs, FieldError{"template", "unknown template"})
+	}
+	if len(errs) == 0 && s.Skip >= s.LabelTemplate().PerPage() {
+		errs = append(errs, FieldError{"skip", fmt.Sprintf("must be less than the %d labels of a page", s.LabelTemplate().PerPage())})
+	}
+	switch n := len(s.Labels) * max(s.Copies, 1); {
+	case len(s.Labels) == 0:
+		errs = append(errs, FieldError{"labels", "must contain at least one label"})
+	case n > MaxSheetLabels:
+		errs = append(errs, FieldError{"labels", fmt.Sprintf("must come to at most %d labels with copies", MaxSheetLabels)})
+	}
+	return errs
+}
+
+// LabelTemplate returns the template of a validated sheet.
+func (s *Sheet) LabelTemplate() sheet.Template {
+	if s.CustomTemplate != nil {
+		return *s.CustomTemplate
+	}
+	t, _ := sheet.Lookup(s.Template)
+	return t
+}
+
+// LabelDesign returns the design of label index (0-based): its content
+// with the shared campaign, and the shared style with the label's
+// overrides merged over it.
+func (s *Sheet) LabelDesign(index int, label SheetLabel) (*Design, error) {
+	return mergeDesign(s.Design, withCampaign(label.Content, s.Campaign, index, len(s.Labels), label.Fields), label.Overrides)
+}
+
+// LabelCaption returns the caption of label index (0-based).
+func (s *Sheet) LabelCaption(index int, label SheetLabel) string {
+	if label.Caption != "" {
+		return label.Caption
+	}
+	return expand(s.Caption, index, len(s.Labels), label.Content, label.Fields)
+}
+
+// SheetLabels turns batch rows, e.g. from ParseCSVRows, into labels. A
+// caption field becomes the label's caption.
+func SheetLabels(rows []BatchRow) []SheetLabel {
+	labels := make([]SheetLabel, len(rows))
+	for i, row := range rows {
+		labels[i] = SheetLabel{Content: row.Content, Overrides: row.Overrides, Caption: row.Fields["caption"], Fields: row.Fields}
+	}
+	return labels
+}
@@ -0,0 +1,10 @@
+package design
+
+import "time"
+
+// TokenRequest asks for a signed design token: a design and, optionally,
+// when the token stops working.
+type TokenRequest struct {
+	Design
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" doc:"When the token stops working (RFC 3339). Omitted, it never expires."`
+}
@@ -0,0 +1,402 @@
+package design
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// FieldError reports a problem with one field of a request, addressed by
+// its dotted JSON path (e.g. "colors.foreground").
+type FieldError struct {
+	Field   string `json:"field" doc:"Dotted JSON path of the field; empty for the document as a whole."`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when a document does not match the schema.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = strings.TrimPrefix(f.Field+": "+f.Message, ": ")
+	}
+	return "invalid design: " + strings.Join(msgs, "; ")
+}
+
+// ErrorResponse is the body of every 4xx response of the v1 API.
+type ErrorResponse struct {
+	Error           string       `json:"error" required:"true"`
+	Fields          []FieldError `json:"fields,omitempty" doc:"Field-level validation errors."`
+	RequiredVersion int          `json:"requiredVersion,omitempty" doc:"QR version the payload needs when it does not fit maxVersion."`
+}
+
+// validator is implemented by types with rules spanning several fields.
+// Field names in the errors are relative to the receiver.
+type validator interface {
+	Validate() []FieldError
+}
+
+// Decode reads a JSON document into v, rejecting unknown fields and
+// trailing data, then fills defaults and validates it. Problems with the
+// document are returned as a *ValidationError; read errors as they are.
+func Decode(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if fe, ok := decodeFieldError(err); ok {
+			if fe.Message == "unknown field" {
+				fe.Field = unknownFieldPath(data, reflect.TypeOf(v), fe.Field)
+			}
+			return &ValidationError{Fields: []FieldError{fe}}
+		}
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return &ValidationError{Fields: []FieldError{{Message: "unexpected data after the JSON document"}}}
+	}
+	return Check(v)
+}
+
+// Check fills defaults in v, a pointer to a struct, and validates it
+// against its tags and Validate methods.
+func Check(v any) error {
+	var errs []FieldError
+	walk(reflect.ValueOf(v).Elem(), "", &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// decodeFieldError turns a json decoding error into a field error. It
+// reports false for errors reading the body.
+func decodeFieldError(err error) (FieldError, bool) {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		return FieldError{typeErr.Field, fmt.Sprintf("must be %s, not %s", jsonTypeName(typeErr.Type), typeErr.Value)}, true
+	case errors.As(err, &syntaxErr):
+		return FieldError{Message: fmt.Sprintf("malformed JSON at offset %d: %v", syntaxErr.Offset, err)}, true
+	case errors.Is(err, io.EOF):
+		return FieldError{Message: "request body is empty"}, true
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return FieldError{Message: "malformed JSON: unexpected end of input"}, true
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		name, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		return FieldError{name, "unknown field"}, true
+	}
+	return FieldError{}, false
+}
+
+// unknownFieldPath finds the dotted path of the unknown field name in data,
+// which encoding/json reports without its parents, by walking the
+// document along the type t. It returns name when the walk does not find
+// it.
+func unknownFieldPath(data []byte, t reflect.Type, name string) string {
+	if path, ok := findUnknown(json.NewDecoder(bytes.NewReader(data)), t, ""); ok {
+		return path
+	}
+	return name
+}
+
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// findUnknown reads one value from dec, the JSON of a t at path, and
+// reports the path of its first object key t has no field for. A nil t
+// skips the value.
+func findUnknown(dec *json.Decoder, t reflect.Type, path string) (string, bool) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil && (t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(unmarshalerType)) {
+		t = nil
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return "", false
+	}
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return "", false
+			}
+			key, _ := tok.(string)
+			var ft reflect.Type
+			fpath := joinPath(path, key)
+			switch {
+			case t == nil:
+			case t.Kind() == reflect.Map:
+				ft = t.Elem()
+			case t.Kind() == reflect.Struct:
+				f, ok := fieldByJSONName(t, key)
+				if !ok {
+					return fpath, true
+				}
+				ft, fpath = f.Type, joinPath(path, jsonName(f))
+			}
+			if p, ok := findUnknown(dec, ft, fpath); ok {
+				return p, true
+			}
+		}
+		dec.Token()
+	case json.Delim('['):
+		var et reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			et = t.Elem()
+		}
+		for i := 0; dec.More(); i++ {
+			if p, ok := findUnknown(dec, et, fmt.Sprintf("%s[%d]", path, i)); ok {
+				return p, true
+			}
+		}
+		dec.Token()
+	}
+	return "", false
+}
+
+// fieldByJSONName finds the field of the struct t that encoding/json
+// decodes key into, matching names without regard to case as it does.
+func fieldByJSONName(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if ef, ok := fieldByJSONName(f.Type, key); ok {
+				return ef, true
+			}
+			continue
+		}
+		if name := jsonName(f); name != "" && strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// jsonTypeName names a Go type the way the schema does.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice:
+		return "an array"
+	}
+	return "an object"
+}
+
+// walk applies defaults and checks the tags of every field of the struct
+// v, recursing into nested structs, then runs its Validate method.
+func walk(v reflect.Value, path string, errs *[]FieldError) {
+	before := len(*errs)
+	walkFields(v, path, errs)
+
+	// Cross-field rules only make sense once the fields themselves are valid.
+	if len(*errs) > before {
+		return
+	}
+	if val, ok := v.Addr().Interface().(validator); ok {
+		for _, e := range val.Validate() {
+			e.Field = joinPath(path, e.Field)
+			*errs = append(*errs, e)
+		}
+	}
+}
+
+// walkFields applies defaults and checks the tags of the fields of v.
+func walkFields(v reflect.Value, path string, errs *[]FieldError) {
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name := jsonName(f)
+		if name == "" {
+			continue
+		}
+		fv := v.Field(i)
+		if f.Anonymous && fv.Kind() == reflect.Struct {
+			// The fields of embedded structs are promoted, and so is their
+			// Validate method, which runs with the outer struct's.
+			walkFields(fv, path, errs)
+			continue
+		}
+		fpath := joinPath(path, name)
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				if f.Tag.Get("required") == "true" {
+					*errs = append(*errs, FieldError{fpath, "is required"})
+				}
+				continue
+			}
+			fv = fv.Elem()
+		}
+		switch fv.Kind() {
+		case reflect.Struct:
+			walk(fv, fpath, errs)
+		case reflect.Slice:
+			switch elem := fv.Type().Elem().Kind(); {
+			case elem == reflect.Struct && f.Tag.Get("independent") != "true":
+				for j := range fv.Len() {
+					walk(fv.Index(j), fmt.Sprintf("%s[%d]", fpath, j), errs)
+				}
+			case elem == reflect.String:
+				for j := range fv.Len() {
+					if msg := checkString(f, fv.Index(j)); msg != "" {
+						*errs = append(*errs, FieldError{fmt.Sprintf("%s[%d]", fpath, j), msg})
+					}
+				}
+			}
+		case reflect.String:
+			if msg := checkString(f, fv); msg != "" {
+				*errs = append(*errs, FieldError{fpath, msg})
+			}
+		case reflect.Int:
+			if msg := checkInt(f, fv.Int()); msg != "" {
+				*errs = append(*errs, FieldError{fpath, msg})
+			}
+		case reflect.Float64:
+			if msg := checkFloat(f, fv.Float()); msg != "" {
+				*errs = append(*errs, FieldError{fpath, msg})
+			}
+		}
+	}
+}
+
+// checkString fills the default of an empty string field and checks it.
+func checkString(f reflect.StructField, v reflect.Value) string {
+	s := v.String()
+	if s == "" {
+		if def, ok := f.Tag.Lookup("default"); ok {
+			v.SetString(def)
+			return ""
+		}
+		if f.Tag.Get("required") == "true" {
+			return "is required"
+		}
+		return ""
+	}
+	if enum, ok := f.Tag.Lookup("enum"); ok && !slices.Contains(strings.Split(enum, ","), s) {
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(enum, ",", ", "))
+	}
+	// A maxLength of $name is a limit of the server configuration, left to
+	// the handlers; see OpenAPI
+	if n, ok := f.Tag.Lookup("maxLength"); ok && !strings.HasPrefix(n, "$") {
+		if limit, _ := strconv.Atoi(n); utf8.RuneCountInString(s) > limit {
+			return fmt.Sprintf("must be at most %d characters", limit)
+		}
+	}
+	if p, ok := f.Tag.Lookup("pattern"); ok && !compiled(p).MatchString(s) {
+		return fmt.Sprintf("must match %s", p)
+	}
+	return ""
+}
+
+// checkInt checks an integer field against its bounds.
+func checkInt(f reflect.StructField, n int64) string {
+	lo, hasLo := intTag(f, "min")
+	hi, hasHi := intTag(f, "max")
+	switch {
+	case hasLo && hasHi && (n < lo || n > hi):
+		return fmt.Sprintf("must be between %d and %d", lo, hi)
+	case hasLo && n < lo:
+		return fmt.Sprintf("must be at least %d", lo)
+	case hasHi && n > hi:
+		return fmt.Sprintf("must be at most %d", hi)
+	}
+	return ""
+}
+
+// checkFloat checks a number field against its bounds.
+func checkFloat(f reflect.StructField, x float64) string {
+	lo, hasLo := floatTag(f, "min")
+	hi, hasHi := floatTag(f, "max")
+	switch {
+	case hasLo && hasHi && (x < lo || x > hi):
+		return fmt.Sprintf("must be between %g and %g", lo, hi)
+	case hasLo && x < lo:
+		return fmt.Sprintf("must be at least %g", lo)
+	case hasHi && x > hi:
+		return fmt.Sprintf("must be at most %g", hi)
+	}
+	return ""
+}
+
+func floatTag(f reflect.StructField, key string) (float64, bool) {
+	v, ok := f.Tag.Lookup(key)
+	if !ok {
+		return 0, false
+	}
+	x, err := strconv.ParseFloat(v, 64)
+	return x, err == nil
+}
+
+func intTag(f reflect.StructField, key string) (int64, bool) {
+	v, ok := f.Tag.Lookup(key)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	return n, err == nil
+}
+
+// jsonName returns the JSON name of a field, or "" if it is not encoded.
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	if name == "" {
+		return path
+	}
+	return path + "." + name
+}
+
+var (
+	patternsMu sync.Mutex
+	patterns   = map[string]*regexp.Regexp{}
+)
+
+// compiled returns the compiled form of a pattern tag.
+func compiled(p string) *regexp.Regexp {
+	patternsMu.Lock()
+	defer patternsMu.Unlock()
+	re, ok := patterns[p]
+	if !ok {
+		re = regexp.MustCompile(p)
+		patterns[p] = re
+	}
+	return re
+}
@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/barcode"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/logging"
+	"github.com/fogleman/gg"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/freetype/truetype"
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// barcodeFontSize is the height of the human-readable text in modules.
+	barcodeFontSize = 9.0
+	// barcodeMargin is the space above and below the symbol in modules.
+	barcodeMargin = 2.0
+)
+
+// barcodeRect is a filled rectangle in module units.
+type barcodeRect struct{ x, y, w, h float64 }
+
+// barcodeText is a line of human-readable text centred on x with its
+// baseline at y, in module units.
+type barcodeText struct {
+	text string
+	x, y float64
+}
+
+// barcodeLayout is a linear symbol laid out in module units, ready to be
+// drawn by the raster, SVG and PDF backends.
+type barcodeLayout struct {
+	width, height float64
+	bars          []barcodeRect
+	text          []barcodeText
+}
+
+// layoutBarcode places the bars, bearer bars and text of code. Bars are
+// narrowed by reduction modules, half on each side, to offset ink spread.
+func layoutBarcode(code *barcode.Code, barHeight float64, showText bool, reduction float64) barcodeLayout {
+	l := barcodeLayout{width: float64(code.QuietLeft + len(code.Modules) + code.QuietRight)}
+	top := barcodeMargin + float64(code.Bearer)
+	bottom := top + barHeight
+
+	// Guard bars reach halfway into the text.
+	guardBottom := bottom
+	if showText {
+		guardBottom += barcodeFontSize / 2
+	}
+	for i := 0; i < len(code.Modules); {
+		if !code.Modules[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < len(code.Modules) && code.Modules[j] {
+			j++
+		}
+		h := barHeight
+		if code.Guards != nil && code.Guards[i] {
+			h = guardBottom - top
+		}
+		x := float64(code.QuietLeft + i)
+		l.bars = append(l.bars, barcodeRect{x: x + reduction/2, y: top, w: float64(j-i) - reduction, h: h})
+		i = j
+	}
+
+	if code.Bearer > 0 {
+		b := float64(code.Bearer)
+		l.bars = append(l.bars,
+			barcodeRect{x: 0, y: barcodeMargin, w: l.width, h: b},
+			barcodeRect{x: 0, y: bottom, w: l.width, h: b})
+		bottom += b
+	}
+
+	l.height = bottom + barcodeMargin
+	if showText {
+		baseline := bottom + barcodeFontSize
+		for _, span := range code.Text {
+			l.text = append(l.text, barcodeText{
+				text: span.Text,
+				x:    float64(code.QuietLeft) + float64(span.Start+span.End)/2,
+				y:    baseline,
+			})
+		}
+		l.height = baseline + barcodeMargin
+	}
+	return l
+}
+
+var (
+	barcodeFontOnce sync.Once
+	barcodeTTF      *truetype.Font
+	barcodeSFNT     *sfnt.Font
+	barcodeFontErr  error
+)
+
+// barcodeFonts parses the embedded Go Mono font used for human-readable
+// text, once.
+func barcodeFonts() (*truetype.Font, *sfnt.Font, error) {
+	barcodeFontOnce.Do(func() {
+		if barcodeTTF, barcodeFontErr = truetype.Parse(gomono.TTF); barcodeFontErr != nil {
+			return
+		}
+		barcodeSFNT, barcodeFontErr = sfnt.Parse(gomono.TTF)
+	})
+	return barcodeTTF, barcodeSFNT, barcodeFontErr
+}
+
+// BarcodeHandler generates linear barcodes (Code 128, GS1-128, EAN-13,
+// UPC-A, Code 39, ITF-14) as PNG, JPG, SVG or PDF
+func (h *Handler) BarcodeHandler(c *gin.Context) {
+	sym, err := barcode.ParseSymbology(c.Query("symbology"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payload, err := h.codePayload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format, err := outputFormat(c, "png", "jpg", "svg", "pdf")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code, err := barcode.Encode(sym, payload, barcode.Options{Checksum: c.Query("checksum") == "true"})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, barcode.ErrInvalidData) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	barHeight := float64(code.Height)
+	if v := c.Query("height"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 5 || n > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid height %q (use 5-500 modules)", v)})
+			return
+		}
+		barHeight = float64(n)
+	}
+	reduction := 0.0
+	if v := c.Query("bwr"); v != "" {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 || n > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid bwr %q (use 0-50 percent of the module width)", v)})
+			return
+		}
+		reduction = n / 100
+	}
+	layout := layoutBarcode(code, barHeight, c.DefaultQuery("hrt", "true") != "false", reduction)
+	xdim := barcodeModuleWidth(c, layout)
+	width, height := int(math.Ceil(layout.width*float64(xdim))), int(math.Ceil(layout.height*float64(xdim)))
+	if (format == "png" || format == "jpg") && width*height > maxBarcodePixels {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("barcode would be %dx%d pixels, more than the limit of %d (lower moduleWidth, previewSize or height)", width, height, maxBarcodePixels)})
+		return
+	}
+
+	fg := parseColorParam(c.Query("fg"), color.RGBA{0, 0, 0, 255})
+	bg := parseColorParam(c.Query("bg"), color.RGBA{255, 255, 255, 255})
+
+	logger(c).Debug("barcode request", "symbology", sym, "data", logging.Payload(code.Data), "format", format)
+	c.Header("X-Barcode-Data", code.Data)
+	c.Header("Cache-Control", h.imageCacheControl())
+
+	switch format {
+	case "pdf":
+		mm := 0.33
+		if v := c.Query("xdim"); v != "" {
+			if mm, err = strconv.ParseFloat(v, 64); err != nil || mm < 0.1 || mm > 2 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid xdim %q (use 0.1-2 mm)", v)})
+				return
+			}
+		}
+		c.Header("Content-Type", "application/pdf")
+		err = writeBarcodePDF(c.Writer, layout, mm, fg, bg)
+	case "svg":
+		c.Header("Content-Type", "image/svg+xml")
+		err = writeBarcodeSVG(c.Writer, layout, xdim, fg, bg)
+	default:
+		contentType := "image/png"
+		if format == "jpg" {
+			contentType = "image/jpeg"
+		}
+		st := qrStyle{size: c.DefaultQuery("size", "preview"), frame: "none"}
+		h.sendImage(c, "", contentType, rasterCost(width, height, format), st, format, func() ([]byte, error) {
+			img, err := rasterizeBarcode(layout, xdim, fg, bg)
+			if err != nil {
+				return nil, err
+			}
+			var buf bytes.Buffer
+			if format == "jpg" {
+				err = writeJPEG(&buf, img.Image(), bg)
+			} else {
+				err = png.Encode(&buf, img.Image())
+			}
+			return buf.Bytes(), err
+		})
+		return
+	}
+	if err != nil {
+		logger(c).Warn("failed to write barcode", "err", err)
+	}
+}
+
+// barcodeModuleWidth returns the module width in pixels: moduleWidth when
+// given, the width that fits previewSize for previews, or 8 for downloads.
+func barcodeModuleWidth(c *gin.Context, l barcodeLayout) int {
+	if n, err := strconv.Atoi(c.Query("moduleWidth")); err == nil && n > 0 {
+		return min(n, 40)
+	}
+	if c.DefaultQuery("size", "preview") == "preview" {
+		if ps, err := strconv.Atoi(c.Query("previewSize")); err == nil && ps > 0 {
+			return max(1, min(ps, maxPreviewSize)/int(math.Ceil(l.width)))
+		}
+		return 3
+	}
+	return 8
+}
+
+// rasterizeBarcode draws the layout with xdim pixels per module. Bar edges
+// are snapped to whole pixels so bars stay sharp; the width reduction is
+// therefore applied to the nearest pixel.
+func rasterizeBarcode(l barcodeLayout, xdim int, fg, bg color.RGBA) (*gg.Context, error) {
+	s := float64(xdim)
+	dc := gg.NewContext(int(math.Ceil(l.width*s)), int(math.Ceil(l.height*s)))
+	if bg.A > 0 {
+		dc.SetColor(bg)
+		dc.Clear()
+	}
+	dc.SetColor(fg)
+	for _, b := range l.bars {
+		x0, x1 := math.Round(b.x*s), math.Round((b.x+b.w)*s)
+		if x1 <= x0 {
+			x1 = x0 + 1
+		}
+		dc.DrawRectangle(x0, math.Round(b.y*s), x1-x0, math.Round(b.h*s))
+	}
+	dc.Fill()
+
+	if len(l.text) > 0 {
+		ttf, _, err := barcodeFonts()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load barcode font: %v", err)
+		}
+		dc.SetFontFace(truetype.NewFace(ttf, &truetype.Options{Size: barcodeFontSize * s, Hinting: font.HintingFull}))
+		for _, t := range l.text {
+			dc.DrawStringAnchored(t.text, t.x*s, t.y*s, 0.5, 0)
+		}
+	}
+	return dc, nil
+}
+
+// writeBarcodeSVG writes the layout as SVG in module units, scaled to xdim
+// pixels per module. Text is converted to glyph outlines so it renders the
+// same without the font installed.
+func writeBarcodeSVG(w io.Writer, l barcodeLayout, xdim int, fg, bg color.RGBA) error {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	sb.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %s %s" width="%s" height="%s" shape-rendering="crispEdges">`,
+		svgNum(l.width), svgNum(l.height), svgNum(l.width*float64(xdim)), svgNum(l.height*float64(xdim))))
+	if bg.A > 0 {
+		sb.WriteString(fmt.Sprintf(`<rect width="100%%" height="100%%" fill="rgb(%d,%d,%d)"/>`, bg.R, bg.G, bg.B))
+	}
+	sb.WriteString(fmt.Sprintf(`<g fill="rgb(%d,%d,%d)">`, fg.R, fg.G, fg.B))
+	for _, b := range l.bars {
+		sb.WriteString(fmt.Sprintf(`<rect x="%s" y="%s" width="%s" height="%s"/>`, svgNum(b.x), svgNum(b.y), svgNum(b.w), svgNum(b.h)))
+	}
+	if len(l.text) > 0 {
+		_, f, err := barcodeFonts()
+		if err != nil {
+			return fmt.Errorf("failed to load barcode font: %v", err)
+		}
+		for _, t := range l.text {
+			d, err := svgTextPath(f, t)
+			if err != nil {
+				return err
+			}
+			sb.WriteString(`<path d="` + d + `"/>`)
+		}
+	}
+	sb.WriteString(`</g></svg>`)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// svgTextPath converts a line of text to SVG path data in module units.
+func svgTextPath(f *sfnt.Font, t barcodeText) (string, error) {
+	var buf sfnt.Buffer
+	ppem := fixed.Int26_6(barcodeFontSize * 64)
+	glyphs := make([]sfnt.GlyphIndex, 0, len(t.text))
+	width := fixed.Int26_6(0)
+	for _, r := range t.text {
+		g, err := f.GlyphIndex(&buf, r)
+		if err != nil {
+			return "", err
+		}
+		adv, err := f.GlyphAdvance(&buf, g, ppem, font.HintingNone)
+		if err != nil {
+			return "", err
+		}
+		glyphs = append(glyphs, g)
+		width += adv
+	}
+
+	var d strings.Builder
+	x := t.x - float64(width)/128
+	pt := func(p fixed.Point26_6) string {
+		return svgNum(x+float64(p.X)/64) + " " + svgNum(t.y+float64(p.Y)/64)
+	}
+	for _, g := range glyphs {
+		segs, err := f.LoadGlyph(&buf, g, ppem, nil)
+		if err != nil {
+			return "", err
+		}
+		for _, s := range segs {
+			switch s.Op {
+			case sfnt.SegmentOpMoveTo:
+				d.WriteString("M" + pt(s.Args[0]))
+			case sfnt.SegmentOpLineTo:
+				d.WriteString("L" + pt(s.Args[0]))
+			case sfnt.SegmentOpQuadTo:
+				d.WriteString("Q" + pt(s.Args[0]) + " " + pt(s.Args[1]))
+			case sfnt.SegmentOpCubeTo:
+				d.WriteString("C" + pt(s.Args[0]) + " " + pt(s.Args[1]) + " " + pt(s.Args[2]))
+			}
+		}
+		adv, err := f.GlyphAdvance(&buf, g, ppem, font.HintingNone)
+		if err != nil {
+			return "", err
+		}
+		x += float64(adv) / 64
+	}
+	return d.String(), nil
+}
+
+// svgNum formats a coordinate with at most three decimals.
+func svgNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// writeBarcodePDF writes the layout as a single-page PDF the size of the
+// symbol, with xdim millimetres per module and the font embedded.
+func writeBarcodePDF(w io.Writer, l barcodeLayout, xdim float64, fg, bg color.RGBA) error {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: l.width * xdim, Ht: l.height * xdim},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	if bg.A > 0 {
+		pdf.SetFillColor(int(bg.R), int(bg.G), int(bg.B))
+		pdf.Rect(0, 0, l.width*xdim, l.height*xdim, "F")
+	}
+	pdf.SetFillColor(int(fg.R), int(fg.G), int(fg.B))
+	for _, b := range l.bars {
+		pdf.Rect(b.x*xdim, b.y*xdim, b.w*xdim, b.h*xdim, "F")
+	}
+	if len(l.text) > 0 {
+		pdf.AddUTF8FontFromBytes("gomono", "", gomono.TTF)
+		pdf.SetFont("gomono", "", 10)
+		pdf.SetFontUnitSize(barcodeFontSize * xdim)
+		pdf.SetTextColor(int(fg.R), int(fg.G), int(fg.B))
+		for _, t := range l.text {
+			pdf.Text(t.x*xdim-pdf.GetStringWidth(t.text)/2, t.y*xdim, t.text)
+		}
+	}
+	return pdf.Output(w)
+}
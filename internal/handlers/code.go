@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/aztec"
+	"github.com/boombuler/barcode/datamatrix"
+	"github.com/boombuler/barcode/pdf417"
+	"github.com/gin-gonic/gin"
+)
+
+// maxCodePayload bounds the payload accepted by the 2D symbologies; the
+// largest Aztec symbol holds a little under 2 KB of bytes.
+const maxCodePayload = 4 << 10
+
+// matrixSymbology is a 2D symbology rendered through the QR styling
+// pipeline.
+type matrixSymbology struct {
+	// shapes lists the module shapes readers still decode; the connected
+	// shapes would merge bars and rings.
+	shapes    []string
+	quietZone int // modules
+	encode    func(c *gin.Context, payload string) ([][]bool, error)
+}
+
+var matrixSymbologies = map[string]matrixSymbology{
+	"datamatrix": {shapes: []string{"rectangle", "circle"}, quietZone: 2, encode: encodeDataMatrix},
+	"aztec":      {shapes: []string{"rectangle"}, quietZone: 2, encode: encodeAztec},
+	"pdf417":     {shapes: []string{"rectangle"}, quietZone: 2, encode: encodePDF417},
+}
+
+// CodeHandler generates Data Matrix, Aztec and PDF417 symbols with the same
+// colors, padding, frames and formats as QR codes
+func (h *Handler) CodeHandler(c *gin.Context) {
+	name := strings.ToLower(strings.TrimSpace(c.Query("symbology")))
+	sym, ok := matrixSymbologies[name]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported symbology %q (use datamatrix, aztec or pdf417)", name)})
+		return
+	}
+
+	payload, err := h.codePayload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format, err := outputFormat(c, "png", "jpg", "svg")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	st := h.parseQRStyle(c)
+	if !slices.Contains(sym.shapes, st.qrShape) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("qrShape %q is not supported for %s (use %s)", st.qrShape, name, strings.Join(sym.shapes, " or "))})
+		return
+	}
+	// The symbology's own quiet zone replaces the QR padding, and none of
+	// these symbols leave room for a logo
+	st.quietZone = sym.quietZone
+	st.centerLogo = "false"
+
+	done := h.stage(c.Request.Context(), stageEncode)
+	bitmap, err := sym.encode(c, payload)
+	done()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logger(c).Debug("code request", "symbology", name, "bytes", len(payload), "format", format, "size", st.size)
+	c.Header("X-Code-Symbology", name)
+	c.Header("X-Code-Modules", fmt.Sprintf("%dx%d", len(bitmap[0]), len(bitmap)))
+
+	if format == "svg" {
+		h.generateSVGQR(c, bitmap, st)
+		return
+	}
+	h.generatePNGQR(c, bitmap, st, format)
+}
+
+// outputFormat reads the format parameter (default png) and checks it is
+// one of allowed. "jpeg" is accepted as "jpg".
+func outputFormat(c *gin.Context, allowed ...string) (string, error) {
+	format := strings.ToLower(c.DefaultQuery("format", "png"))
+	if format == "jpeg" {
+		format = "jpg"
+	}
+	if !slices.Contains(allowed, format) {
+		return "", fmt.Errorf("unsupported format %q (use %s)", format, strings.Join(allowed, ", "))
+	}
+	return format, nil
+}
+
+// codePayload reads the payload from the data parameter or, failing that,
+// the url parameter.
+func (h *Handler) codePayload(c *gin.Context) (string, error) {
+	payload := c.Query("data")
+	if payload == "" {
+		rawURL := strings.TrimSpace(c.Query("url"))
+		if rawURL == "" {
+			return "", fmt.Errorf("data or url parameter is required")
+		}
+		u, err := h.normalizeTaggedURL(rawURL, c)
+		if err != nil {
+			return "", err
+		}
+		return h.checkURL(c, u)
+	}
+	if len(payload) > maxCodePayload {
+		return "", fmt.Errorf("payload exceeds %d bytes", maxCodePayload)
+	}
+	return payload, nil
+}
+
+// encodeDataMatrix encodes an ECC 200 Data Matrix symbol; the size is
+// chosen by the encoder.
+func encodeDataMatrix(c *gin.Context, payload string) ([][]bool, error) {
+	bc, err := datamatrix.Encode(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode data matrix: %v", err)
+	}
+	return barcodeBitmap(bc, 1, 1), nil
+}
+
+// encodeAztec encodes an Aztec symbol. ecc sets the minimum error
+// correction percentage (5-95, default 33) and layers forces a compact
+// (-1 to -4) or full-range (1 to 32) symbol.
+func encodeAztec(c *gin.Context, payload string) ([][]bool, error) {
+	ecc, layers := aztec.DEFAULT_EC_PERCENT, aztec.DEFAULT_LAYERS
+	if v := c.Query("ecc"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 5 || n > 95 {
+			return nil, fmt.Errorf("invalid ecc %q for aztec (use 5-95 percent)", v)
+		}
+		ecc = n
+	}
+	if v := c.Query("layers"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < -4 || n > 32 {
+			return nil, fmt.Errorf("invalid layers %q for aztec (use -4 to 32, 0 for auto)", v)
+		}
+		layers = n
+	}
+	bc, err := aztec.Encode([]byte(payload), ecc, layers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode aztec: %v", err)
+	}
+	return barcodeBitmap(bc, 1, 1), nil
+}
+
+// encodePDF417 encodes a PDF417 symbol. ecc is the error correction level
+// (0-8); by default it follows the minimum ISO/IEC 15438 recommends for the
+// payload size.
+func encodePDF417(c *gin.Context, payload string) ([][]bool, error) {
+	var level byte
+	switch n := len(payload); {
+	case n <= 40:
+		level = 2
+	case n <= 160:
+		level = 3
+	case n <= 320:
+		level = 4
+	default:
+		level = 5
+	}
+	if v := c.Query("ecc"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 8 {
+			return nil, fmt.Errorf("invalid ecc %q for pdf417 (use 0-8)", v)
+		}
+		level = byte(n)
+	}
+	bc, err := pdf417.Encode(payload, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pdf417: %v", err)
+	}
+	// The encoder draws each row two pixels tall; rows three modules tall
+	// scan more reliably from print.
+	return barcodeBitmap(bc, 2, 3), nil
+}
+
+// barcodeBitmap converts a boombuler barcode into a module bitmap indexed
+// [y][x], sampling every rowStep-th pixel row and repeating each sampled
+// row rowHeight times.
+func barcodeBitmap(bc barcode.Barcode, rowStep, rowHeight int) [][]bool {
+	b := bc.Bounds()
+	bitmap := make([][]bool, 0, b.Dy()/rowStep*rowHeight)
+	for y := b.Min.Y; y < b.Max.Y; y += rowStep {
+		row := make([]bool, b.Dx())
+		for x := range row {
+			row[x] = isDark(bc, b.Min.X+x, y)
+		}
+		for range rowHeight {
+			bitmap = append(bitmap, row)
+		}
+	}
+	return bitmap
+}
+
+// isDark reports whether the pixel at (x, y) is a dark module.
+func isDark(img image.Image, x, y int) bool {
+	return color.Gray16Model.Convert(img.At(x, y)).(color.Gray16).Y < 0x8000
+}
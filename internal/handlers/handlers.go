@@ -1,15 +1,127 @@
 package handlers
 
 import (
+    "cmp"
+    "fmt"
+    "log/slog"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "github.com/cristianadrielbraun/qrcreator.link/internal/analytics"
+    "github.com/cristianadrielbraun/qrcreator.link/internal/jobs"
+    "github.com/cristianadrielbraun/qrcreator.link/internal/logging"
+    "github.com/cristianadrielbraun/qrcreator.link/internal/metrics"
+    "github.com/cristianadrielbraun/qrcreator.link/internal/ratelimit"
+    "github.com/cristianadrielbraun/qrcreator.link/internal/rendercache"
+    "github.com/cristianadrielbraun/qrcreator.link/internal/screen"
+    "github.com/cristianadrielbraun/qrcreator.link/internal/store"
+    "github.com/cristianadrielbraun/qrcreator.link/internal/token"
     "github.com/gin-gonic/gin"
 )
 
-// Handler is a placeholder for dependencies for HTTP handlers.
-// It currently does not hold state, but exists to keep methods organized.
-type Handler struct{}
+// Handler holds the dependencies of the HTTP handlers.
+type Handler struct {
+    jobs     *jobs.Manager
+    store    *store.Store
+    tokens   *token.Signer
+    geo      *analytics.GeoDB
+    clock    func() time.Time
+    screener screen.URLScreener
+    renders  *rendercache.Cache
+
+    limiters    map[string]*ratelimit.Limiter
+    keyLimiters map[string]*ratelimit.Limiter
+    renderSlots ratelimit.Semaphore
+    adminToken  string
+    metrics     *renderMetrics
+    draining    atomic.Bool
+    renderCheck cachedCheck
+    spec        func() map[string]any
+
+    downloadSize   int
+    paddingPercent int
+    imageMaxAge    time.Duration
+    maxURLLength   int
+    uploadsDir     string
+    // renderSettings are the settings that change rendered images, part
+    // of their cache keys and ETags
+    renderSettings []byte
+
+    passcodeVisitors *ratelimit.Limiter
+    passcodeLinks    *ratelimit.Limiter
+}
+
+// Options configures the dependencies of a Handler.
+type Options struct {
+    // Jobs runs asynchronous renders; nil disables them.
+    Jobs *jobs.Manager
+    // Store keeps presets and other records.
+    Store *store.Store
+    // Tokens signs and verifies design tokens.
+    Tokens *token.Signer
+    // Geo looks up the country of scans; nil counts them as unknown.
+    Geo *analytics.GeoDB
+    // Clock returns the current time for redirect rules; nil uses time.Now.
+    Clock func() time.Time
+    // Screener checks URLs before they are encoded; nil lets all through.
+    Screener screen.URLScreener
+    // Renders caches rendered images; nil renders every request.
+    Renders *rendercache.Cache
+    // Limits are the rate limits by name, see DefaultLimits; a missing or
+    // zero policy limits nothing.
+    Limits map[string]ratelimit.Policy
+    // KeyLimits are the rate limits of each API key, see DefaultKeyLimits.
+    KeyLimits map[string]ratelimit.Policy
+    // RenderConcurrency bounds the images rendered at once; 0 is no bound.
+    RenderConcurrency int
+    // AdminToken guards the management of API keys; empty disables it.
+    AdminToken string
+    // Metrics receives the render and error metrics; nil records none.
+    Metrics *metrics.Registry
+    // DownloadSize is the minimum edge in pixels of download-size images;
+    // 0 is 2000.
+    DownloadSize int
+    // PaddingPercent is the padding around QR codes in percent of their
+    // edge. It is used as it is, so 0 means no padding.
+    PaddingPercent int
+    // ImageMaxAge is the Cache-Control max-age of images; 0 is an hour.
+    ImageMaxAge time.Duration
+    // MaxURLLength caps the URLs that are encoded; 0 is 4096.
+    MaxURLLength int
+    // UploadsDir holds the logo PNGs; empty is "uploads".
+    UploadsDir string
+}
 
 // New returns a new Handler instance.
-func New() *Handler { return &Handler{} }
+func New(opts Options) *Handler {
+    h := &Handler{jobs: opts.Jobs, store: opts.Store, tokens: opts.Tokens, geo: opts.Geo, clock: opts.Clock, screener: opts.Screener, renders: opts.Renders, adminToken: opts.AdminToken}
+    if h.clock == nil {
+        h.clock = time.Now
+    }
+    h.downloadSize = cmp.Or(opts.DownloadSize, 2000)
+    h.paddingPercent = opts.PaddingPercent
+    h.imageMaxAge = cmp.Or(opts.ImageMaxAge, time.Hour)
+    h.maxURLLength = cmp.Or(opts.MaxURLLength, 4096)
+    h.uploadsDir = cmp.Or(opts.UploadsDir, "uploads")
+    h.renderSettings = fmt.Appendf(nil, "downloadSize=%d paddingPercent=%d", h.downloadSize, h.paddingPercent)
+    h.spec = sync.OnceValue(h.v1Spec)
+    h.limiters = newLimiters(opts.Limits)
+    h.keyLimiters = newLimiters(opts.KeyLimits)
+    h.renderSlots = ratelimit.NewSemaphore(opts.RenderConcurrency)
+    h.metrics = &renderMetrics{}
+    if opts.Metrics != nil {
+        h.registerMetrics(opts.Metrics)
+    }
+    h.passcodeVisitors = ratelimit.New(1, passcodeVisitorPeriod, passcodeVisitorBurst)
+    h.passcodeLinks = ratelimit.New(1, passcodeLinkPeriod, passcodeLinkBurst)
+    return h
+}
+
+// logger returns the logger of the request, which carries its ID.
+func logger(c *gin.Context) *slog.Logger {
+    return logging.FromContext(c.Request.Context())
+}
 
 // SitemapXML serves a minimal sitemap for the site.
 // Update the URLs if you add more pages.
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/qr"
+	"github.com/gin-gonic/gin"
+)
+
+// renderCheckTTL is how long the result of the render check is reused, so
+// frequent probes do not render on every call.
+const renderCheckTTL = 5 * time.Second
+
+// Drain makes the readiness check fail from now on, so the orchestrator
+// stops sending requests while the server shuts down.
+func (h *Handler) Drain() {
+	h.draining.Store(true)
+}
+
+// HealthzHandler answers liveness probes: the process is up and serving
+func (h *Handler) HealthzHandler(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ReadyzHandler answers readiness probes: the temporary and uploads
+// directories are writable and a tiny QR code renders. It answers 503
+// with the failed checks, and while the server shuts down
+func (h *Handler) ReadyzHandler(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	if h.draining.Load() {
+		failReason(c, reasonNotReady)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting down"})
+		return
+	}
+	checks := map[string]string{
+		"temp_dir":    checkWritable(os.TempDir()),
+		"uploads_dir": checkWritable(h.uploadsDir),
+		"render":      h.renderCheck.get(h.checkRender),
+	}
+	status, code := "ok", http.StatusOK
+	for name, result := range checks {
+		if result != "ok" {
+			logger(c).Warn("readiness check failed", "check", name, "err", result)
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+	}
+	if code != http.StatusOK {
+		failReason(c, reasonNotReady)
+	}
+	c.JSON(code, gin.H{"status": status, "checks": checks})
+}
+
+// checkWritable creates and removes a file in dir. It returns "ok" or the
+// error.
+func checkWritable(dir string) string {
+	f, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return err.Error()
+	}
+	f.Close()
+	if err := os.Remove(f.Name()); err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
+
+// checkRender encodes a fixed payload and draws it as a PNG in memory,
+// through the rasterizer that PNG and JPEG images use. It does not wait
+// for a render slot, so a busy server stays ready, and records no
+// metrics. It returns "ok" or the error.
+func (h *Handler) checkRender() string {
+	code, err := qr.Encode("readyz", qr.Options{Level: qr.ECLevelL})
+	if err != nil {
+		return err.Error()
+	}
+	st := h.parseQRStyle(valuesQuery(url.Values{"previewSize": {"64"}}))
+	img := rasterizeMatrix(code.Bitmap(), rasterOptions{
+		moduleSize: 2,
+		fgColor:    st.fgColor,
+		bgColor:    st.bgColor,
+		gradient:   st.gradient,
+		shape:      st.qrShape,
+	})
+	if err := png.Encode(io.Discard, img); err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
+
+// cachedCheck keeps the result of a readiness check for renderCheckTTL.
+type cachedCheck struct {
+	mu     sync.Mutex
+	at     time.Time
+	result string
+}
+
+// get returns the kept result, or runs check when it is older than
+// renderCheckTTL.
+func (cc *cachedCheck) get(check func() string) string {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.result == "" || time.Since(cc.at) >= renderCheckTTL {
+		cc.result, cc.at = check(), time.Now()
+	}
+	return cc.result
+}
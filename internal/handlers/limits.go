@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/logging"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/ratelimit"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// Rate limit policies, named after the routes they apply to.
+const (
+	// LimitRender covers the image endpoints, in render cost units.
+	LimitRender = "render"
+	// LimitBatch covers batches and sheets, one unit per row or label.
+	LimitBatch = "batch"
+	// LimitAPI covers the other API endpoints, one unit per request.
+	LimitAPI = "api"
+)
+
+// DefaultLimits are the rate limits of each client address.
+var DefaultLimits = map[string]ratelimit.Policy{
+	LimitRender: {N: 300, Period: time.Minute, Burst: 120},
+	LimitBatch:  {N: 2000, Period: time.Hour, Burst: 1000},
+	LimitAPI:    {N: 300, Period: time.Minute, Burst: 100},
+}
+
+const (
+	// renderQueueWait is how long a request waits for a render slot
+	// before it is turned away.
+	renderQueueWait = 10 * time.Second
+	// maxPreviewSize bounds the previewSize parameter, in pixels.
+	maxPreviewSize = 4096
+	// maxBarcodePixels bounds the area of PNG and JPG barcodes, the same
+	// as the largest QR preview.
+	maxBarcodePixels = maxPreviewSize * maxPreviewSize
+)
+
+// DefaultKeyLimits are the rate limits of each API key, which replace
+// those of the client address for requests that carry one.
+var DefaultKeyLimits = map[string]ratelimit.Policy{
+	LimitRender: {N: 3000, Period: time.Minute, Burst: 600},
+	LimitBatch:  {N: 20000, Period: time.Hour, Burst: 5000},
+	LimitAPI:    {N: 3000, Period: time.Minute, Burst: 500},
+}
+
+// newLimiters returns the limiters of the policies that limit something.
+func newLimiters(policies map[string]ratelimit.Policy) map[string]*ratelimit.Limiter {
+	limiters := map[string]*ratelimit.Limiter{}
+	for name, p := range policies {
+		if l := p.Limiter(); l != nil {
+			limiters[name] = l
+		}
+	}
+	return limiters
+}
+
+// limiterKey is the context key of the rate limit state of the current
+// route.
+const limiterKey = "qrcreator.limiter"
+
+// rateState is what RateLimit leaves in the context for charge.
+type rateState struct {
+	name    string
+	limiter *ratelimit.Limiter // nil limits nothing
+	bucket  string             // client address, or key:<id> for API keys
+	key     *store.APIKey      // nil for anonymous requests
+	counted bool               // whether the request was counted in the key's usage
+}
+
+// RateLimit returns middleware that limits each client address, or each
+// API key when the request carries one, by the named policy. Every
+// request costs one unit; handlers charge more once they know what the
+// request costs. Requests with an unknown API key are answered 401.
+func (h *Handler) RateLimit(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, ok := h.requestAPIKey(c)
+		if !ok {
+			return
+		}
+		st := &rateState{name: name, limiter: h.limiters[name], bucket: c.ClientIP()}
+		if key != nil {
+			st.limiter, st.bucket, st.key = h.keyLimiters[name], "key:"+key.ID, key
+		}
+		if st.limiter == nil && st.key == nil {
+			return
+		}
+		c.Set(limiterKey, st)
+		if !h.charge(c, 1) {
+			c.Abort()
+		}
+	}
+}
+
+// charge takes cost units from the bucket of the current route and
+// counts them in the usage of the request's API key. When there are not
+// enough, or the key has used up its daily quota, it answers 429 with
+// Retry-After and returns false. A cost above the burst is capped at it,
+// so a costly request empties the bucket instead of never passing.
+func (h *Handler) charge(c *gin.Context, cost float64) bool {
+	v, ok := c.Get(limiterKey)
+	if !ok {
+		return true
+	}
+	st := v.(*rateState)
+	if st.key != nil && !h.withinQuota(c, st.key) {
+		return false
+	}
+	if st.limiter != nil {
+		allowed, wait := st.limiter.Allow(st.bucket, min(cost, st.limiter.Burst()))
+		if !allowed {
+			logger(c).Info("rate limited", "limit", st.name, "client", logging.Payload(st.bucket), "cost", cost)
+			failReason(c, reasonRateLimited)
+			tooManyRequests(c, wait, "too many requests, try again later")
+			return false
+		}
+	}
+	if st.key != nil {
+		requests := 0
+		if !st.counted {
+			requests, st.counted = 1, true
+		}
+		if err := h.store.RecordUsage(st.key.ID, h.clock(), st.name, requests, cost); err != nil {
+			logger(c).Warn("failed to record API key usage", "key", st.key.ID, "err", err)
+		}
+	}
+	return true
+}
+
+// withinQuota reports whether key has units of its daily quota left. When
+// it has not it answers 429 with Retry-After set to the next midnight
+// (UTC), when the quota resets.
+func (h *Handler) withinQuota(c *gin.Context, key *store.APIKey) bool {
+	if key.DailyQuota == 0 {
+		return true
+	}
+	now := h.clock().UTC()
+	today := now.Truncate(24 * time.Hour)
+	days, err := h.store.Usage(key.ID, today)
+	if err != nil {
+		logger(c).Warn("failed to read API key usage", "key", key.ID, "err", err)
+		return true
+	}
+	if len(days) == 0 || days[0].Units < float64(key.DailyQuota) {
+		return true
+	}
+	logger(c).Info("quota used up", "key", key.ID, "quota", key.DailyQuota)
+	failReason(c, reasonQuota)
+	tooManyRequests(c, today.AddDate(0, 0, 1).Sub(now), fmt.Sprintf("daily quota of %d units used up, it resets at 00:00 UTC", key.DailyQuota))
+	return false
+}
+
+// tooManyRequests answers 429 with msg and the seconds to wait in
+// Retry-After.
+func tooManyRequests(c *gin.Context, wait time.Duration, msg string) {
+	c.Header("Retry-After", strconv.Itoa(max(1, int(math.Ceil(wait.Seconds())))))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": msg})
+}
+
+// renderCost estimates what rendering an image costs, in units of a
+// small preview: raster images by their pixels, with frames redrawing
+// the canvas and JPEG encoding it once more. SVG is built as text.
+func (h *Handler) renderCost(st qrStyle, format string) float64 {
+	if format == "svg" {
+		return 1
+	}
+	edge := 400
+	if st.size == "download" {
+		edge = h.downloadSize
+	} else if st.previewSize > 0 {
+		edge = st.previewSize
+	}
+	cost := rasterCost(edge, edge, format)
+	if st.frame != "none" {
+		cost += float64(edge*edge) / 1e6 / 2
+	}
+	return cost
+}
+
+// rasterCost estimates what drawing a width by height image costs, in the
+// units of renderCost.
+func rasterCost(width, height int, format string) float64 {
+	megapixels := float64(width*height) / 1e6
+	cost := 1 + megapixels
+	if format == "jpg" {
+		cost += megapixels / 2
+	}
+	return cost
+}
+
+// startRender waits for a render slot. Interactive requests give up after
+// renderQueueWait with 429 and return false; the caller releases the slot
+// with h.renderSlots.Release.
+func (h *Handler) startRender(c *gin.Context) bool {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), renderQueueWait)
+	defer cancel()
+	if err := h.renderSlots.Acquire(ctx); err != nil {
+		logger(c).Info("no render slot")
+		failReason(c, reasonNoRenderSlot)
+		tooManyRequests(c, time.Second, "too many requests, try again later")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/logging"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Reasons of failed requests, for the errors metric. Responses without
+// one are counted by their status.
+const (
+	reasonBlocked       = "blocked"
+	reasonRateLimited   = "rate_limited"
+	reasonQuota         = "quota"
+	reasonNoRenderSlot  = "no_render_slot"
+	reasonEncode        = "encode"
+	reasonRender        = "render"
+	reasonInvalidAPIKey = "invalid_api_key"
+	reasonScope         = "scope"
+	reasonNotReady      = "not_ready"
+)
+
+// errorReasonKey is the context key of the reason a request failed.
+const errorReasonKey = "qrcreator.errorReason"
+
+// Render stages timed by the stage histogram.
+const (
+	stageEncode    = "encode"     // payload to modules
+	stageDraw      = "draw"       // modules to pixels
+	stageScale     = "scale"      // resizing to the requested size
+	stagePad       = "pad"        // quiet zone
+	stageFrame     = "frame"      // decorative frame
+	stageEncodeOut = "encode_out" // the image in its output format
+)
+
+// renderMetrics are the metrics of the handlers. Without a registry the
+// metrics are nil and record nothing.
+type renderMetrics struct {
+	duration *metrics.HistogramVec
+	stages   *metrics.HistogramVec
+	bytes    *metrics.HistogramVec
+	errors   *metrics.CounterVec
+	inFlight atomic.Int64
+}
+
+// registerMetrics adds the metrics of the handlers to r.
+func (h *Handler) registerMetrics(r *metrics.Registry) {
+	m := h.metrics
+	m.duration = r.NewHistogram("qrcreator_render_duration_seconds",
+		"Time to render an image that was not cached, after waiting for a render slot.",
+		metrics.ExponentialBuckets(0.005, 2, 12), "format", "size", "shape", "frame")
+	m.stages = r.NewHistogram("qrcreator_render_stage_duration_seconds",
+		"Time spent in each stage of a render: encode, draw, scale, pad, frame and encode_out.",
+		metrics.ExponentialBuckets(0.0005, 2.5, 12), "stage")
+	m.bytes = r.NewHistogram("qrcreator_render_output_bytes",
+		"Size of rendered images.",
+		metrics.ExponentialBuckets(1024, 4, 9), "format")
+	m.errors = r.NewCounter("qrcreator_errors_total",
+		"Requests answered with an error, by reason.", "reason")
+	r.NewGaugeFunc("qrcreator_renders_in_flight",
+		"Images being rendered now.",
+		func() float64 { return float64(m.inFlight.Load()) })
+	r.NewGaugeFunc("qrcreator_render_slots_in_use",
+		"Render slots taken, including batch and sheet renders.",
+		func() float64 { return float64(h.renderSlots.InUse()) })
+
+	r.NewCounterFunc("qrcreator_render_cache_hits_total",
+		"Images served from the render cache.",
+		func() float64 { hits, _, _, _ := h.renders.Stats(); return float64(hits) })
+	r.NewCounterFunc("qrcreator_render_cache_misses_total",
+		"Images looked up in the render cache and not found.",
+		func() float64 { _, misses, _, _ := h.renders.Stats(); return float64(misses) })
+	r.NewGaugeFunc("qrcreator_render_cache_hit_ratio",
+		"Share of render cache lookups that hit since the start.",
+		func() float64 {
+			hits, misses, _, _ := h.renders.Stats()
+			if hits+misses == 0 {
+				return 0
+			}
+			return float64(hits) / float64(hits+misses)
+		})
+	r.NewGaugeFunc("qrcreator_render_cache_memory_bytes",
+		"Bytes held by the memory tier of the render cache.",
+		func() float64 { _, _, mem, _ := h.renders.Stats(); return float64(mem) })
+	r.NewGaugeFunc("qrcreator_render_cache_disk_bytes",
+		"Bytes held by the disk tier of the render cache.",
+		func() float64 { _, _, _, disk := h.renders.Stats(); return float64(disk) })
+}
+
+// CountErrors returns middleware that counts the requests answered with
+// a status of 400 or more, by the reason the handler gave or else by the
+// status.
+func (h *Handler) CountErrors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		status := c.Writer.Status()
+		if status < 400 {
+			return
+		}
+		reason := c.GetString(errorReasonKey)
+		if reason == "" {
+			reason = statusReason(status)
+		}
+		h.metrics.errors.Inc(reason)
+	}
+}
+
+// statusReason names the reason of an error status.
+func statusReason(status int) string {
+	switch {
+	case status == http.StatusBadRequest:
+		return "invalid"
+	case status == http.StatusUnauthorized:
+		return "unauthorized"
+	case status == http.StatusForbidden:
+		return "forbidden"
+	case status == http.StatusNotFound:
+		return "not_found"
+	case status == http.StatusRequestEntityTooLarge:
+		return "too_large"
+	case status == http.StatusTooManyRequests:
+		return reasonRateLimited
+	case status >= 500:
+		return "internal"
+	}
+	return "client_error"
+}
+
+// failReason records why the request failed, for the errors metric.
+func failReason(c *gin.Context, reason string) {
+	c.Set(errorReasonKey, reason)
+}
+
+// stage starts timing a stage of a render, both for the stage histogram
+// and the request's log line, and returns the function that ends it.
+func (h *Handler) stage(ctx context.Context, name string) func() {
+	logged := logging.Stage(ctx, name)
+	start := time.Now()
+	return func() {
+		logged()
+		h.metrics.stages.Observe(time.Since(start).Seconds(), name)
+	}
+}
+
+// observeRender records a render of an image in format with style st that
+// took d and produced n bytes.
+func (h *Handler) observeRender(st qrStyle, format string, d time.Duration, n int) {
+	size := "preview"
+	if st.size == "download" {
+		size = "download"
+	}
+	h.metrics.duration.Observe(d.Seconds(), format, size, shapeLabel(st.qrShape), frameLabel(st.frame))
+	h.metrics.bytes.Observe(float64(n), format)
+}
+
+// shapeLabel bounds the qrShape parameter to the shapes that exist, as
+// shapeFor does.
+func shapeLabel(shape string) string {
+	switch shape {
+	case "circle", "liquid", "chain", "hstripe", "vstripe":
+		return shape
+	}
+	return "rectangle"
+}
+
+// frameLabel reduces a frame to none, rounded or square.
+func frameLabel(frame string) string {
+	switch {
+	case frame == "none":
+		return "none"
+	case strings.HasPrefix(frame, "rounded-"):
+		return "rounded"
+	}
+	return "square"
+}
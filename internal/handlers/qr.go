@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
@@ -9,6 +15,8 @@ import (
 	"image/jpeg"
 	"image/png"
 	"io"
+	"io/fs"
+	"log/slog"
 	"math"
 	"net/http"
 	"net/url"
@@ -16,17 +24,23 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/logging"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/qr"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/rendercache"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/screen"
 	"github.com/gin-gonic/gin"
-	"github.com/yeqown/go-qrcode/v2"
-	"github.com/yeqown/go-qrcode/writer/standard"
-	"github.com/yeqown/go-qrcode/writer/standard/shapes"
+	"golang.org/x/net/idna"
 )
 
 // normalizeHTTPURL validates and normalizes a URL string for QR generation.
 // It ensures an http/https scheme, a non-empty hostname, and returns a cleaned absolute URL.
-func normalizeHTTPURL(s string) (string, error) {
+// The URL is returned as an IRI, so non-ASCII text is encoded as typed.
+func (h *Handler) normalizeHTTPURL(s string) (string, error) {
 	v := strings.TrimSpace(s)
 	if v == "" {
 		return "", fmt.Errorf("URL parameter is required")
@@ -45,11 +59,96 @@ func normalizeHTTPURL(s string) (string, error) {
 	if u.Host == "" {
 		return "", fmt.Errorf("URL must include a valid host")
 	}
-	// Optional: cap length to avoid abuse
-	if len(v) > 4096 {
+	// Cap the length of what is encoded, to avoid abuse
+	iri := iriString(u.String())
+	if len(iri) > h.maxURLLength {
 		return "", fmt.Errorf("URL is too long")
 	}
-	return u.String(), nil
+	return iri, nil
+}
+
+// iriString decodes the percent-escapes of non-ASCII text in a URL, so
+// "b%C3%BCcher.de/stra%C3%9Fe" becomes "bücher.de/straße" and is
+// encoded as UTF-8 rather than as escapes three times as long. Escapes of
+// ASCII characters, like %2F or %20, keep their meaning and are kept.
+func iriString(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		// A run of escaped bytes of 0x80 and above
+		var run []byte
+		j := i
+		for j+2 < len(s) && s[j] == '%' {
+			v, err := strconv.ParseUint(s[j+1:j+3], 16, 8)
+			if err != nil || v < 0x80 {
+				break
+			}
+			run = append(run, byte(v))
+			j += 3
+		}
+		switch {
+		case len(run) > 0 && utf8.Valid(run):
+			b.Write(run)
+		case len(run) > 0:
+			b.WriteString(s[i:j])
+		default:
+			b.WriteByte(s[i])
+			j = i + 1
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// uriString returns an IRI as an ASCII URI, with the host in punycode,
+// for headers like Location.
+func uriString(iri string) string {
+	u, err := url.Parse(iri)
+	if err != nil {
+		return iri
+	}
+	if host, err := idna.Lookup.ToASCII(u.Hostname()); err == nil && host != u.Hostname() {
+		if port := u.Port(); port != "" {
+			host += ":" + port
+		}
+		u.Host = host
+	}
+	// String escapes the path and fragment; a raw query is left alone
+	u.RawQuery = escapeNonASCII(u.RawQuery)
+	u.RawFragment = ""
+	return u.String()
+}
+
+// escapeNonASCII percent-escapes the bytes of s of 0x80 and above.
+func escapeNonASCII(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= 0x80 {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// normalizeTaggedURL normalizes a URL and adds the utm_* parameters of
+// the request to it.
+func (h *Handler) normalizeTaggedURL(raw string, q queryReader) (string, error) {
+	u, err := h.normalizeHTTPURL(raw)
+	if err != nil {
+		return "", err
+	}
+	if cmp := design.CampaignFromQuery(q.Query); cmp != nil {
+		if u, err = cmp.Tag(u); err == nil {
+			if u = iriString(u); len(u) > h.maxURLLength {
+				err = fmt.Errorf("URL is too long")
+			}
+		}
+	}
+	return u, err
 }
 
 // min4 returns the minimum of four integers.
@@ -67,6 +166,48 @@ func min4(a, b, c, d int) int {
 	return m
 }
 
+// screenURL runs the URL screener over a normalized URL. It returns the
+// findings to warn about, or an error when the URL is blocked.
+func (h *Handler) screenURL(u string) ([]screen.Finding, error) {
+	if h.screener == nil {
+		return nil, nil
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+	findings := h.screener.Screen(parsed)
+	if f := screen.Blocked(findings); f != nil {
+		slog.Info("URL blocked", "url", logging.Payload(u), "check", f.Check, "reason", logging.Payload(f.Reason))
+		return nil, fmt.Errorf("%w: %s", errURLBlocked, f.Reason)
+	}
+	return findings, nil
+}
+
+// errURLBlocked is wrapped by the errors of URLs the screener blocks.
+var errURLBlocked = errors.New("URL blocked")
+
+// checkURL screens a normalized URL for a request: the findings to warn
+// about go into X-URL-Warning headers and a blocked URL is an error.
+func (h *Handler) checkURL(c *gin.Context, u string) (string, error) {
+	findings, err := h.screenURL(u)
+	if errors.Is(err, errURLBlocked) {
+		failReason(c, reasonBlocked)
+	}
+	if err != nil {
+		return "", err
+	}
+	warnURL(c, findings)
+	return u, nil
+}
+
+// warnURL adds an X-URL-Warning header for each screening finding.
+func warnURL(c *gin.Context, findings []screen.Finding) {
+	for _, f := range findings {
+		c.Writer.Header().Add("X-URL-Warning", f.String())
+	}
+}
+
 // QRCodeHandler generates QR codes for URLs with advanced customization options
 func (h *Handler) QRCodeHandler(c *gin.Context) {
 	rawURL := strings.TrimSpace(c.Query("url"))
@@ -75,14 +216,24 @@ func (h *Handler) QRCodeHandler(c *gin.Context) {
 		return
 	}
 
-	normalizedURL, err := normalizeHTTPURL(rawURL)
+	normalizedURL, err := h.normalizeTaggedURL(rawURL, c)
+	if err == nil {
+		normalizedURL, err = h.checkURL(c, normalizedURL)
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// A saved preset supplies the defaults of every other parameter
+	q, err := h.presetQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("preset %q: %v", c.Query("preset"), err)})
+		return
+	}
+
 	// Parse format parameter (default to PNG)
-	format := strings.ToLower(c.DefaultQuery("format", "png"))
+	format := strings.ToLower(q.DefaultQuery("format", "png"))
 	if format == "jpeg" {
 		format = "jpg"
 	}
@@ -90,224 +241,455 @@ func (h *Handler) QRCodeHandler(c *gin.Context) {
 		format = "png"
 	}
 
-	// Parse customization parameters
-	colorMode := c.DefaultQuery("colorMode", "flat")
-	bgColor := parseColorParam(c.Query("bg"), color.RGBA{255, 255, 255, 255}) // Default white
+	st := h.parseQRStyle(q)
+
+	logger(c).Debug("QR request", "url", logging.Payload(normalizedURL), "format", format, "size", st.size, "colorMode", st.colorMode, "qrShape", st.qrShape)
+
+	// Parse encoding parameters (segment mode, ECI, version range and mask)
+	opts, err := parseQROptions(q)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	st.applySymbology(opts.Symbology)
+
+	// Create QR code instance with Q error correction level unless overridden
+	done := h.stage(c.Request.Context(), stageEncode)
+	qrc, err := qr.Encode(normalizedURL, opts)
+	done()
+	if err != nil {
+		encodeError(c, err)
+		return
+	}
+	c.Header("X-QR-Version", qrc.VersionName())
+	c.Header("X-QR-Mask", strconv.Itoa(qrc.Mask))
+	c.Header("X-QR-Segments", qrc.SegmentSummary())
+
+	if format == "svg" {
+		// Generate SVG format
+		h.generateSVGQR(c, qrc.Bitmap(), st)
+	} else {
+		// Generate PNG format (default)
+		// Add debug header for quick inspection from devtools
+		c.Header("X-QR-Debug", fmt.Sprintf("format=%s;size=%s;shape=%s;colorMode=%s", format, st.size, st.qrShape, st.colorMode))
+		h.generatePNGQR(c, qrc.Bitmap(), st, format)
+	}
+}
+
+// qrStyle holds the visual options applied when rendering a symbol.
+type qrStyle struct {
+	colorMode   string
+	useGradient bool
+	gradient    *linearGradient
+
+	fgColor, bgColor, borderColor              color.RGBA
+	gradientStart, gradientMiddle, gradientEnd color.RGBA
+
+	border            int // padding, percent of the QR size
+	quietZone         int // padding in modules; replaces border when set
+	frame             string
+	frameWidthPercent int
+
+	size        string // "preview" or "download"
+	previewSize int    // exact preview edge in pixels, 0 for the natural size
+	qrShape     string
+	centerLogo  string
+	logoFile    string
+}
+
+// queryReader is the part of *gin.Context used by the parameter parsers,
+// so a v1 design document can go through the same code as a query string.
+type queryReader interface {
+	Query(key string) string
+	DefaultQuery(key, defaultValue string) string
+}
+
+// valuesQuery adapts url.Values to queryReader.
+type valuesQuery url.Values
+
+func (q valuesQuery) Query(key string) string { return url.Values(q).Get(key) }
+
+func (q valuesQuery) DefaultQuery(key, defaultValue string) string {
+	if v, ok := q[key]; ok && len(v) > 0 {
+		return v[0]
+	}
+	return defaultValue
+}
+
+// parseQRStyle reads the styling parameters shared by the image endpoints.
+func (h *Handler) parseQRStyle(c queryReader) qrStyle {
+	st := qrStyle{
+		colorMode: c.DefaultQuery("colorMode", "flat"),
+		bgColor:   parseColorParam(c.Query("bg"), color.RGBA{255, 255, 255, 255}), // Default white
+	}
+
 	cornerStyle := c.DefaultQuery("cornerStyle", "none")
 	borderPattern := c.DefaultQuery("borderPattern", "simple")
 	borderColorParam := c.Query("borderColor")
 	// Combine corner style and border pattern
-	var frame string
 	switch cornerStyle {
 	case "none":
-		frame = "none"
+		st.frame = "none"
 	case "rounded":
-		frame = "rounded-" + borderPattern
+		st.frame = "rounded-" + borderPattern
 	default:
-		frame = borderPattern
+		st.frame = borderPattern
 	}
 
-	// Fixed 7% padding
-	border := 7
+	// Padding in percent of the edge
+	st.border = h.paddingPercent
 
 	// Base frame width percent
-	frameWidthPercent := 4
+	st.frameWidthPercent = 4
 	// Make rounded frames start thicker before carving so final result
 	// remains visually strong after the rounded inner cut.
-	if strings.HasPrefix(frame, "rounded-") {
-		frameWidthPercent = 6 // effective ~4% after inner carve
+	if strings.HasPrefix(st.frame, "rounded-") {
+		st.frameWidthPercent = 6 // effective ~4% after inner carve
 	}
 
 	// Parse size parameter for different resolutions
-	size := c.DefaultQuery("size", "preview") // "preview" or "download"
-
-	// Basic request debug info
-	fmt.Printf("[QR] request start: url=%q format=%s size=%s colorMode=%s qrShape=%s branding=%s\n",
-		normalizedURL, format, size, c.DefaultQuery("colorMode", "flat"), c.DefaultQuery("qrShape", "rectangle"), c.DefaultQuery("branding", "default"))
+	st.size = c.DefaultQuery("size", "preview") // "preview" or "download"
+	if ps, err := strconv.Atoi(c.Query("previewSize")); err == nil && ps > 0 {
+		st.previewSize = min(ps, maxPreviewSize)
+	}
 
 	// Handle color mode
-	var useGradient bool
-	var gradient *standard.LinearGradient
-	var fgColor color.RGBA
-	var gradientStartColor color.RGBA
-
-	if colorMode == "gradient" {
+	if st.colorMode == "gradient" {
 		// Parse gradient colors
-		startColor := parseColorParam(c.Query("gradientStart"), color.RGBA{0, 0, 0, 255})
-		middleColor := parseColorParam(c.Query("gradientMiddle"), color.RGBA{128, 128, 128, 255})
-		endColor := parseColorParam(c.Query("gradientEnd"), color.RGBA{255, 0, 0, 255})
-
-		// Store start color for logo branding
-		gradientStartColor = startColor
+		st.gradientStart = parseColorParam(c.Query("gradientStart"), color.RGBA{0, 0, 0, 255})
+		st.gradientMiddle = parseColorParam(c.Query("gradientMiddle"), color.RGBA{128, 128, 128, 255})
+		st.gradientEnd = parseColorParam(c.Query("gradientEnd"), color.RGBA{255, 0, 0, 255})
 
 		// Create gradient with 45-degree angle
-		gradient = standard.NewGradient(45, []standard.ColorStop{
-			{T: 0, Color: startColor},
-			{T: 0.5, Color: middleColor},
-			{T: 1, Color: endColor},
-		}...)
-		useGradient = true
+		st.gradient = &linearGradient{angle: 45, start: st.gradientStart, middle: st.gradientMiddle, end: st.gradientEnd}
+		st.useGradient = true
 	} else {
 		// Flat color mode
-		fgColor = parseColorParam(c.Query("fg"), color.RGBA{0, 0, 0, 255})
-		useGradient = false
+		st.fgColor = parseColorParam(c.Query("fg"), color.RGBA{0, 0, 0, 255})
 	}
 
 	// Parse border color - use foreground/gradient start as default
-	var borderColor color.RGBA
 	if borderColorParam != "" {
-		borderColor = parseColorParam(borderColorParam, color.RGBA{0, 0, 0, 255})
+		st.borderColor = parseColorParam(borderColorParam, color.RGBA{0, 0, 0, 255})
+	} else if st.useGradient {
+		st.borderColor = st.gradientStart
 	} else {
-		// Default to foreground color or gradient start color
-		if useGradient {
-			borderColor = gradientStartColor
-		} else {
-			borderColor = fgColor
-		}
+		st.borderColor = st.fgColor
 	}
 
-	// Parse QR shape parameter
-	qrShape := c.DefaultQuery("qrShape", "rectangle")
-
-	// Parse branding parameters
-	branding := c.DefaultQuery("branding", "default")
-	customDomain := c.Query("customDomain")
-	centerLogo := c.DefaultQuery("centerLogo", "false")
-	logoFile := c.Query("logoFile")
+	// Parse QR shape and branding parameters
+	st.qrShape = c.DefaultQuery("qrShape", "rectangle")
+	st.centerLogo = c.DefaultQuery("centerLogo", "false")
+	st.logoFile = c.Query("logoFile")
+	return st
+}
 
-	// Create QR code instance with Q error correction level
-	qrc, err := qrcode.NewWith(normalizedURL, qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionQuart))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create QR code"})
+// applySymbology adjusts the style to the rules of the symbology: Micro QR
+// and rMQR need only a 2-module quiet zone and have too little error
+// correction to give up modules to a logo.
+func (st *qrStyle) applySymbology(sym qr.Symbology) {
+	if sym == qr.SymbologyQR {
 		return
 	}
+	st.quietZone = 2
+	st.centerLogo = "false"
+}
 
-	if format == "svg" {
-		// Generate SVG format
-		// Pass gradient colors if available
-		var startColor, middleColor, endColor color.RGBA
-		if useGradient {
-			startColor = parseColorParam(c.Query("gradientStart"), color.RGBA{0, 0, 0, 255})
-			middleColor = parseColorParam(c.Query("gradientMiddle"), color.RGBA{128, 128, 128, 255})
-			endColor = parseColorParam(c.Query("gradientEnd"), color.RGBA{255, 0, 0, 255})
+// generatePNGQR generates a PNG QR code
+func (h *Handler) generatePNGQR(c *gin.Context, bitmap [][]bool, st qrStyle, outputFormat string) {
+	contentType := "image/png"
+	if outputFormat == "jpg" {
+		contentType = "image/jpeg"
+	}
+	h.sendImage(c, h.renderKey(bitmap, st, outputFormat), contentType, h.renderCost(st, outputFormat), st, outputFormat, func() ([]byte, error) {
+		tmpFile, err := h.renderPNGFile(c.Request.Context(), bitmap, st)
+		if err != nil {
+			return nil, err
 		}
-		var _ string = branding
-		var _ string = customDomain
-		h.generateSVGQR(c, qrc, useGradient, fgColor, bgColor, startColor, middleColor, endColor, borderColor, border, frame, frameWidthPercent, size, qrShape, centerLogo)
+		defer os.Remove(tmpFile) // Clean up temp file
+
+		defer h.stage(c.Request.Context(), stageEncodeOut)()
+		data, err := os.ReadFile(tmpFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read QR code file: %v", err)
+		}
+		if outputFormat != "jpg" {
+			return data, nil
+		}
+		// Decode PNG, composite onto opaque background, encode JPEG
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode QR image: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := writeJPEG(&buf, img, st.bgColor); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	})
+	logger(c).Debug("QR sent", "format", outputFormat, "size", st.size, "shape", st.qrShape)
+}
+
+// sendImage answers with an image: 304 when the client already has it,
+// from the render cache when it is there, or rendered by render and then
+// cached. The ETag is the cache key, so it changes with anything that
+// changes the image. Only renders are charged their cost against the
+// rate limit.
+func (h *Handler) sendImage(c *gin.Context, key, contentType string, cost float64, st qrStyle, format string, render func() ([]byte, error)) {
+	if c.Writer.Header().Get("Cache-Control") == "" {
+		c.Header("Cache-Control", h.imageCacheControl())
+	}
+	render = h.limitRender(c, cost, st, format, render)
+	if key == "" {
+		body, err := render()
+		if err == errLimited {
+			return
+		}
+		if err != nil {
+			failReason(c, reasonRender)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, contentType, body)
+		return
+	}
+
+	etag := `"` + key + `"`
+	if etagMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Header("ETag", etag)
+		c.Status(http.StatusNotModified)
+		return
+	}
+	body, ok := h.renders.Get(key)
+	if ok {
+		c.Header("X-Render-Cache", "hit")
 	} else {
-		// Generate PNG format (default)
-		// Pass gradient colors if available
-		var startColor, middleColor, endColor color.RGBA
-		if useGradient {
-			startColor = parseColorParam(c.Query("gradientStart"), color.RGBA{0, 0, 0, 255})
-			middleColor = parseColorParam(c.Query("gradientMiddle"), color.RGBA{128, 128, 128, 255})
-			endColor = parseColorParam(c.Query("gradientEnd"), color.RGBA{255, 0, 0, 255})
+		var err error
+		if body, err = render(); err == errLimited {
+			return
+		} else if err != nil {
+			failReason(c, reasonRender)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
-		// Add debug header for quick inspection from devtools
-		outFmt := format // png or jpg
-		c.Header("X-QR-Debug", fmt.Sprintf("format=%s;size=%s;shape=%s;colorMode=%s", outFmt, size, qrShape, colorMode))
-		h.generatePNGQR(c, qrc, useGradient, gradient, fgColor, bgColor, startColor, middleColor, endColor, borderColor, border, frame, frameWidthPercent, size, qrShape, centerLogo, logoFile, outFmt)
+		h.renders.Put(key, body)
+		c.Header("X-Render-Cache", "miss")
 	}
+	c.Header("ETag", etag)
+	c.Data(http.StatusOK, contentType, body)
 }
 
-// generatePNGQR generates a PNG QR code
-func (h *Handler) generatePNGQR(c *gin.Context, qrc *qrcode.QRCode, useGradient bool, gradient *standard.LinearGradient, fgColor, bgColor, gradientStart, gradientMiddle, gradientEnd, borderColor color.RGBA, border int, frame string, frameWidthPercent int, size, qrShape, centerLogo, logoFile, outputFormat string) {
+// imageCacheControl returns the Cache-Control header of images.
+func (h *Handler) imageCacheControl() string {
+	return "public, max-age=" + strconv.FormatInt(int64(h.imageMaxAge/time.Second), 10)
+}
+
+// errLimited is returned by a render refused by limitRender, which has
+// answered the request already.
+var errLimited = errors.New("rate limited")
+
+// limitRender wraps render so it charges cost, an estimate from h.renderCost
+// or rasterCost, runs in a render slot and is measured as st in format.
+func (h *Handler) limitRender(c *gin.Context, cost float64, st qrStyle, format string, render func() ([]byte, error)) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		// The request itself was charged one unit by the middleware
+		if !h.charge(c, cost-1) {
+			return nil, errLimited
+		}
+		done := logging.Stage(c.Request.Context(), "queue")
+		ok := h.startRender(c)
+		done()
+		if !ok {
+			return nil, errLimited
+		}
+		defer h.renderSlots.Release()
+		defer logging.Stage(c.Request.Context(), "render")()
+		h.metrics.inFlight.Add(1)
+		defer h.metrics.inFlight.Add(-1)
+		start := time.Now()
+		body, err := render()
+		if err == nil {
+			h.observeRender(st, format, time.Since(start), len(body))
+		}
+		return body, err
+	}
+}
+
+// etagMatch reports whether an If-None-Match header lists etag, weak
+// comparison as RFC 9110 asks for.
+func etagMatch(header, etag string) bool {
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// renderKey returns the cache key of an image: its modules, the parsed
+// style, the format, the render settings of the server and, with a logo, a
+// hash of the logo's contents, so replacing the file changes the key. It
+// is "" when the logo cannot be read.
+func (h *Handler) renderKey(bitmap [][]bool, st qrStyle, format string) string {
+	var logo string
+	if st.centerLogo == "true" && format != "svg" {
+		var err error
+		if logo, err = logoDigest(h.logoPath(st)); err != nil {
+			return ""
+		}
+	}
+	st.gradient = nil // made from the gradient colors
+	var modules []byte
+	for _, row := range bitmap {
+		modules = binary.AppendUvarint(modules, uint64(len(row)))
+		var b byte
+		for x, dark := range row {
+			if dark {
+				b |= 1 << (x % 8)
+			}
+			if x%8 == 7 || x == len(row)-1 {
+				modules = append(modules, b)
+				b = 0
+			}
+		}
+	}
+	return rendercache.Key([]byte(format), fmt.Appendf(nil, "%+v", st), modules, []byte(logo), h.renderSettings)
+}
+
+// logoPath returns the file of the centre logo of a style.
+func (h *Handler) logoPath(st qrStyle) string {
+	if st.logoFile != "" {
+		// Use uploaded logo file
+		return filepath.Join(h.uploadsDir, st.logoFile)
+	}
+	// Use default uploaded logo
+	return filepath.Join(h.uploadsDir, "temp_logo.png")
+}
+
+// logoDigests caches the hashes of logo files by path, size and time.
+var logoDigests sync.Map // path -> logoDigestEntry
+
+type logoDigestEntry struct {
+	size int64
+	mod  time.Time
+	sum  string
+}
+
+// logoDigest returns a hash of the contents of a logo file, or "none" when
+// there is no such file, which renders without a logo.
+func logoDigest(path string) (string, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "none", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if v, ok := logoDigests.Load(path); ok {
+		if e := v.(logoDigestEntry); e.size == info.Size() && e.mod.Equal(info.ModTime()) {
+			return e.sum, nil
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	e := logoDigestEntry{info.Size(), info.ModTime(), hex.EncodeToString(sum[:])}
+	logoDigests.Store(path, e)
+	return e.sum, nil
+}
+
+// writeJPEG composites img onto an opaque background and encodes it as JPEG.
+func writeJPEG(w io.Writer, img image.Image, bgColor color.RGBA) error {
+	// Create opaque background using selected bgColor (fallback to white)
+	bg := color.RGBA{bgColor.R, bgColor.G, bgColor.B, 255}
+	if bgColor.A == 0 {
+		bg = color.RGBA{255, 255, 255, 255}
+	}
+	outBounds := img.Bounds()
+	out := image.NewRGBA(outBounds)
+	draw.Draw(out, outBounds, &image.Uniform{C: bg}, image.Point{}, draw.Src)
+	draw.Draw(out, outBounds, img, outBounds.Min, draw.Over)
+
+	if err := jpeg.Encode(w, out, &jpeg.Options{Quality: 92}); err != nil {
+		return fmt.Errorf("Failed to encode JPEG: %v", err)
+	}
+	return nil
+}
+
+// renderPNGFile runs the raster pipeline (modules, logo, scaling, padding
+// and frame) and returns the path of the resulting temporary PNG. The
+// caller removes the file.
+func (h *Handler) renderPNGFile(ctx context.Context, bitmap [][]bool, st qrStyle) (string, error) {
 	// Create unique temporary file for PNG output
 	tmpFile := filepath.Join(os.TempDir(), generateUniqueFilename("qr", ".png"))
 
 	// Set module size based on requested size
 	var moduleSize uint8
-	if size == "download" {
+	if st.size == "download" {
 		// For 2000x2000 target: 2000 / 21 modules ≈ 95 pixels per module
 		// Let's use a large but safe value
 		moduleSize = 120 // Should give us ~2520x2520 for typical QR
 	} else {
 		moduleSize = 16 // Slightly larger preview (336px target)
 	}
+	// Keep wide rMQR symbols to a sane canvas (139 modules at 120px would
+	// be over 16000px across)
+	cols, rows := 0, len(bitmap)
+	if rows > 0 {
+		cols = len(bitmap[0])
+	}
+	longSide := max(cols, rows)
+	if longSide > 0 && int(moduleSize)*longSide > 3000 {
+		moduleSize = uint8(max(1, 3000/longSide))
+	}
 
-	var writerOptions []standard.ImageOption
-	baseOptions := []standard.ImageOption{
-		standard.WithQRWidth(moduleSize),
-		standard.WithBorderWidth(0), // Generate clean QR without borders
+	rasterOpts := rasterOptions{
+		moduleSize: int(moduleSize),
+		fgColor:    st.fgColor,
+		bgColor:    st.bgColor,
+		gradient:   st.gradient,
+		shape:      st.qrShape,
 	}
 
 	// Add center logo if requested
-	if centerLogo == "true" {
-		var logoPath string
-		if logoFile != "" {
-			// Use uploaded logo file
-			logoPath = filepath.Join("uploads", logoFile)
-		} else {
-			// Use default uploaded logo
-			logoPath = "uploads/temp_logo.png"
-		}
-
+	if st.centerLogo == "true" {
+		logoPath := h.logoPath(st)
 		if _, err := os.Stat(logoPath); err == nil {
-			baseOptions = append(baseOptions, standard.WithLogoImageFilePNG(logoPath))
+			if logo, err := loadLogoPNG(logoPath); err == nil {
+				rasterOpts.logo = logo
+			} else {
+				slog.Warn("failed to decode logo", "path", logoPath, "err", err)
+			}
 		}
 	}
 
-	// Handle background color - transparent or solid
-	if bgColor.A == 0 {
-		// Transparent background
-		fmt.Printf("DEBUG: Using transparent background for PNG\n")
-		baseOptions = append(baseOptions, standard.WithBgTransparent())
-		baseOptions = append(baseOptions, standard.WithBuiltinImageEncoder(standard.PNG_FORMAT))
-	} else {
-		// Solid background color
-		fmt.Printf("DEBUG: Using solid background color: %+v\n", bgColor)
-		baseOptions = append(baseOptions, standard.WithBgColor(bgColor))
-	}
-
-	// Add shape option based on qrShape parameter
-	switch qrShape {
-	case "circle":
-		baseOptions = append(baseOptions, standard.WithCircleShape())
-	case "liquid":
-		baseOptions = append(baseOptions, standard.WithCustomShape(&customShape{drawFunc: shapes.LiquidBlock()}))
-	case "chain":
-		baseOptions = append(baseOptions, standard.WithCustomShape(&customShape{drawFunc: shapes.ChainBlock()}))
-	case "hstripe":
-		baseOptions = append(baseOptions, standard.WithCustomShape(&customShape{drawFunc: shapes.HStripeBlock(0.85)}))
-	case "vstripe":
-		baseOptions = append(baseOptions, standard.WithCustomShape(&customShape{drawFunc: shapes.VStripeBlock(0.85)}))
-	default:
-		// rectangle - default shape, no additional options needed
-	}
-
-	if useGradient {
-		writerOptions = append(baseOptions, standard.WithFgGradient(gradient))
-	} else {
-		writerOptions = append(baseOptions, standard.WithFgColor(fgColor))
-	}
-
-	writer, err := standard.New(tmpFile, writerOptions...)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create QR writer"})
-		return
+	// A transparent background is drawn fully transparent
+	if st.bgColor.A == 0 {
+		rasterOpts.bgColor = color.RGBA{0, 0, 0, 0}
 	}
 
 	// Write QR code to file
-	if err := qrc.Save(writer); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to generate QR code image: %v", err)})
-		return
+	done := h.stage(ctx, stageDraw)
+	err := writeRasterPNG(tmpFile, bitmap, rasterOpts)
+	done()
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate QR code image: %v", err)
 	}
 
 	// Clean up anti-aliasing artifacts (white border pixels) for transparent background
-	if bgColor.A == 0 {
-		if err := h.cleanupAntiAliasing(tmpFile, fgColor); err != nil {
-			fmt.Printf("Warning: Failed to cleanup anti-aliasing: %v\n", err)
+	if st.bgColor.A == 0 {
+		if err := h.cleanupAntiAliasing(tmpFile, st.fgColor); err != nil {
+			slog.Warn("failed to clean up anti-aliasing", "err", err)
 		}
 	}
 
-	// Ensure the writer is properly closed and synced
-	writer.Close()
-
 	// Debug: Check actual generated size
-	if file, err := os.Open(tmpFile); err == nil {
+	if file, err := os.Open(tmpFile); err == nil && slog.Default().Enabled(context.Background(), slog.LevelDebug) {
 		if img, _, err := image.DecodeConfig(file); err == nil {
-			fmt.Printf("Generated QR size: %dx%d (requested moduleSize: %d, size: %s)\n",
-				img.Width, img.Height, moduleSize, size)
+			slog.Debug("QR rendered", "width", img.Width, "height", img.Height, "moduleSize", moduleSize, "size", st.size)
 		}
 		file.Close()
 	}
@@ -319,17 +701,21 @@ func (h *Handler) generatePNGQR(c *gin.Context, qrc *qrcode.QRCode, useGradient
 	}
 
 	// For download size, ensure we reach target dimensions
-	if size == "download" {
-		if err := h.ensureMinimumQRSize(tmpFile, 2000); err != nil {
-			fmt.Printf("Warning: Could not scale QR to target size: %v\n", err)
+	if st.size == "download" {
+		done := h.stage(ctx, stageScale)
+		if err := h.ensureMinimumQRSize(tmpFile, h.downloadSize); err != nil {
+			slog.Warn("could not scale QR to target size", "err", err)
 		}
+		done()
 	}
 
-	// Store original QR size before any modifications
-	originalSize := 0
+	// Store original QR size before any modifications. Padding and frame
+	// widths follow the short side so rectangular symbols get even edges.
+	originalSize, shortSide := 0, 0
 	if file, err := os.Open(tmpFile); err == nil {
 		if img, _, err := image.DecodeConfig(file); err == nil {
-			originalSize = img.Width
+			originalSize = max(img.Width, img.Height)
+			shortSide = min(img.Width, img.Height)
 		}
 		file.Close()
 	}
@@ -338,180 +724,162 @@ func (h *Handler) generatePNGQR(c *gin.Context, qrc *qrcode.QRCode, useGradient
 	// requested previewSize AFTER padding and frame, so we don't scale the
 	// decorative frame later (which causes aliasing/dotting artifacts).
 	didPreviewPreScale := false
-	if size == "preview" {
-		if ps := c.Query("previewSize"); ps != "" {
-			if target, err := strconv.Atoi(ps); err == nil && target > 0 && originalSize > 0 {
-				// final = base + 2*(padding + frame) where padding = originalSize*border/100
-				// and frame = originalSize*frameWidthPercent/100
-				multiplier := 1.0 + 2.0*((float64(border)+float64(frameWidthPercent))/100.0)
-				desiredBase := int(math.Round(float64(target) / multiplier))
-				if desiredBase > 0 && desiredBase != originalSize {
-					if err := h.ensureExactQRSize(tmpFile, desiredBase); err == nil {
-						// update originalSize to the new base size
-						if file, err := os.Open(tmpFile); err == nil {
-							if img, _, err := image.DecodeConfig(file); err == nil {
-								originalSize = img.Width
-							}
-							file.Close()
+	if st.size == "preview" {
+		if target := st.previewSize; target > 0 && originalSize > 0 {
+			// final = base + 2*(padding + frame) where padding = originalSize*border/100
+			// and frame = originalSize*frameWidthPercent/100
+			multiplier := 1.0 + 2.0*((float64(st.border)+float64(st.frameWidthPercent))/100.0)
+			if st.quietZone > 0 {
+				// padding is quietZone modules, frame a share of the short side
+				multiplier = 1.0 + 2.0*(float64(st.quietZone)/float64(longSide)+
+					float64(st.frameWidthPercent)/100.0*float64(shortSide)/float64(originalSize))
+			}
+			desiredBase := int(math.Round(float64(target) / multiplier))
+			if desiredBase > 0 && desiredBase != originalSize {
+				done := h.stage(ctx, stageScale)
+				err := h.ensureExactQRSize(tmpFile, desiredBase)
+				done()
+				if err == nil {
+					// update originalSize to the new base size
+					if file, err := os.Open(tmpFile); err == nil {
+						if img, _, err := image.DecodeConfig(file); err == nil {
+							originalSize = max(img.Width, img.Height)
+							shortSide = min(img.Width, img.Height)
 						}
-						didPreviewPreScale = true
+						file.Close()
 					}
+					didPreviewPreScale = true
 				}
 			}
 		}
 	}
 
 	// Step 2: Add padding around QR+logo - use transparent padding for transparent QRs
-	if border > 0 {
-		paddingBgColor := bgColor
-		if bgColor.A == 0 {
+	paddingPixels := (shortSide * st.border) / 100
+	if st.quietZone > 0 && longSide > 0 {
+		paddingPixels = int(math.Round(float64(st.quietZone*originalSize) / float64(longSide)))
+	}
+	if paddingPixels > 0 {
+		done := h.stage(ctx, stagePad)
+		paddingBgColor := st.bgColor
+		if st.bgColor.A == 0 {
 			paddingBgColor = color.RGBA{0, 0, 0, 0} // Ensure truly transparent
 		}
-		if err := h.addAbsolutePaddingToQRFile(tmpFile, border, originalSize, paddingBgColor); err != nil {
-			fmt.Printf("Warning: Could not add padding to QR: %v\n", err)
+		if err := h.addAbsolutePaddingToQRFile(tmpFile, paddingPixels, paddingBgColor); err != nil {
+			slog.Warn("could not add padding to QR", "err", err)
 		}
+		done()
 	}
 
 	// Step 3: Add decorative frame around everything - with appropriate background
-	if frame != "none" {
-		framePixels := (originalSize * frameWidthPercent) / 100
+	if st.frame != "none" {
+		done := h.stage(ctx, stageFrame)
+		framePixels := (shortSide * st.frameWidthPercent) / 100
 		// Use the actual QR background color for the frame background so
 		// any carved inner gap (rounded frames) visually matches the QR padding.
-		frameBgColor := bgColor
-		if bgColor.A == 0 {
+		frameBgColor := st.bgColor
+		if st.bgColor.A == 0 {
 			frameBgColor = color.RGBA{0, 0, 0, 0} // Ensure fully transparent
 		}
-		if err := h.addFrameToQRFile(tmpFile, frame, framePixels, frameBgColor, borderColor, useGradient, gradientStart, gradientMiddle, gradientEnd); err != nil {
-			fmt.Printf("Warning: Could not add frame to QR: %v\n", err)
+		if err := h.addFrameToQRFile(tmpFile, st.frame, framePixels, frameBgColor, st.borderColor, st.useGradient, st.gradientStart, st.gradientMiddle, st.gradientEnd); err != nil {
+			slog.Warn("could not add frame to QR", "err", err)
 		}
+		done()
 	}
 
 	// If preview and we did not pre-scale, fall back to final scaling as before
-	if size == "preview" && !didPreviewPreScale {
-		if ps := c.Query("previewSize"); ps != "" {
-			if target, err := strconv.Atoi(ps); err == nil && target > 0 {
-				if err := h.ensureExactQRSize(tmpFile, target); err != nil {
-					fmt.Printf("Warning: Could not scale QR to preview size: %v\n", err)
-				}
-			}
+	if st.size == "preview" && !didPreviewPreScale && st.previewSize > 0 {
+		done := h.stage(ctx, stageScale)
+		if err := h.ensureExactQRSize(tmpFile, st.previewSize); err != nil {
+			slog.Warn("could not scale QR to preview size", "err", err)
 		}
+		done()
 	}
 
 	// Verify file exists and has content
 	fileInfo, err := os.Stat(tmpFile)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Generated QR file not found: %v", err)})
-		return
+		return "", fmt.Errorf("Generated QR file not found: %v", err)
 	}
 	if fileInfo.Size() == 0 {
 		os.Remove(tmpFile) // Clean up empty file
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Generated QR file is empty"})
-		return
-	}
-
-	// Read the file and send it as requested format
-	file, err := os.Open(tmpFile)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to read QR code file: %v", err)})
-		return
+		return "", fmt.Errorf("Generated QR file is empty")
 	}
-	defer file.Close()
-	defer os.Remove(tmpFile) // Clean up temp file
-
-	c.Header("Cache-Control", "public, max-age=3600") // Cache for 1 hour
 
-	if outputFormat == "jpg" {
-		// Decode PNG, composite onto opaque background, encode JPEG
-		img, _, err := image.Decode(file)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to decode QR image: %v", err)})
-			return
-		}
-		// Create opaque background using selected bgColor (fallback to white)
-		bg := color.RGBA{bgColor.R, bgColor.G, bgColor.B, 255}
-		if bgColor.A == 0 {
-			bg = color.RGBA{255, 255, 255, 255}
-		}
-		outBounds := img.Bounds()
-		out := image.NewRGBA(outBounds)
-		draw.Draw(out, outBounds, &image.Uniform{C: bg}, image.Point{}, draw.Src)
-		draw.Draw(out, outBounds, img, outBounds.Min, draw.Over)
-
-		c.Header("Content-Type", "image/jpeg")
-		if err := jpeg.Encode(c.Writer, out, &jpeg.Options{Quality: 92}); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to encode JPEG: %v", err)})
-			return
-		}
-		fmt.Printf("[QR] sent JPG size=%s shape=%s\n", size, qrShape)
-		return
-	}
-
-	// Default: stream PNG bytes
-	c.Header("Content-Type", "image/png")
-	if _, err := io.Copy(c.Writer, file); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send QR code"})
-		return
-	}
-	fmt.Printf("[QR] sent PNG size=%s shape=%s\n", size, qrShape)
+	return tmpFile, nil
 }
 
 // generateSVGQR generates a true vector SVG QR code
-func (h *Handler) generateSVGQR(c *gin.Context, qrc *qrcode.QRCode, useGradient bool, fgColor, bgColor, gradientStart, gradientMiddle, gradientEnd, borderColor color.RGBA, border int, frame string, frameWidthPercent int, size, qrShape, centerLogo string) {
-	// Generate true vector SVG from QR matrix data
-	if err := h.generateVectorSVG(c, qrc, useGradient, fgColor, bgColor, gradientStart, gradientMiddle, gradientEnd, borderColor, border, frame, frameWidthPercent, size, qrShape, centerLogo); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to generate vector SVG: %v", err)})
-		return
-	}
+func (h *Handler) generateSVGQR(c *gin.Context, bitmap [][]bool, st qrStyle) {
+	h.sendImage(c, h.renderKey(bitmap, st, "svg"), "image/svg+xml", h.renderCost(st, "svg"), st, "svg", func() ([]byte, error) {
+		// Generate true vector SVG from QR matrix data
+		done := h.stage(c.Request.Context(), stageDraw)
+		svg, err := h.generateVectorSVG(bitmap, st)
+		done()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to generate vector SVG: %v", err)
+		}
+		return svg, nil
+	})
 }
 
 // generateVectorSVG creates a true vector SVG QR code from matrix data
-func (h *Handler) generateVectorSVG(c *gin.Context, qrc *qrcode.QRCode, useGradient bool, fgColor, bgColor, gradientStart, gradientMiddle, gradientEnd, borderColor color.RGBA, border int, frame string, frameWidthPercent int, size, qrShape, centerLogo string) error {
-	// Get QR matrix dimensions and bitmap data
-	dimension := qrc.Dimension()
-	if dimension <= 0 {
-		return fmt.Errorf("invalid QR matrix dimension")
-	}
+func (h *Handler) generateVectorSVG(bitmap [][]bool, st qrStyle) ([]byte, error) {
+	useGradient, fgColor, bgColor, borderColor := st.useGradient, st.fgColor, st.bgColor, st.borderColor
+	frame, border, frameWidthPercent := st.frame, st.border, st.frameWidthPercent
 
-	// Access QR matrix bitmap through a different approach
-	// We'll need to create a temporary bitmap by iterating through the matrix
+	// Get QR matrix dimensions (rMQR symbols are wider than they are tall)
+	rows := len(bitmap)
+	if rows <= 0 || len(bitmap[0]) <= 0 {
+		return nil, fmt.Errorf("invalid QR matrix dimension")
+	}
+	cols := len(bitmap[0])
+	dimension := max(rows, cols)
 
 	// Calculate module size for different target sizes
 	var moduleSize int
 	var targetSize int
-	if size == "download" {
-		targetSize = 2000
+	if st.size == "download" {
+		targetSize = h.downloadSize
 		moduleSize = targetSize / dimension
 	} else {
 		targetSize = 400 // Preview size
 		moduleSize = targetSize / dimension
 	}
+	// The target applies to the longer side
+	targetW, targetH := targetSize*cols/dimension, targetSize*rows/dimension
+	shortSide := min(targetW, targetH)
 
 	// Calculate total SVG size including padding and frame
-	paddingPixels := (targetSize * border) / 100
+	paddingPixels := (shortSide * border) / 100
+	if st.quietZone > 0 {
+		paddingPixels = st.quietZone * moduleSize
+	}
 	framePixels := 0
 	if frame != "none" {
-		framePixels = (targetSize * frameWidthPercent) / 100
+		framePixels = (shortSide * frameWidthPercent) / 100
 	}
 
-	totalSize := targetSize + (paddingPixels * 2) + (framePixels * 2)
+	totalWidth := targetW + (paddingPixels * 2) + (framePixels * 2)
+	totalHeight := targetH + (paddingPixels * 2) + (framePixels * 2)
 	qrOffset := framePixels + paddingPixels
 
 	// Start building SVG content
 	svgBuilder := strings.Builder{}
 	svgBuilder.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
 	svgBuilder.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">`,
-		totalSize, totalSize, totalSize, totalSize))
+		totalWidth, totalHeight, totalWidth, totalHeight))
 
 	// Add definitions for gradients if needed
 	if useGradient {
 		svgBuilder.WriteString(`<defs>`)
 		svgBuilder.WriteString(`<linearGradient id="qrGradient" x1="0%" y1="0%" x2="100%" y2="100%">`)
 		svgBuilder.WriteString(fmt.Sprintf(`<stop offset="0%%" stop-color="rgb(%d,%d,%d)"/>`,
-			gradientStart.R, gradientStart.G, gradientStart.B))
+			st.gradientStart.R, st.gradientStart.G, st.gradientStart.B))
 		svgBuilder.WriteString(fmt.Sprintf(`<stop offset="50%%" stop-color="rgb(%d,%d,%d)"/>`,
-			gradientMiddle.R, gradientMiddle.G, gradientMiddle.B))
+			st.gradientMiddle.R, st.gradientMiddle.G, st.gradientMiddle.B))
 		svgBuilder.WriteString(fmt.Sprintf(`<stop offset="100%%" stop-color="rgb(%d,%d,%d)"/>`,
-			gradientEnd.R, gradientEnd.G, gradientEnd.B))
+			st.gradientEnd.R, st.gradientEnd.G, st.gradientEnd.B))
 		svgBuilder.WriteString(`</linearGradient>`)
 		svgBuilder.WriteString(`</defs>`)
 	}
@@ -519,7 +887,7 @@ func (h *Handler) generateVectorSVG(c *gin.Context, qrc *qrcode.QRCode, useGradi
 	// Add background
 	if bgColor.A > 0 {
 		svgBuilder.WriteString(fmt.Sprintf(`<rect width="%d" height="%d" fill="rgb(%d,%d,%d)"/>`,
-			totalSize, totalSize, bgColor.R, bgColor.G, bgColor.B))
+			totalWidth, totalHeight, bgColor.R, bgColor.G, bgColor.B))
 	}
 
 	// Add frame if requested
@@ -532,16 +900,16 @@ func (h *Handler) generateVectorSVG(c *gin.Context, qrc *qrcode.QRCode, useGradi
 		// Create simple frame as a border (4 rectangles around the edges)
 		// Top border
 		svgBuilder.WriteString(fmt.Sprintf(`<rect x="0" y="0" width="%d" height="%d" fill="%s"/>`,
-			totalSize, framePixels, frameFillColor))
+			totalWidth, framePixels, frameFillColor))
 		// Bottom border
 		svgBuilder.WriteString(fmt.Sprintf(`<rect x="0" y="%d" width="%d" height="%d" fill="%s"/>`,
-			totalSize-framePixels, totalSize, framePixels, frameFillColor))
+			totalHeight-framePixels, totalWidth, framePixels, frameFillColor))
 		// Left border
 		svgBuilder.WriteString(fmt.Sprintf(`<rect x="0" y="%d" width="%d" height="%d" fill="%s"/>`,
-			framePixels, framePixels, totalSize-(2*framePixels), frameFillColor))
+			framePixels, framePixels, totalHeight-(2*framePixels), frameFillColor))
 		// Right border
 		svgBuilder.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`,
-			totalSize-framePixels, framePixels, framePixels, totalSize-(2*framePixels), frameFillColor))
+			totalWidth-framePixels, framePixels, framePixels, totalHeight-(2*framePixels), frameFillColor))
 	}
 
 	// Generate QR modules as SVG paths
@@ -550,48 +918,16 @@ func (h *Handler) generateVectorSVG(c *gin.Context, qrc *qrcode.QRCode, useGradi
 		fillColor = "url(#qrGradient)"
 	}
 
-	// Create a bitmap by examining a temporary PNG first
-	// Since we can't directly access the matrix, we'll generate a minimal PNG first
-	tmpFile := filepath.Join(os.TempDir(), generateUniqueFilename("qr_matrix", ".png"))
-	defer os.Remove(tmpFile)
-
-	// Create minimal writer for matrix extraction
-	writer, err := standard.New(tmpFile, standard.WithQRWidth(1), standard.WithBorderWidth(0), standard.WithBgColor(color.RGBA{255, 255, 255, 255}), standard.WithFgColor(color.RGBA{0, 0, 0, 255}))
-	if err != nil {
-		return fmt.Errorf("failed to create QR writer for matrix extraction: %v", err)
-	}
-
-	if err := qrc.Save(writer); err != nil {
-		return fmt.Errorf("failed to generate QR for matrix extraction: %v", err)
-	}
-	writer.Close()
-
-	// Read the generated PNG to extract the matrix
-	file, err := os.Open(tmpFile)
-	if err != nil {
-		return fmt.Errorf("failed to open matrix file: %v", err)
-	}
-	defer file.Close()
-
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return fmt.Errorf("failed to decode matrix image: %v", err)
-	}
-
-	bounds := img.Bounds()
-	actualDimension := bounds.Dx() // Should match our calculated dimension
-
 	// Iterate through image and create rectangles for dark pixels
-	for y := 0; y < actualDimension; y++ {
-		for x := 0; x < actualDimension; x++ {
-			r, _, _, _ := img.At(x, y).RGBA()
-			if r < 32768 { // Dark pixel (closer to black than white)
-				// Scale from 1-pixel module to target moduleSize
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			if bitmap[y][x] {
+				// Scale from matrix coordinates to target moduleSize
 				moduleX := qrOffset + (x * moduleSize)
 				moduleY := qrOffset + (y * moduleSize)
 
 				// Apply shape based on qrShape parameter
-				switch qrShape {
+				switch st.qrShape {
 				case "circle":
 					radius := moduleSize / 2
 					centerX := moduleX + radius
@@ -607,10 +943,10 @@ func (h *Handler) generateVectorSVG(c *gin.Context, qrc *qrcode.QRCode, useGradi
 	}
 
 	// Add center logo if requested
-	if centerLogo == "true" {
-		centerX := totalSize / 2
-		centerY := totalSize / 2
-		logoSize := targetSize / 4 // 25% of QR size
+	if st.centerLogo == "true" {
+		centerX := totalWidth / 2
+		centerY := totalHeight / 2
+		logoSize := shortSide / 4 // 25% of QR size
 
 		// Create a white background circle for the logo
 		svgBuilder.WriteString(fmt.Sprintf(`<circle cx="%d" cy="%d" r="%d" fill="white"/>`,
@@ -624,12 +960,7 @@ func (h *Handler) generateVectorSVG(c *gin.Context, qrc *qrcode.QRCode, useGradi
 	// Close SVG
 	svgBuilder.WriteString(`</svg>`)
 
-	// Return SVG content
-	c.Header("Content-Type", "image/svg+xml")
-	c.Header("Cache-Control", "public, max-age=3600")
-	c.Data(http.StatusOK, "image/svg+xml", []byte(svgBuilder.String()))
-
-	return nil
+	return []byte(svgBuilder.String()), nil
 }
 
 // Helper function to parse hex color parameters
@@ -671,7 +1002,8 @@ func generateUniqueFilename(prefix, extension string) string {
 	return fmt.Sprintf("%s_%d_%x%s", prefix, timestamp, randomBytes, extension)
 }
 
-// ensureMinimumQRSize scales up QR code if it's smaller than target size
+// ensureMinimumQRSize scales up QR code if its longer side is smaller than
+// target size, keeping the aspect ratio of rectangular symbols
 func (h *Handler) ensureMinimumQRSize(filename string, minSize int) error {
 	// Open and check current size
 	file, err := os.Open(filename)
@@ -686,9 +1018,7 @@ func (h *Handler) ensureMinimumQRSize(filename string, minSize int) error {
 	}
 
 	bounds := qrImg.Bounds()
-	currentSize := bounds.Dx() // Assuming square QR
-
-	fmt.Printf("Current QR size: %dx%d, target: %dx%d\n", currentSize, bounds.Dy(), minSize, minSize)
+	currentSize := max(bounds.Dx(), bounds.Dy())
 
 	// If already large enough, no scaling needed
 	if currentSize >= minSize {
@@ -697,23 +1027,24 @@ func (h *Handler) ensureMinimumQRSize(filename string, minSize int) error {
 
 	// Calculate scale factor (use nearest neighbor for QR codes to keep sharp edges)
 	scaleFactor := float64(minSize) / float64(currentSize)
-	newSize := int(float64(currentSize) * scaleFactor)
+	newWidth := int(float64(bounds.Dx()) * scaleFactor)
+	newHeight := int(float64(bounds.Dy()) * scaleFactor)
 
-	fmt.Printf("Scaling QR by factor %.2f to %dx%d\n", scaleFactor, newSize, newSize)
+	slog.Debug("scaling QR", "factor", scaleFactor, "width", newWidth, "height", newHeight)
 
 	// Create new larger image
-	scaledImg := image.NewRGBA(image.Rect(0, 0, newSize, newSize))
+	scaledImg := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
 
 	// Scale using nearest neighbor (preserves sharp QR edges)
-	for y := 0; y < newSize; y++ {
-		for x := 0; x < newSize; x++ {
+	for y := 0; y < newHeight; y++ {
+		for x := 0; x < newWidth; x++ {
 			// Map back to original coordinates
 			origX := int(float64(x) / scaleFactor)
 			origY := int(float64(y) / scaleFactor)
 
 			// Ensure we don't go out of bounds
-			if origX >= currentSize {
-				origX = currentSize - 1
+			if origX >= bounds.Dx() {
+				origX = bounds.Dx() - 1
 			}
 			if origY >= bounds.Dy() {
 				origY = bounds.Dy() - 1
@@ -739,7 +1070,8 @@ func (h *Handler) ensureMinimumQRSize(filename string, minSize int) error {
 	return nil
 }
 
-// ensureExactQRSize scales the QR code to exactly targetSize x targetSize using nearest neighbor.
+// ensureExactQRSize scales the QR code so its longer side is exactly
+// targetSize using nearest neighbor; square codes end up targetSize x targetSize.
 func (h *Handler) ensureExactQRSize(filename string, targetSize int) error {
 	// Open and decode current image
 	file, err := os.Open(filename)
@@ -753,16 +1085,22 @@ func (h *Handler) ensureExactQRSize(filename string, targetSize int) error {
 	}
 
 	bounds := img.Bounds()
-	currentW := bounds.Dx()
-	if currentW == 0 || targetSize <= 0 {
+	currentW, currentH := bounds.Dx(), bounds.Dy()
+	if currentW == 0 || currentH == 0 || targetSize <= 0 {
 		return nil
 	}
 
-	scale := float64(targetSize) / float64(currentW)
+	targetW, targetH := targetSize, targetSize
+	if currentW > currentH {
+		targetH = int(math.Round(float64(targetSize*currentH) / float64(currentW)))
+	} else if currentH > currentW {
+		targetW = int(math.Round(float64(targetSize*currentW) / float64(currentH)))
+	}
+	scale := float64(targetSize) / float64(max(currentW, currentH))
 	// Create destination image
-	dst := image.NewRGBA(image.Rect(0, 0, targetSize, targetSize))
-	for y := 0; y < targetSize; y++ {
-		for x := 0; x < targetSize; x++ {
+	dst := image.NewRGBA(image.Rect(0, 0, targetW, targetH))
+	for y := 0; y < targetH; y++ {
+		for x := 0; x < targetW; x++ {
 			ox := int(float64(x) / scale)
 			oy := int(float64(y) / scale)
 			if ox >= bounds.Dx() {
@@ -786,8 +1124,9 @@ func (h *Handler) ensureExactQRSize(filename string, targetSize int) error {
 	return nil
 }
 
-// addAbsolutePaddingToQRFile adds consistent padding regardless of QR resolution
-func (h *Handler) addAbsolutePaddingToQRFile(filename string, borderPercent, originalSize int, bgColor color.RGBA) error {
+// addAbsolutePaddingToQRFile adds paddingPixels of padding on every side, so
+// rectangular codes get the same margin along both axes
+func (h *Handler) addAbsolutePaddingToQRFile(filename string, paddingPixels int, bgColor color.RGBA) error {
 	file, err := os.Open(filename)
 	if err != nil {
 		return fmt.Errorf("failed to open QR file: %v", err)
@@ -800,13 +1139,6 @@ func (h *Handler) addAbsolutePaddingToQRFile(filename string, borderPercent, ori
 	}
 
 	bounds := qrImg.Bounds()
-	// Calculate padding based on percentage of original QR size for consistency
-	paddingPixels := (originalSize * borderPercent) / 100
-	// Scale padding proportionally if QR was resized
-	if bounds.Dx() != originalSize {
-		scaleFactor := float64(bounds.Dx()) / float64(originalSize)
-		paddingPixels = int(float64(paddingPixels) * scaleFactor)
-	}
 
 	// Create new image with padding
 	newWidth := bounds.Dx() + paddingPixels*2
@@ -1354,22 +1686,6 @@ func (h *Handler) lerpColor(color1, color2 color.RGBA, t float64) color.RGBA {
 	}
 }
 
-// customShape implements the IShape interface by wrapping drawing functions from the shapes package
-type customShape struct {
-	drawFunc func(ctx *standard.DrawContext)
-}
-
-// Draw implements the IShape interface
-func (cs *customShape) Draw(ctx *standard.DrawContext) {
-	cs.drawFunc(ctx)
-}
-
-// DrawFinder implements the IShape interface for finder patterns
-func (cs *customShape) DrawFinder(ctx *standard.DrawContext) {
-	// Use the same drawing function for finder patterns
-	cs.drawFunc(ctx)
-}
-
 // cleanupAntiAliasing removes white border pixels caused by anti-aliasing
 func (h *Handler) cleanupAntiAliasing(filename string, fgColor color.RGBA) error {
 	// Open and decode the image
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/qr"
+	"github.com/gin-gonic/gin"
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	// maxAppendPayload bounds the payload accepted for Structured Append;
+	// 16 version 40 symbols hold a little under 48 KB.
+	maxAppendPayload = 64 << 10
+	// defaultAppendMaxVersion keeps automatically split symbols at a
+	// density that scans reliably from print.
+	defaultAppendMaxVersion = 10
+)
+
+// QRAppendHandler splits a long payload across a Structured Append sequence
+// and returns the symbols as a ZIP of images or as a single sheet
+func (h *Handler) QRAppendHandler(c *gin.Context) {
+	payload, err := h.appendPayload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts, err := parseQROptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if opts.Symbology != qr.SymbologyQR {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "structured append is only supported for qr"})
+		return
+	}
+
+	symbols := 0
+	if v := c.DefaultQuery("symbols", "auto"); v != "auto" {
+		if symbols, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid symbols %q (use auto or 2-%d)", v, qr.MaxAppendSymbols)})
+			return
+		}
+	}
+	if symbols == 0 && opts.MaxVersion == 0 {
+		opts.MaxVersion = defaultAppendMaxVersion
+		if opts.MinVersion > opts.MaxVersion {
+			opts.MaxVersion = opts.MinVersion
+		}
+	}
+
+	codes, err := qr.EncodeStructured(payload, opts, symbols)
+	if err != nil {
+		encodeError(c, err)
+		return
+	}
+
+	layout := c.DefaultQuery("layout", "zip")
+	format := strings.ToLower(c.DefaultQuery("format", "png"))
+	if format == "jpeg" {
+		format = "jpg"
+	}
+	st := h.parseQRStyle(c)
+
+	cellSize := 0
+	switch layout {
+	case "zip":
+		if format != "png" && format != "jpg" && format != "svg" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "zip layout supports png, jpg or svg"})
+			return
+		}
+	case "sheet":
+		if format != "png" && format != "jpg" && format != "pdf" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sheet layout supports png, jpg or pdf"})
+			return
+		}
+		cellSize = 600
+		if format == "pdf" {
+			// Print needs more pixels per module than the screen sheet.
+			cellSize = 800
+		}
+		if v, err := strconv.Atoi(c.Query("cellSize")); err == nil {
+			cellSize = max(100, min(v, 1200))
+		}
+		// Sheets always render cells at an exact size so the grid stays even
+		st.size = "preview"
+		st.previewSize = cellSize
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported layout (use zip or sheet)"})
+		return
+	}
+
+	if !h.charge(c, h.renderCost(st, format)*float64(len(codes))-1) {
+		return
+	}
+
+	logger(c).Debug("structured append request", "bytes", len(payload), "symbols", len(codes), "layout", layout, "format", format)
+	c.Header("X-QR-Symbols", strconv.Itoa(len(codes)))
+
+	if layout == "sheet" {
+		h.writeAppendSheet(c, codes, st, format, cellSize)
+		return
+	}
+	h.writeAppendZIP(c, codes, st, format)
+}
+
+// appendPayload reads the raw payload from the data parameter, a plain
+// text request body, or the url parameter.
+func (h *Handler) appendPayload(c *gin.Context) (string, error) {
+	payload := c.Query("data")
+	if payload == "" && c.Request.Method == http.MethodPost {
+		if strings.HasPrefix(c.ContentType(), "application/x-www-form-urlencoded") || strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+			payload = c.PostForm("data")
+		} else {
+			body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAppendPayload+1))
+			if err != nil {
+				return "", fmt.Errorf("failed to read request body: %v", err)
+			}
+			payload = string(body)
+		}
+	}
+	if payload == "" {
+		rawURL := strings.TrimSpace(c.Query("url"))
+		if rawURL == "" {
+			return "", fmt.Errorf("data or url parameter is required")
+		}
+		u, err := h.normalizeTaggedURL(rawURL, c)
+		if err != nil {
+			return "", err
+		}
+		return h.checkURL(c, u)
+	}
+	if len(payload) > maxAppendPayload {
+		return "", fmt.Errorf("payload exceeds %d bytes", maxAppendPayload)
+	}
+	return payload, nil
+}
+
+// appendName returns the file name of a symbol in the sequence.
+func appendName(code *qr.Code, ext string) string {
+	return fmt.Sprintf("qr-%02d-of-%02d.%s", code.Append.Index+1, code.Append.Total, ext)
+}
+
+// writeAppendZIP streams every symbol as a separate image in a ZIP archive.
+func (h *Handler) writeAppendZIP(c *gin.Context, codes []*qr.Code, st qrStyle, format string) {
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="qr-append.zip"`)
+
+	zw := zip.NewWriter(c.Writer)
+	now := time.Now()
+	for _, code := range codes {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: appendName(code, format), Method: zip.Deflate, Modified: now})
+		if err != nil {
+			logger(c).Warn("failed to add symbol to zip", "file", appendName(code, format), "err", err)
+			return
+		}
+		if err := h.writeSymbol(c.Request.Context(), w, code, st, format); err != nil {
+			logger(c).Warn("failed to render symbol", "file", appendName(code, format), "err", err)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		logger(c).Warn("failed to finish zip", "err", err)
+	}
+}
+
+// writeSymbol renders a single styled symbol to w. It gives up when ctx is
+// done.
+func (h *Handler) writeSymbol(ctx context.Context, w io.Writer, code *qr.Code, st qrStyle, format string) error {
+	// Batches and sequences wait for a slot rather than give up
+	if err := h.renderSlots.Acquire(ctx); err != nil {
+		return err
+	}
+	defer h.renderSlots.Release()
+
+	if format == "svg" {
+		svg, err := h.generateVectorSVG(code.Bitmap(), st)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(svg)
+		return err
+	}
+
+	tmpFile, err := h.renderPNGFile(ctx, code.Bitmap(), st)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile)
+	file, err := os.Open(tmpFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if format == "jpg" {
+		img, _, err := image.Decode(file)
+		if err != nil {
+			return err
+		}
+		return writeJPEG(w, img, st.bgColor)
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// writeAppendSheet lays the symbols out in order on a single image or PDF.
+func (h *Handler) writeAppendSheet(c *gin.Context, codes []*qr.Code, st qrStyle, format string, cellSize int) {
+	files := make([]string, 0, len(codes))
+	defer func() {
+		for _, f := range files {
+			os.Remove(f)
+		}
+	}()
+	if !h.startRender(c) {
+		return
+	}
+	for _, code := range codes {
+		f, err := h.renderPNGFile(c.Request.Context(), code.Bitmap(), st)
+		if err != nil {
+			h.renderSlots.Release()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		files = append(files, f)
+	}
+	h.renderSlots.Release()
+
+	if format == "pdf" {
+		c.Header("Content-Type", "application/pdf")
+		c.Header("Content-Disposition", `attachment; filename="qr-append.pdf"`)
+		if err := writeAppendPDF(c.Writer, files); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to generate PDF: %v", err)})
+		}
+		return
+	}
+
+	cols := int(math.Ceil(math.Sqrt(float64(len(files)))))
+	rows := (len(files) + cols - 1) / cols
+	gap := cellSize / 10
+	sheet := image.NewRGBA(image.Rect(0, 0, cols*cellSize+(cols+1)*gap, rows*cellSize+(rows+1)*gap))
+	bg := st.bgColor
+	if bg.A == 0 {
+		bg = color.RGBA{0, 0, 0, 0}
+	}
+	draw.Draw(sheet, sheet.Bounds(), &image.Uniform{C: bg}, image.Point{}, draw.Src)
+
+	for i, f := range files {
+		file, err := os.Open(f)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to read QR code file: %v", err)})
+			return
+		}
+		img, _, err := image.Decode(file)
+		file.Close()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to decode QR image: %v", err)})
+			return
+		}
+		x := gap + (i%cols)*(cellSize+gap)
+		y := gap + (i/cols)*(cellSize+gap)
+		draw.Draw(sheet, image.Rect(x, y, x+cellSize, y+cellSize), img, img.Bounds().Min, draw.Over)
+	}
+
+	if format == "jpg" {
+		c.Header("Content-Type", "image/jpeg")
+		if err := writeJPEG(c.Writer, sheet, st.bgColor); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.Header("Content-Type", "image/png")
+	if err := png.Encode(c.Writer, sheet); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send QR code"})
+	}
+}
+
+// writeAppendPDF places the rendered symbols on A4 pages in reading order,
+// each labelled with its position in the sequence.
+func writeAppendPDF(w io.Writer, files []string) error {
+	const (
+		pageW, pageH = 210.0, 297.0
+		margin       = 15.0
+		gap          = 8.0
+		labelH       = 7.0
+	)
+	cols := 3
+	if len(files) <= 4 {
+		cols = 2
+	}
+	cell := (pageW - 2*margin - float64(cols-1)*gap) / float64(cols)
+	rowsPerPage := int((pageH - 2*margin + gap) / (cell + labelH + gap))
+	perPage := cols * rowsPerPage
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 10)
+	for i, f := range files {
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+		slot := i % perPage
+		x := margin + float64(slot%cols)*(cell+gap)
+		y := margin + float64(slot/cols)*(cell+labelH+gap)
+		pdf.ImageOptions(f, x, y, cell, cell, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		pdf.SetXY(x, y+cell)
+		pdf.CellFormat(cell, labelH, fmt.Sprintf("%d / %d", i+1, len(files)), "", 0, "C", false, 0, "")
+	}
+	return pdf.Output(w)
+}
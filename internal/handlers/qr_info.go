@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/qr"
+	"github.com/gin-gonic/gin"
+)
+
+// parseQROptions reads the encoder parameters shared by the QR endpoints:
+// symbology, ecc, encoding, eci, minVersion, maxVersion and mask.
+func parseQROptions(c queryReader) (qr.Options, error) {
+	var opts qr.Options
+	var err error
+	if opts.Symbology, err = qr.ParseSymbology(c.Query("symbology")); err != nil {
+		return opts, err
+	}
+	opts.Level = opts.Symbology.DefaultLevel()
+	if v := c.Query("ecc"); v != "" {
+		if opts.Level, err = qr.ParseLevel(v); err != nil {
+			return opts, err
+		}
+	}
+	if opts.Encoding, err = qr.ParseEncoding(c.Query("encoding")); err != nil {
+		return opts, err
+	}
+	if opts.Charset, err = qr.ParseCharset(c.Query("eci")); err != nil {
+		return opts, err
+	}
+	if opts.MinVersion, err = intQuery(c, "minVersion"); err != nil {
+		return opts, err
+	}
+	if opts.MaxVersion, err = intQuery(c, "maxVersion"); err != nil {
+		return opts, err
+	}
+	if v := c.Query("mask"); v != "" && v != "auto" {
+		mask, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid mask %q (use auto or 0-7)", v)
+		}
+		opts.Mask = &mask
+	}
+	return opts, opts.Validate()
+}
+
+// intQuery parses an optional integer query parameter; missing means 0.
+func intQuery(c queryReader, name string) (int, error) {
+	v := strings.TrimSpace(c.Query(name))
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	return n, nil
+}
+
+// encodeError writes a 400 for an encoder error, including the required
+// version when the payload did not fit.
+func encodeError(c *gin.Context, err error) {
+	failReason(c, reasonEncode)
+	var tooLong *qr.DataTooLongError
+	if errors.As(err, &tooLong) && tooLong.RequiredVersion > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "requiredVersion": tooLong.RequiredVersion})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// QRInfoHandler reports how a payload would be encoded without rendering an image
+func (h *Handler) QRInfoHandler(c *gin.Context) {
+	rawURL := strings.TrimSpace(c.Query("url"))
+	if rawURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL parameter is required"})
+		return
+	}
+
+	normalizedURL, err := h.normalizeTaggedURL(rawURL, c)
+	if err == nil {
+		normalizedURL, err = h.checkURL(c, normalizedURL)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts, err := parseQROptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	qrc, err := qr.Encode(normalizedURL, opts)
+	if err != nil {
+		encodeError(c, err)
+		return
+	}
+
+	segments := make([]gin.H, 0, len(qrc.Segments))
+	for _, s := range qrc.Segments {
+		segments = append(segments, gin.H{"mode": s.Mode.String(), "count": s.Count})
+	}
+	// Micro QR scores its masks instead of penalising them; higher wins.
+	scoreKey, scoreName := "maskPenalties", "penalty"
+	if qrc.Symbology == qr.SymbologyMicroQR {
+		scoreKey, scoreName = "maskScores", "score"
+	}
+	penalties := make([]gin.H, 0, len(qrc.Penalties))
+	for mask, score := range qrc.Penalties {
+		penalties = append(penalties, gin.H{"mask": mask, scoreName: score})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"symbology":   qrc.Symbology.String(),
+		"version":     qrc.Version,
+		"versionName": qrc.VersionName(),
+		"modules":     qrc.Size(),
+		"width":       qrc.Width(),
+		"height":      qrc.Height(),
+		"ecc":         qrc.LevelName(),
+		"mask":        qrc.Mask,
+		"segments":    segments,
+		"capacity": gin.H{
+			"dataBits":       qrc.CapacityBits,
+			"usedBits":       qrc.DataBits,
+			"remainingBits":  qrc.RemainingBits(),
+			"remainingBytes": qrc.RemainingBits() / 8,
+		},
+		scoreKey: penalties,
+	})
+}
@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log/slog"
+	"math"
+	"os"
+
+	"github.com/fogleman/gg"
+)
+
+// The module shapes below follow the block shapes of
+// github.com/yeqown/go-qrcode/writer/standard (MIT), which this package used
+// before encoding moved to internal/qr. Keeping the geometry identical means
+// existing designs render the same way.
+
+// Neighbour flags describe which cells around a module are dark.
+const (
+	nTopLeft uint16 = 1 << iota
+	nTop
+	nTopRight
+	nLeft
+	nSelf
+	nRight
+	nBotLeft
+	nBot
+	nBotRight
+)
+
+// moduleCell is the drawing context for one module of the bitmap.
+type moduleCell struct {
+	x, y, w, h float64
+	color      color.Color
+	neighbours uint16
+}
+
+// moduleShape paints a single module onto dc.
+type moduleShape func(dc *gg.Context, c moduleCell)
+
+// rasterOptions controls how a module bitmap is painted.
+type rasterOptions struct {
+	moduleSize int
+	fgColor    color.RGBA
+	bgColor    color.RGBA
+	gradient   *linearGradient
+	shape      string
+	logo       image.Image
+}
+
+// linearGradient is a 3-stop gradient applied over the dark modules.
+type linearGradient struct {
+	angle              float64
+	start, middle, end color.RGBA
+}
+
+// rasterizeMatrix paints bitmap (indexed [y][x]) into an RGBA image with no
+// quiet zone, moduleSize pixels per module.
+func rasterizeMatrix(bitmap [][]bool, opt rasterOptions) *image.RGBA {
+	rows := len(bitmap)
+	cols := 0
+	if rows > 0 {
+		cols = len(bitmap[0])
+	}
+	ms := opt.moduleSize
+	if ms <= 0 {
+		ms = 20
+	}
+	w, h := cols*ms, rows*ms
+	dc := gg.NewContext(w, h)
+
+	dc.SetColor(opt.bgColor)
+	dc.DrawRectangle(0, 0, float64(w), float64(h))
+	dc.Fill()
+
+	fg := opt.fgColor
+	if opt.gradient != nil {
+		// Paint modules in opaque black, then swap those pixels for the
+		// gradient so anti-aliased edges keep their blended color.
+		fg = color.RGBA{0, 0, 0, 255}
+	}
+
+	shape := shapeFor(opt.shape)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			c := moduleCell{
+				x:          float64(x * ms),
+				y:          float64(y * ms),
+				w:          float64(ms),
+				h:          float64(ms),
+				color:      opt.bgColor,
+				neighbours: neighbours(bitmap, x, y),
+			}
+			if bitmap[y][x] {
+				c.color = fg
+			}
+			shape(dc, c)
+		}
+	}
+
+	img, ok := dc.Image().(*image.RGBA)
+	if !ok {
+		img = image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(img, img.Bounds(), dc.Image(), image.Point{}, draw.Src)
+	}
+
+	if opt.gradient != nil {
+		opt.gradient.apply(img, fg)
+	}
+
+	if opt.logo != nil {
+		lb := opt.logo.Bounds()
+		// Logos larger than a fifth of the symbol would hide too many modules.
+		if w >= 5*lb.Dx() && h >= 5*lb.Dy() {
+			offset := image.Pt((w-lb.Dx())/2, (h-lb.Dy())/2)
+			draw.Draw(img, lb.Sub(lb.Min).Add(offset), opt.logo, lb.Min, draw.Over)
+		} else {
+			slog.Warn("logo is larger than 1/5 of the QR, skipping it", "logo", fmt.Sprintf("%dx%d", lb.Dx(), lb.Dy()), "qr", fmt.Sprintf("%dx%d", w, h))
+		}
+	}
+	return img
+}
+
+// writeRasterPNG rasterizes bitmap and writes the result to filename.
+func writeRasterPNG(filename string, bitmap [][]bool, opt rasterOptions) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, rasterizeMatrix(bitmap, opt))
+}
+
+// loadLogoPNG decodes a PNG logo from disk.
+func loadLogoPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// neighbours returns the neighbour flags for the module at (x, y).
+func neighbours(bitmap [][]bool, x, y int) uint16 {
+	dirs := []struct {
+		dx, dy int
+		flag   uint16
+	}{
+		{-1, -1, nTopLeft}, {0, -1, nTop}, {1, -1, nTopRight},
+		{-1, 0, nLeft}, {0, 0, nSelf}, {1, 0, nRight},
+		{-1, 1, nBotLeft}, {0, 1, nBot}, {1, 1, nBotRight},
+	}
+	var res uint16
+	for _, d := range dirs {
+		nx, ny := x+d.dx, y+d.dy
+		if ny >= 0 && ny < len(bitmap) && nx >= 0 && nx < len(bitmap[ny]) && bitmap[ny][nx] {
+			res |= d.flag
+		}
+	}
+	return res
+}
+
+func has(mask, bits uint16) bool { return mask&bits == bits }
+
+// shapeFor maps a qrShape parameter to its module painter.
+func shapeFor(name string) moduleShape {
+	switch name {
+	case "circle":
+		return circleModule
+	case "liquid":
+		return liquidModule
+	case "chain":
+		return chainModule
+	case "hstripe":
+		return stripeModule(0.85, false)
+	case "vstripe":
+		return stripeModule(0.85, true)
+	}
+	return rectangleModule
+}
+
+func rectangleModule(dc *gg.Context, c moduleCell) {
+	dc.DrawRectangle(c.x, c.y, c.w, c.h)
+	dc.SetColor(c.color)
+	dc.Fill()
+}
+
+func circleModule(dc *gg.Context, c moduleCell) {
+	radius := math.Floor(math.Min(c.w, c.h) / 2)
+	dc.DrawCircle(c.x+c.w/2, c.y+c.h/2, radius)
+	dc.SetColor(c.color)
+	dc.Fill()
+}
+
+// liquidModule draws blob-like modules that melt into their neighbours.
+func liquidModule(dc *gg.Context, c moduleCell) {
+	x, y, fw, fh := c.x, c.y, c.w, c.h
+	cx, cy := x+fw/2, y+fh/2
+	r := fw / 2
+	l := fw / 2
+	mask := c.neighbours
+	dc.SetColor(c.color)
+
+	drawRect := func(x, y, w, h float64) {
+		dc.DrawRectangle(x, y, w, h)
+		dc.Fill()
+	}
+
+	switch mask {
+	case nRight | nSelf:
+		drawRect(cx, cy-r, fw/2, 2*r)
+	case nTop | nSelf:
+		drawRect(cx-r, y, 2*r, fh/2)
+	case nLeft | nSelf:
+		drawRect(x, cy-r, fw/2, 2*r)
+	case nBot | nSelf:
+		drawRect(cx-r, y+fh/2, 2*r, fh/2)
+	case nLeft | nSelf | nRight:
+		drawRect(x-fw/2, cy-r, 2*fw, 2*r)
+	}
+
+	if has(mask, nLeft|nSelf|nRight) {
+		drawRect(x-fw/2, cy-r, 2*fw, 2*r)
+	}
+	if has(mask, nTop|nSelf|nBot) {
+		drawRect(cx-r, y-fh/2, 2*r, 2*fh)
+	}
+	if has(mask, nLeft|nSelf) {
+		drawRect(x, cy-r, fw/2, 2*r)
+	}
+	if has(mask, nSelf|nRight) {
+		drawRect(cx, cy-r, fw/2, 2*r)
+	}
+	if has(mask, nSelf|nTop) {
+		drawRect(cx-r, y, 2*r, fh/2)
+	}
+	if has(mask, nSelf|nBot) {
+		drawRect(cx-r, y+fh/2, 2*r, fh/2)
+	}
+
+	if has(mask, nBot|nRight|nSelf) && mask&nBotRight == 0 {
+		dc.MoveTo(cx, cy-r)
+		dc.LineTo(cx-r, cy)
+		dc.LineTo(cx-r, y+fh+l)
+		dc.LineTo(cx+r, y+fh+l)
+		dc.QuadraticTo(cx+r, cy+r, x+fw+l, cy+r)
+		dc.LineTo(x+fw, cy-r)
+		dc.ClosePath()
+		dc.Fill()
+	}
+	if has(mask, nBot|nLeft|nSelf) && mask&nBotLeft == 0 {
+		dc.MoveTo(cx, cy-r)
+		dc.LineTo(cx+r, cy)
+		dc.LineTo(cx+r, y+fh+l)
+		dc.LineTo(cx-r, y+fh+l)
+		dc.QuadraticTo(cx-r, cy+r, x-l, cy+r)
+		dc.LineTo(x-l, cy-r)
+		dc.ClosePath()
+		dc.Fill()
+	}
+	if has(mask, nTop|nLeft|nSelf) && mask&nTopLeft == 0 {
+		dc.MoveTo(cx, cy+r)
+		dc.LineTo(cx+r, cy)
+		dc.LineTo(cx+r, y-l)
+		dc.LineTo(cx-r, y-l)
+		dc.QuadraticTo(cx-r, cy-r, x-l, cy-r)
+		dc.LineTo(x-l, cy+r)
+		dc.ClosePath()
+		dc.Fill()
+	}
+	if has(mask, nTop|nRight|nSelf) && mask&nTopRight == 0 {
+		dc.MoveTo(cx, cy+r)
+		dc.LineTo(cx-r, cy)
+		dc.LineTo(cx-r, y)
+		dc.LineTo(cx+r, y-l)
+		dc.QuadraticTo(cx+r, cy-r, x+fw+l, cy-r)
+		dc.LineTo(x+fw, cy+r)
+		dc.ClosePath()
+		dc.Fill()
+	}
+
+	dc.DrawCircle(cx, cy, r)
+	dc.Fill()
+}
+
+// chainModule draws a dot with narrow links towards dark neighbours.
+func chainModule(dc *gg.Context, c moduleCell) {
+	x, y, fw, fh := c.x, c.y, c.w, c.h
+	cx, cy := x+fw/2, y+fh/2
+	r := fw * 0.9 / 2
+	l := r * 0.2
+	mask := c.neighbours
+	dc.SetColor(c.color)
+
+	drawRect := func(x, y, w, h float64) {
+		dc.DrawRectangle(x, y, w, h)
+		dc.Fill()
+	}
+
+	dc.DrawCircle(cx, cy, r)
+	if has(mask, nTop|nSelf) {
+		drawRect(cx-l, y, 2*l, fh/2)
+	}
+	if has(mask, nBot|nSelf) {
+		drawRect(cx-l, cy, 2*l, fh/2)
+	}
+	if has(mask, nLeft|nSelf) {
+		drawRect(x, cy-l, fw/2, 2*l)
+	}
+	if has(mask, nRight|nSelf) {
+		drawRect(cx, cy-l, fw/2, 2*l)
+	}
+	dc.Fill()
+}
+
+// stripeModule draws dots joined into horizontal (or vertical) stripes.
+func stripeModule(ratio float64, vertical bool) moduleShape {
+	return func(dc *gg.Context, c moduleCell) {
+		x, y, fw, fh := c.x, c.y, c.w, c.h
+		cx, cy := x+fw/2, y+fh/2
+		mask := c.neighbours
+		dc.SetColor(c.color)
+
+		drawRect := func(x, y, w, h float64) {
+			dc.DrawRectangle(x, y, w, h)
+			dc.Fill()
+		}
+
+		if vertical {
+			r := fw * ratio / 2
+			dc.DrawCircle(cx, cy, r)
+			if has(mask, nTop|nSelf) {
+				drawRect(cx-r, y, 2*r, fh/2)
+			}
+			if has(mask, nBot|nSelf) {
+				drawRect(cx-r, cy, 2*r, fh/2)
+			}
+		} else {
+			r := fw * 0.9 / 2
+			dc.DrawCircle(cx, cy, r)
+			if has(mask, nLeft|nSelf) {
+				drawRect(x, cy-r, fw/2, 2*r)
+			}
+			if has(mask, nRight|nSelf) {
+				drawRect(cx, cy-r, fw/2, 2*r)
+			}
+		}
+		dc.Fill()
+	}
+}
+
+// apply replaces every pixel equal to fg with the gradient color at that
+// position.
+func (g *linearGradient) apply(img *image.RGBA, fg color.RGBA) {
+	angle := g.angle * math.Pi / 180.0
+	dx, dy := math.Cos(angle), -math.Sin(angle)
+
+	b := img.Bounds()
+	minProj, maxProj := math.Inf(1), math.Inf(-1)
+	for _, p := range [4][2]float64{
+		{float64(b.Min.X), float64(b.Min.Y)},
+		{float64(b.Min.X), float64(b.Max.Y)},
+		{float64(b.Max.X), float64(b.Min.Y)},
+		{float64(b.Max.X), float64(b.Max.Y)},
+	} {
+		proj := p[0]*dx + p[1]*dy
+		minProj = math.Min(minProj, proj)
+		maxProj = math.Max(maxProj, proj)
+	}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.RGBAAt(x, y) != fg {
+				continue
+			}
+			t := (float64(x)*dx + float64(y)*dy - minProj) / (maxProj - minProj)
+			img.SetRGBA(x, y, g.at(t))
+		}
+	}
+}
+
+// at returns the gradient color at t in [0, 1].
+func (g *linearGradient) at(t float64) color.RGBA {
+	blend := func(c1, c2 color.RGBA, t float64) color.RGBA {
+		return color.RGBA{
+			R: uint8(float64(c1.R)*(1-t) + float64(c2.R)*t),
+			G: uint8(float64(c1.G)*(1-t) + float64(c2.G)*t),
+			B: uint8(float64(c1.B)*(1-t) + float64(c2.B)*t),
+			A: 255,
+		}
+	}
+	switch {
+	case t <= 0:
+		return g.start
+	case t >= 1:
+		return g.end
+	case t <= 0.5:
+		return blend(g.start, g.middle, t/0.5)
+	}
+	return blend(g.middle, g.end, (t-0.5)/0.5)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/analytics"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/redirect"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/store"
+	"github.com/cristianadrielbraun/qrcreator.link/web/pages"
+	"github.com/gin-gonic/gin"
+)
+
+// RedirectHandler resolves the short URL of a dynamic code through its
+// rules. Codes that do not exist, are paused, have expired or used up
+// their scans get an error page instead, and protected codes ask for
+// their passcode.
+func (h *Handler) RedirectHandler(c *gin.Context) {
+	l, ok := h.openLink(c)
+	if !ok {
+		return
+	}
+	if l.Protected {
+		passcodePage(c, http.StatusOK, l.Slug, "")
+		return
+	}
+	h.redirect(c, l, http.StatusFound)
+}
+
+// PasscodeHandler checks the passcode entered on the page of a protected
+// code and redirects on success. Attempts are limited per visitor, and
+// failed ones per code.
+func (h *Handler) PasscodeHandler(c *gin.Context) {
+	l, ok := h.openLink(c)
+	if !ok {
+		return
+	}
+	if !l.Protected {
+		h.redirect(c, l, http.StatusSeeOther)
+		return
+	}
+
+	// Visitors are told apart by a hash of their address, kept only in
+	// memory by the limiter
+	sum := sha256.Sum256([]byte(c.ClientIP()))
+	visitor := l.Slug + "|" + hex.EncodeToString(sum[:8])
+	// Only visitors who failed lately are held to the limit per code, so
+	// that guesses from elsewhere cannot lock out someone who knows it
+	first := h.passcodeVisitors.Full(visitor)
+	allowed, wait := h.passcodeVisitors.Allow(visitor, 1)
+	if allowed && !first {
+		allowed, wait = h.passcodeLinks.Allow(l.Slug, 1)
+	}
+	if !allowed {
+		minutes := int(math.Ceil(wait.Minutes()))
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		passcodePage(c, http.StatusTooManyRequests, l.Slug, fmt.Sprintf("Too many attempts. Try again in %d minute%s.", minutes, plural(minutes)))
+		return
+	}
+	if !l.CheckPasscode(c.PostForm("passcode")) {
+		logger(c).Info("wrong passcode", "slug", l.Slug)
+		if first {
+			// Repeated attempts were charged above
+			h.passcodeLinks.Allow(l.Slug, 1)
+		}
+		passcodePage(c, http.StatusUnauthorized, l.Slug, "Wrong passcode.")
+		return
+	}
+	h.passcodeVisitors.Reset(visitor)
+	h.redirect(c, l, http.StatusSeeOther)
+}
+
+// openLink loads the link of a short URL and checks that it may redirect.
+// It renders the error page when it may not.
+func (h *Handler) openLink(c *gin.Context) (store.Link, bool) {
+	l, err := h.store.Link(c.Param("slug"))
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		linkPage(c, http.StatusNotFound, "QR code not found", "This QR code does not exist or has been deleted.")
+	case err != nil:
+		logger(c).Error("link store error", "err", err)
+		linkPage(c, http.StatusInternalServerError, "Something went wrong", "This QR code could not be opened. Please try again later.")
+	case l.Paused:
+		linkPage(c, http.StatusGone, "QR code paused", "The owner of this QR code has paused it.")
+	case l.Exhausted() || (l.ExpiresAt != nil && !h.clock().Before(*l.ExpiresAt)):
+		expiredPage(c, l)
+	default:
+		return l, true
+	}
+	return store.Link{}, false
+}
+
+// redirect redirects to the target the rules of l pick, and counts the
+// scan once the redirect is sure to be served. Scans by bots, such as link
+// previews, are recorded but do not use up the scans of the code.
+func (h *Handler) redirect(c *gin.Context, l store.Link, status int) {
+	scan := redirect.NewScan(h.clock(), c.GetHeader("User-Agent"), c.GetHeader("Accept-Language"), c.ClientIP())
+	target, _ := redirect.Resolve(&l.Link, scan)
+	if l.Campaign != nil {
+		if tagged, err := l.Campaign.Tag(target); err == nil {
+			target = tagged
+		}
+	}
+	// The blocklist may have grown since the code was saved
+	if _, err := h.screenURL(target); err != nil {
+		failReason(c, reasonBlocked)
+		linkPage(c, http.StatusForbidden, "QR code blocked", "This QR code leads to a site that was reported as unsafe.")
+		return
+	}
+	if scan.Device != analytics.Bot {
+		err := h.store.TakeScan(l.Slug)
+		switch {
+		case errors.Is(err, store.ErrExhausted):
+			expiredPage(c, l)
+			return
+		case err != nil:
+			logger(c).Warn("failed to count scan", "slug", l.Slug, "err", err)
+		}
+	}
+	h.recordScan(c, l.Slug, scan)
+	// The target can be edited at any time and depends on the rules, so
+	// the redirect must not be cached
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(status, uriString(target))
+}
+
+// linkPage renders the error page of a short URL.
+func linkPage(c *gin.Context, status int, heading, message string) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	c.Status(status)
+	if err := pages.LinkErrorPage(heading, message).Render(c.Request.Context(), c.Writer); err != nil {
+		logger(c).Warn("failed to render link page", "err", err)
+	}
+}
+
+// expiredPage renders the page of a code that has expired or used up its
+// scans, with the owner's message if there is one.
+func expiredPage(c *gin.Context, l store.Link) {
+	message := l.ExpiredMessage
+	if message == "" {
+		message = "This QR code has expired."
+	}
+	linkPage(c, http.StatusGone, "QR code expired", message)
+}
+
+// passcodePage renders the passcode form of a protected code.
+func passcodePage(c *gin.Context, status int, slug, message string) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	c.Status(status)
+	if err := pages.LinkPasscodePage(slug, message).Render(c.Request.Context(), c.Writer); err != nil {
+		logger(c).Warn("failed to render link page", "err", err)
+	}
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// Passcode attempts: 5 in a row per visitor, then one every 3 minutes;
+// 30 failed ones in a row per code across visitors, then one every 10
+// seconds for visitors who already failed.
+const (
+	passcodeVisitorBurst  = 5
+	passcodeVisitorPeriod = 3 * time.Minute
+	passcodeLinkBurst     = 30
+	passcodeLinkPeriod    = 10 * time.Second
+)
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/qr"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/token"
+	"github.com/gin-gonic/gin"
+)
+
+// TokenResponse is a signed design token and the image URL it makes.
+type TokenResponse struct {
+	Token     string     `json:"token"`
+	URL       string     `json:"url" doc:"PNG image URL; use .svg or .jpg for the other formats."`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// tokenKeys maps the GET /api/qr parameters kept in a design token to the
+// short keys they are stored under. url and data hold the payload.
+var tokenKeys = map[string]string{
+	"url": "u", "data": "d",
+	"colorMode": "m", "fg": "f", "bg": "b",
+	"gradientStart": "gs", "gradientMiddle": "gm", "gradientEnd": "ge",
+	"cornerStyle": "c", "borderPattern": "p", "borderColor": "bc",
+	"qrShape": "s", "centerLogo": "o", "logoFile": "l",
+	"symbology": "y", "ecc": "e", "encoding": "n", "eci": "i",
+	"minVersion": "v", "maxVersion": "V", "mask": "k",
+	"size": "z", "previewSize": "w",
+}
+
+// tokenDefaults are the values GET /api/qr assumed when tokens were first
+// signed, left out of tokens. Decoding puts them back, so a later change of
+// a default does not change the images of existing tokens.
+var tokenDefaults = map[string]string{
+	"colorMode": "flat", "fg": "000000", "bg": "ffffff",
+	"cornerStyle": "none", "borderPattern": "simple", "qrShape": "rectangle", "centerLogo": "false",
+	"symbology": "qr", "ecc": "Q", "encoding": "auto", "size": "preview",
+}
+
+// CreateTokenHandler signs a design into a compact token whose image is
+// served at /q/<token>.png. The token cannot be edited without breaking
+// its signature.
+func (h *Handler) CreateTokenHandler(c *gin.Context) {
+	var req design.TokenRequest
+	if err := design.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxDesignBytes), &req); err != nil {
+		v1Error(c, err)
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		v1Error(c, fieldError("expiresAt", "must be in the future"))
+		return
+	}
+	payload, _, opts, _, err := h.prepareDesign(&req.Design)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	// Refuse designs that cannot be drawn now rather than on every view
+	if _, err := qr.Encode(payload, opts); err != nil {
+		v1Error(c, err)
+		return
+	}
+
+	var expires time.Time
+	if req.ExpiresAt != nil {
+		expires = *req.ExpiresAt
+	}
+	tok := h.tokens.Sign(encodeTokenDesign(&req.Design, payload), expires)
+	resp := TokenResponse{Token: tok, URL: "/q/" + tok + ".png"}
+	if !expires.IsZero() {
+		exp := expires.UTC().Truncate(time.Second)
+		resp.ExpiresAt = &exp
+	}
+	logger(c).Info("token created", "length", len(tok), "expires", resp.ExpiresAt != nil)
+	c.JSON(http.StatusOK, resp)
+}
+
+// TokenImageHandler renders the design of a signed token; the extension
+// of the file name (png, jpg or svg) selects the format.
+func (h *Handler) TokenImageHandler(c *gin.Context) {
+	tok, format, _ := strings.Cut(c.Param("file"), ".")
+	if format == "jpeg" {
+		format = "jpg"
+	}
+	if !slices.Contains([]string{"png", "jpg", "svg"}, format) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "use /q/<token>.png, .jpg or .svg"})
+		return
+	}
+	data, expires, err := h.tokens.Verify(tok)
+	switch {
+	case errors.Is(err, token.ErrExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "this QR code link has expired"})
+		return
+	case err != nil:
+		c.JSON(http.StatusNotFound, gin.H{"error": "invalid QR code link"})
+		return
+	}
+
+	payload, q, err := h.decodeTokenDesign(data)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invalid QR code link"})
+		return
+	}
+	// The blocklist may have grown since the token was signed
+	if q.Get("url") != "" {
+		if _, err := h.screenURL(payload); err != nil {
+			failReason(c, reasonBlocked)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	opts, err := parseQROptions(valuesQuery(q))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	st := h.parseQRStyle(valuesQuery(q))
+	st.applySymbology(opts.Symbology)
+	done := h.stage(c.Request.Context(), stageEncode)
+	qrc, err := qr.Encode(payload, opts)
+	done()
+	if err != nil {
+		encodeError(c, err)
+		return
+	}
+
+	// The image of a token never changes, so it can be cached until the
+	// token expires
+	maxAge := int64(365 * 24 * time.Hour / time.Second)
+	if !expires.IsZero() {
+		maxAge = min(maxAge, int64(time.Until(expires)/time.Second))
+	}
+	c.Header("Cache-Control", "public, max-age="+strconv.FormatInt(maxAge, 10))
+	c.Header("X-QR-Version", qrc.VersionName())
+	c.Header("X-QR-Mask", strconv.Itoa(qrc.Mask))
+	if format == "svg" {
+		h.generateSVGQR(c, qrc.Bitmap(), st)
+		return
+	}
+	h.generatePNGQR(c, qrc.Bitmap(), st, format)
+}
+
+// encodeTokenDesign writes the payload and the non-default parameters of
+// a design as a query string with short keys.
+func encodeTokenDesign(d *design.Design, payload string) []byte {
+	out := url.Values{}
+	for name, values := range d.Query() {
+		key, ok := tokenKeys[name]
+		v := strings.TrimPrefix(values[0], "#")
+		if !ok || v == "" || tokenDefaults[name] == v {
+			continue
+		}
+		out.Set(key, v)
+	}
+	if d.Content.Type == "url" {
+		out.Set("u", strings.TrimPrefix(payload, "https://"))
+	} else {
+		out.Set("d", payload)
+	}
+	return []byte(out.Encode())
+}
+
+// decodeTokenDesign reads what encodeTokenDesign wrote back into the
+// payload and the GET /api/qr parameters.
+func (h *Handler) decodeTokenDesign(data []byte) (string, url.Values, error) {
+	in, err := url.ParseQuery(string(data))
+	if err != nil {
+		return "", nil, err
+	}
+	q := url.Values{}
+	for name, key := range tokenKeys {
+		if v := in.Get(key); v != "" {
+			q.Set(name, v)
+		} else if v, ok := tokenDefaults[name]; ok {
+			q.Set(name, v)
+		}
+	}
+	// Tokens signed before centerLogo had a key imply it from the logo
+	if in.Get(tokenKeys["centerLogo"]) == "" && q.Get("logoFile") != "" {
+		q.Set("centerLogo", "true")
+	}
+	if raw := q.Get("url"); raw != "" {
+		payload, err := h.normalizeHTTPURL(raw)
+		return payload, q, err
+	}
+	if data := q.Get("data"); data != "" {
+		return data, q, nil
+	}
+	return "", nil, fmt.Errorf("token has no payload")
+}
@@ -0,0 +1,474 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/qr"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/screen"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/sheet"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// maxDesignBytes bounds the size of a JSON design document.
+const maxDesignBytes = 64 << 10
+
+// v1Operations lists the v1 endpoints described by the OpenAPI document.
+var v1Operations = []design.Operation{
+	{
+		Method:  "POST",
+		Path:    "/api/v1/qr",
+		Summary: "Render a QR code from a design document",
+		Request: design.Design{},
+		Responses: map[string]map[string]any{
+			"200": {"image/png": nil, "image/jpeg": nil, "image/svg+xml": nil},
+			"400": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "POST",
+		Path:    "/api/v1/batch",
+		Summary: "Render a batch of QR codes into a ZIP archive with a manifest.csv; with ?async=true it runs as a job",
+		Request: design.Batch{},
+		Responses: map[string]map[string]any{
+			"200": {"application/zip": nil},
+			"202": {"application/json": JobResponse{}},
+			"400": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "POST",
+		Path:    "/api/v1/sheet",
+		Summary: "Lay codes out on label stock as a PDF; with ?async=true it runs as a job",
+		Request: design.Sheet{},
+		Responses: map[string]map[string]any{
+			"200": {"application/pdf": nil},
+			"202": {"application/json": JobResponse{}},
+			"400": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/sheet/templates",
+		Summary: "Bundled label templates",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": []sheet.Template{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/presets",
+		Summary: "Saved design presets and brand kits, sorted by name",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": []store.Preset{}},
+		},
+	},
+	{
+		Method:  "POST",
+		Path:    "/api/v1/presets",
+		Summary: "Save a preset; use its id as preset=<id> on GET /api/qr",
+		Request: design.Preset{},
+		Responses: map[string]map[string]any{
+			"201": {"application/json": NewPreset{}},
+			"400": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/presets/{id}",
+		Summary: "One preset",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": store.Preset{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "PUT",
+		Path:    "/api/v1/presets/{id}",
+		Summary: "Replace a preset; needs its owner key",
+		Request: design.Preset{},
+		Responses: map[string]map[string]any{
+			"200": {"application/json": store.Preset{}},
+			"400": {"application/json": design.ErrorResponse{}},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "DELETE",
+		Path:    "/api/v1/presets/{id}",
+		Summary: "Delete a preset; needs its owner key",
+		Responses: map[string]map[string]any{
+			"204": {},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/campaigns",
+		Summary: "Saved campaign templates, sorted by name",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": []store.Campaign{}},
+		},
+	},
+	{
+		Method:  "POST",
+		Path:    "/api/v1/campaigns",
+		Summary: "Save a campaign template; use its id as template in any campaign",
+		Request: design.CampaignTemplate{},
+		Responses: map[string]map[string]any{
+			"201": {"application/json": store.Campaign{}},
+			"400": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/campaigns/{id}",
+		Summary: "One campaign template",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": store.Campaign{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "PUT",
+		Path:    "/api/v1/campaigns/{id}",
+		Summary: "Replace a campaign template",
+		Request: design.CampaignTemplate{},
+		Responses: map[string]map[string]any{
+			"200": {"application/json": store.Campaign{}},
+			"400": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "DELETE",
+		Path:    "/api/v1/campaigns/{id}",
+		Summary: "Delete a campaign template",
+		Responses: map[string]map[string]any{
+			"204": {},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "POST",
+		Path:    "/api/v1/tokens",
+		Summary: "Sign a design into a short token whose image is served at /q/{token}.png",
+		Request: design.TokenRequest{},
+		Responses: map[string]map[string]any{
+			"200": {"application/json": TokenResponse{}},
+			"400": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/q/{file}",
+		Summary: "Render the design of a token; file is the token with a .png, .jpg or .svg extension",
+		Responses: map[string]map[string]any{
+			"200": {"image/png": nil, "image/jpeg": nil, "image/svg+xml": nil},
+			"403": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+			"410": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "POST",
+		Path:    "/api/v1/links",
+		Summary: "Create a dynamic code whose short URL, /r/{slug}, redirects to an editable target",
+		Request: design.NewLink{},
+		Responses: map[string]map[string]any{
+			"201": {"application/json": LinkResponse{}},
+			"400": {"application/json": design.ErrorResponse{}},
+			"409": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/links/{slug}",
+		Summary: "One dynamic code; needs its owner key",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": LinkResponse{}},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "PUT",
+		Path:    "/api/v1/links/{slug}",
+		Summary: "Change the target, pause state or expiry of a dynamic code; needs its owner key",
+		Request: design.Link{},
+		Responses: map[string]map[string]any{
+			"200": {"application/json": LinkResponse{}},
+			"400": {"application/json": design.ErrorResponse{}},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "DELETE",
+		Path:    "/api/v1/links/{slug}",
+		Summary: "Delete a dynamic code; needs its owner key",
+		Responses: map[string]map[string]any{
+			"204": {},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/links/{slug}/scans",
+		Summary: "Daily scan counts of a dynamic code over the last days (default 30, at most 366); format=csv returns a CSV file. Needs its owner key",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": ScanStats{}, "text/csv": nil},
+			"400": {"application/json": design.ErrorResponse{}},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/usage",
+		Summary: "Usage of the API key sent with the request over the last days (default 30, at most 366)",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": KeyUsage{}},
+			"400": {"application/json": design.ErrorResponse{}},
+			"401": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/keys",
+		Summary: "API keys, sorted by name. Needs the admin token",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": []store.APIKey{}},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "POST",
+		Path:    "/api/v1/keys",
+		Summary: "Create an API key; the key is returned only now. Needs the admin token",
+		Request: design.APIKey{},
+		Responses: map[string]map[string]any{
+			"201": {"application/json": NewAPIKey{}},
+			"400": {"application/json": design.ErrorResponse{}},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/keys/{id}",
+		Summary: "One API key. Needs the admin token",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": store.APIKey{}},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "PUT",
+		Path:    "/api/v1/keys/{id}",
+		Summary: "Replace the name, scopes and quota of an API key; the key stays the same. Needs the admin token",
+		Request: design.APIKey{},
+		Responses: map[string]map[string]any{
+			"200": {"application/json": store.APIKey{}},
+			"400": {"application/json": design.ErrorResponse{}},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "DELETE",
+		Path:    "/api/v1/keys/{id}",
+		Summary: "Delete an API key and its usage. Needs the admin token",
+		Responses: map[string]map[string]any{
+			"204": {},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/keys/{id}/usage",
+		Summary: "Usage of an API key over the last days (default 30, at most 366). Needs the admin token",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": KeyUsage{}},
+			"400": {"application/json": design.ErrorResponse{}},
+			"401": {"application/json": design.ErrorResponse{}},
+			"403": {"application/json": design.ErrorResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/jobs/{id}",
+		Summary: "State and progress of a job",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": JobResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "DELETE",
+		Path:    "/api/v1/jobs/{id}",
+		Summary: "Cancel a queued or running job, or delete a finished one",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": JobResponse{}},
+			"404": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/jobs/{id}/artifact",
+		Summary: "Download the artifact of a succeeded job",
+		Responses: map[string]map[string]any{
+			"200": {"application/zip": nil, "application/pdf": nil},
+			"404": {"application/json": design.ErrorResponse{}},
+			"409": {"application/json": design.ErrorResponse{}},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/api/v1/openapi.json",
+		Summary: "This document",
+		Responses: map[string]map[string]any{
+			"200": {"application/json": map[string]any{}},
+		},
+	},
+}
+
+// v1Spec builds the OpenAPI 3 document of the v1 API, with the limits of
+// h.
+func (h *Handler) v1Spec() map[string]any {
+	return design.OpenAPI("qrcreator.link API", "1.0.0", v1Operations, map[string]int{"maxURLLength": h.maxURLLength})
+}
+
+// OpenAPIHandler serves the OpenAPI 3 document of the v1 API
+func (h *Handler) OpenAPIHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, h.spec())
+}
+
+// QRV1Handler renders a QR code from a JSON design document. Unlike
+// GET /api/qr, every field is validated and unknown or invalid values are
+// rejected with field-level errors.
+func (h *Handler) QRV1Handler(c *gin.Context) {
+	var d design.Design
+	if err := design.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxDesignBytes), &d); err != nil {
+		v1Error(c, err)
+		return
+	}
+
+	payload, st, opts, findings, err := h.prepareDesign(&d)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	warnURL(c, findings)
+	done := h.stage(c.Request.Context(), stageEncode)
+	qrc, err := qr.Encode(payload, opts)
+	done()
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+
+	logger(c).Debug("QR v1 request", "type", d.Content.Type, "format", d.Output.Format, "size", st.size, "shape", st.qrShape)
+	c.Header("X-QR-Version", qrc.VersionName())
+	c.Header("X-QR-Mask", strconv.Itoa(qrc.Mask))
+	c.Header("X-QR-Segments", qrc.SegmentSummary())
+	if d.Output.Format == "svg" {
+		h.generateSVGQR(c, qrc.Bitmap(), st)
+		return
+	}
+	h.generatePNGQR(c, qrc.Bitmap(), st, d.Output.Format)
+}
+
+// prepareDesign turns a validated design into the payload, style and
+// encoder options of the rendering pipeline, with the screening findings to
+// warn about for URLs. Problems that need the filesystem or the encoder,
+// like a missing logo, are reported as field errors.
+func (h *Handler) prepareDesign(d *design.Design) (string, qrStyle, qr.Options, []screen.Finding, error) {
+	payload := d.Content.Payload()
+	var findings []screen.Finding
+	if d.Content.Type == "url" {
+		normalized, err := h.normalizeHTTPURL(payload)
+		if err != nil {
+			return "", qrStyle{}, qr.Options{}, nil, fieldError("content.url", err.Error())
+		}
+		if payload, err = h.tagURL(normalized, d.Content.Campaign); err != nil {
+			return "", qrStyle{}, qr.Options{}, nil, design.PrefixFields(err, "content.campaign")
+		}
+		if findings, err = h.screenURL(payload); err != nil {
+			return "", qrStyle{}, qr.Options{}, nil, fieldError("content.url", err.Error())
+		}
+	}
+	if err := h.checkLogo(d.Logo); err != nil {
+		return "", qrStyle{}, qr.Options{}, nil, err
+	}
+
+	q := valuesQuery(d.Query())
+	opts, err := parseQROptions(q)
+	if err != nil {
+		return "", qrStyle{}, qr.Options{}, nil, fieldError("encoding", err.Error())
+	}
+	st := h.parseQRStyle(q)
+	st.applySymbology(opts.Symbology)
+	return payload, st, opts, findings, nil
+}
+
+// checkLogo reports a logo reference to a file that was never uploaded.
+func (h *Handler) checkLogo(logo *design.Logo) error {
+	if logo == nil {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(h.uploadsDir, logo.File)); err != nil {
+		return fieldError("logo.file", "no such uploaded logo")
+	}
+	return nil
+}
+
+// fieldError returns a validation error for a single field.
+func fieldError(field, message string) error {
+	return &design.ValidationError{Fields: []design.FieldError{{Field: field, Message: message}}}
+}
+
+// v1Error writes err as a v1 error response: validation errors list their
+// fields, and payloads that do not fit report the version they need.
+func v1Error(c *gin.Context, err error) {
+	resp := design.ErrorResponse{Error: err.Error()}
+	var invalid *design.ValidationError
+	var tooLong *qr.DataTooLongError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &invalid):
+		resp.Error = "invalid design document"
+		resp.Fields = invalid.Fields
+	case errors.As(err, &tooLong):
+		failReason(c, reasonEncode)
+		resp.Fields = []design.FieldError{{Field: "content", Message: err.Error()}}
+		resp.RequiredVersion = tooLong.RequiredVersion
+	case errors.As(err, &tooLarge):
+		resp.Error = fmt.Sprintf("request body is larger than %d bytes", tooLarge.Limit)
+		c.JSON(http.StatusRequestEntityTooLarge, resp)
+		return
+	}
+	c.JSON(http.StatusBadRequest, resp)
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/jobs"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/qr"
+	"github.com/gin-gonic/gin"
+)
+
+// maxBatchBytes bounds the size of a batch request, CSV included.
+const maxBatchBytes = 8 << 20
+
+// batchResult is the outcome of rendering one row.
+type batchResult struct {
+	name string // file name in the archive, extension included
+	data []byte
+	err  error
+}
+
+// BatchHandler renders every row of a batch with a shared design and streams
+// back a ZIP with the images and a manifest.csv of row, file and status.
+// Rows that fail are listed in the manifest with the reason. With
+// async=true the batch runs as a job instead and the response is 202 with
+// the job to poll.
+func (h *Handler) BatchHandler(c *gin.Context) {
+	batch, err := readBatch(c)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	// Read the shared campaign's template once, before rows expand it
+	if err := h.fillCampaign(batch.Campaign); err != nil {
+		v1Error(c, design.PrefixFields(err, "campaign"))
+		return
+	}
+	// One unit per row; the middleware took one for the request
+	if !h.charge(c, float64(len(batch.Rows)-1)) {
+		return
+	}
+	logger(c).Info("batch request", "rows", len(batch.Rows), "async", c.Query("async") == "true")
+
+	if c.Query("async") == "true" {
+		h.submitJob(c, jobs.Task{
+			Kind:        "batch",
+			Total:       len(batch.Rows),
+			Filename:    "qr-batch.zip",
+			ContentType: "application/zip",
+			Run: func(ctx context.Context, w io.Writer, progress func(int)) error {
+				_, err := h.writeBatchZIP(ctx, w, batch, progress)
+				return err
+			},
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="qr-batch.zip"`)
+	c.Status(http.StatusOK)
+	failed, err := h.writeBatchZIP(c.Request.Context(), c.Writer, batch, func(int) {})
+	if err != nil {
+		logger(c).Warn("failed to write batch archive", "err", err)
+		return
+	}
+	logger(c).Info("batch done", "rows", len(batch.Rows), "failed", failed)
+}
+
+// writeBatchZIP renders the rows and writes the ZIP archive to w, reporting
+// the number of rows written after each one. It returns the number of rows
+// that failed.
+func (h *Handler) writeBatchZIP(ctx context.Context, w io.Writer, batch *design.Batch, progress func(done int)) (int, error) {
+	n := len(batch.Rows)
+	workers := min(runtime.NumCPU(), 8, n)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Rows are rendered in parallel but written in order. The window keeps
+	// workers from running too far ahead of the writer.
+	results := make([]chan batchResult, n)
+	for i := range results {
+		results[i] = make(chan batchResult, 1)
+	}
+	window := make(chan struct{}, workers*4)
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range n {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for range workers {
+		go func() {
+			for i := range jobs {
+				results[i] <- h.renderBatchRow(ctx, batch, i)
+			}
+		}()
+	}
+
+	zw := zip.NewWriter(w)
+	now := time.Now()
+
+	var manifest bytes.Buffer
+	mw := csv.NewWriter(&manifest)
+	mw.Write([]string{"row", "filename", "status", "error"})
+	used := map[string]bool{}
+	failed := 0
+	for i := range n {
+		var res batchResult
+		select {
+		case res = <-results[i]:
+		case <-ctx.Done():
+			return failed, ctx.Err()
+		}
+		<-window
+
+		row := strconv.Itoa(i + 1)
+		if res.err != nil {
+			failed++
+			mw.Write([]string{row, "", "failed", res.err.Error()})
+			progress(i + 1)
+			continue
+		}
+		name := uniqueName(used, res.name)
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err == nil {
+			_, err = fw.Write(res.data)
+		}
+		if err != nil {
+			return failed, err
+		}
+		mw.Write([]string{row, name, "ok", ""})
+		progress(i + 1)
+	}
+
+	mw.Flush()
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: "manifest.csv", Method: zip.Deflate, Modified: now})
+	if err == nil {
+		_, err = fw.Write(manifest.Bytes())
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	return failed, err
+}
+
+// readBatch reads a batch from a JSON body or from a multipart form with
+// a CSV file in rows and optional design and campaign (JSON) and filename
+// fields.
+func readBatch(c *gin.Context) (*design.Batch, error) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBytes)
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		var batch design.Batch
+		if err := design.Decode(body, &batch); err != nil {
+			return nil, err
+		}
+		return &batch, nil
+	}
+
+	c.Request.Body = body
+	rows, err := formCSVRows(c)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := design.Batch{Filename: c.PostForm("filename"), Rows: rows}
+	if v := c.PostForm("design"); v != "" {
+		if err := design.Decode(strings.NewReader(v), &batch.Design); err != nil {
+			return nil, design.PrefixFields(err, "design")
+		}
+	}
+	if v := c.PostForm("campaign"); v != "" {
+		batch.Campaign = &design.Campaign{}
+		if err := design.Decode(strings.NewReader(v), batch.Campaign); err != nil {
+			return nil, design.PrefixFields(err, "campaign")
+		}
+	}
+	if err := design.Check(&batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// formCSVRows parses the CSV file uploaded in the rows field.
+func formCSVRows(c *gin.Context) ([]design.BatchRow, error) {
+	file, err := c.FormFile("rows")
+	if err != nil {
+		return nil, fieldError("rows", "a CSV file is required")
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rows, err := design.ParseCSVRows(f)
+	if err != nil {
+		return nil, fieldError("rows", err.Error())
+	}
+	return rows, nil
+}
+
+// renderBatchRow renders row i of the batch.
+func (h *Handler) renderBatchRow(ctx context.Context, batch *design.Batch, i int) batchResult {
+	row := batch.Rows[i]
+	d, err := batch.RowDesign(i, row)
+	if err != nil {
+		return batchResult{err: err}
+	}
+	payload, st, opts, _, err := h.prepareDesign(d)
+	if err != nil {
+		return batchResult{err: err}
+	}
+	code, err := qr.Encode(payload, opts)
+	if err != nil {
+		return batchResult{err: err}
+	}
+	var buf bytes.Buffer
+	if err := h.writeSymbol(ctx, &buf, code, st, d.Output.Format); err != nil {
+		return batchResult{err: err}
+	}
+	row.Content = d.Content // with defaults filled
+	name := batch.RowFilename(i, len(batch.Rows), row) + "." + d.Output.Format
+	return batchResult{name: name, data: buf.Bytes()}
+}
+
+// uniqueName returns name, or name with a -2, -3... suffix if it is taken.
+func uniqueName(used map[string]bool, name string) string {
+	base, ext := name, ""
+	if dot := strings.LastIndexByte(name, '.'); dot > 0 {
+		base, ext = name[:dot], name[dot:]
+	}
+	for k := 2; used[name]; k++ {
+		name = fmt.Sprintf("%s-%d%s", base, k, ext)
+	}
+	used[name] = true
+	return name
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// CampaignsHandler lists the saved campaign templates
+func (h *Handler) CampaignsHandler(c *gin.Context) {
+	campaigns, err := h.store.Campaigns()
+	if err != nil {
+		campaignError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, campaigns)
+}
+
+// CampaignHandler returns one campaign template
+func (h *Handler) CampaignHandler(c *gin.Context) {
+	t, err := h.store.Campaign(c.Param("id"))
+	if err != nil {
+		campaignError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// CreateCampaignHandler saves a new campaign template
+func (h *Handler) CreateCampaignHandler(c *gin.Context) {
+	var doc design.CampaignTemplate
+	if err := design.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxDesignBytes), &doc); err != nil {
+		v1Error(c, err)
+		return
+	}
+	t, err := h.store.CreateCampaign(doc)
+	if err != nil {
+		campaignError(c, err)
+		return
+	}
+	logger(c).Info("campaign template created", "campaign", t.ID, "name", t.Name)
+	c.Header("Location", "/api/v1/campaigns/"+t.ID)
+	c.JSON(http.StatusCreated, t)
+}
+
+// UpdateCampaignHandler replaces the document of a campaign template
+func (h *Handler) UpdateCampaignHandler(c *gin.Context) {
+	var doc design.CampaignTemplate
+	if err := design.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxDesignBytes), &doc); err != nil {
+		v1Error(c, err)
+		return
+	}
+	t, err := h.store.UpdateCampaign(c.Param("id"), doc)
+	if err != nil {
+		campaignError(c, err)
+		return
+	}
+	logger(c).Info("campaign template updated", "campaign", t.ID, "name", t.Name)
+	c.JSON(http.StatusOK, t)
+}
+
+// DeleteCampaignHandler deletes a campaign template
+func (h *Handler) DeleteCampaignHandler(c *gin.Context) {
+	if err := h.store.DeleteCampaign(c.Param("id")); err != nil {
+		campaignError(c, err)
+		return
+	}
+	logger(c).Info("campaign template deleted", "campaign", c.Param("id"))
+	c.Status(http.StatusNoContent)
+}
+
+// fillCampaign fills the empty fields of a campaign from the template it
+// names, if any, and drops the reference so it is only read once.
+func (h *Handler) fillCampaign(cmp *design.Campaign) error {
+	if cmp == nil || cmp.Template == "" {
+		return nil
+	}
+	t, err := h.store.Campaign(cmp.Template)
+	if errors.Is(err, store.ErrNotFound) {
+		return fieldError("template", "no such campaign template")
+	}
+	if err != nil {
+		return err
+	}
+	*cmp = cmp.Fill(t.Campaign)
+	cmp.Template = ""
+	return nil
+}
+
+// tagURL fills a campaign from its template and adds it to a normalized
+// URL. Field errors are relative to the campaign.
+func (h *Handler) tagURL(u string, cmp *design.Campaign) (string, error) {
+	if cmp == nil {
+		return u, nil
+	}
+	if err := h.fillCampaign(cmp); err != nil {
+		return "", err
+	}
+	tagged, err := cmp.Tag(u)
+	if err != nil {
+		return "", err
+	}
+	if tagged = iriString(tagged); len(tagged) > h.maxURLLength {
+		return "", fieldError("", fmt.Sprintf("makes the URL longer than %d characters", h.maxURLLength))
+	}
+	return tagged, nil
+}
+
+// campaignError writes the error response of a campaigns endpoint.
+func campaignError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, design.ErrorResponse{Error: "campaign template not found"})
+		return
+	}
+	logger(c).Error("campaign store error", "err", err)
+	c.JSON(http.StatusInternalServerError, design.ErrorResponse{Error: "campaign store error"})
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+// JobResponse is a job as returned by the jobs endpoints.
+type JobResponse struct {
+	jobs.Job
+	Location    string `json:"location" doc:"URL to poll for the job's state."`
+	ArtifactURL string `json:"artifactUrl,omitempty" doc:"URL of the artifact once the job has succeeded."`
+}
+
+func jobResponse(job jobs.Job) JobResponse {
+	resp := JobResponse{Job: job, Location: "/api/v1/jobs/" + job.ID}
+	if job.Status == jobs.StatusSucceeded {
+		resp.ArtifactURL = resp.Location + "/artifact"
+	}
+	return resp
+}
+
+// submitJob queues task and answers 202 with the job to poll.
+func (h *Handler) submitJob(c *gin.Context, task jobs.Task) {
+	if h.jobs == nil {
+		c.JSON(http.StatusServiceUnavailable, design.ErrorResponse{Error: "asynchronous jobs are not enabled"})
+		return
+	}
+	job, err := h.jobs.Submit(task)
+	if errors.Is(err, jobs.ErrShutdown) {
+		failReason(c, reasonNotReady)
+		c.JSON(http.StatusServiceUnavailable, design.ErrorResponse{Error: "the server is shutting down, try again shortly"})
+		return
+	}
+	if err != nil {
+		logger(c).Error("failed to submit job", "err", err)
+		c.JSON(http.StatusInternalServerError, design.ErrorResponse{Error: "failed to submit job"})
+		return
+	}
+	logger(c).Info("job submitted", "job", job.ID, "kind", job.Kind, "total", job.Total)
+	resp := jobResponse(job)
+	c.Header("Location", resp.Location)
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// JobHandler reports the state and progress of a job
+func (h *Handler) JobHandler(c *gin.Context) {
+	job, err := h.getJob(c.Param("id"))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, jobResponse(job))
+}
+
+// JobArtifactHandler downloads the artifact of a succeeded job
+func (h *Handler) JobArtifactHandler(c *gin.Context) {
+	if h.jobs == nil {
+		jobError(c, jobs.ErrNotFound)
+		return
+	}
+	f, job, err := h.jobs.Open(c.Param("id"))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	defer f.Close()
+
+	c.Header("Content-Type", job.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", job.Filename))
+	c.Header("Content-Length", strconv.FormatInt(job.Size, 10))
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, f); err != nil {
+		logger(c).Warn("failed to send job artifact", "job", job.ID, "err", err)
+	}
+}
+
+// CancelJobHandler cancels a queued or running job, or deletes a finished
+// one with its artifact.
+func (h *Handler) CancelJobHandler(c *gin.Context) {
+	if h.jobs == nil {
+		jobError(c, jobs.ErrNotFound)
+		return
+	}
+	job, err := h.jobs.Cancel(c.Param("id"))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	logger(c).Info("job canceled", "job", job.ID, "status", job.Status)
+	c.JSON(http.StatusOK, jobResponse(job))
+}
+
+func (h *Handler) getJob(id string) (jobs.Job, error) {
+	if h.jobs == nil {
+		return jobs.Job{}, jobs.ErrNotFound
+	}
+	return h.jobs.Get(id)
+}
+
+// jobError writes the error response for a jobs endpoint.
+func jobError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, jobs.ErrNotReady):
+		status = http.StatusConflict
+	}
+	c.JSON(status, design.ErrorResponse{Error: err.Error()})
+}
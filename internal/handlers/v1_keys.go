@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// NewAPIKey is a created API key with the key itself.
+type NewAPIKey struct {
+	store.APIKey
+	Key string `json:"key" doc:"Sent as X-API-Key: <key> or Authorization: Bearer <key>. It is not shown again."`
+}
+
+// KeyUsage sums up the use of an API key over a period.
+type KeyUsage struct {
+	Key       store.APIKey     `json:"key"`
+	Since     string           `json:"since" doc:"First day counted, as YYYY-MM-DD."`
+	Requests  int              `json:"requests"`
+	Units     float64          `json:"units"`
+	Today     float64          `json:"today" doc:"Units used today (UTC)."`
+	Remaining *float64         `json:"remaining,omitempty" doc:"Units left of today's quota; absent for keys without a quota."`
+	Days      []store.UsageDay `json:"days" doc:"Counters per day; days without use are left out."`
+}
+
+// apiKeyContextKey is the context key of the API key of the request.
+const apiKeyContextKey = "qrcreator.apikey"
+
+// requestAPIKey returns the API key the request carries in X-API-Key, or
+// as a Bearer token starting with qrk_, and keeps it in the context.
+// Other Bearer tokens are owner keys of dynamic codes and are left alone.
+// Without a key it returns nil; with an unknown one it answers 401 and
+// returns false.
+func (h *Handler) requestAPIKey(c *gin.Context) (*store.APIKey, bool) {
+	if v, ok := c.Get(apiKeyContextKey); ok {
+		return v.(*store.APIKey), true
+	}
+	raw := c.GetHeader("X-API-Key")
+	if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); raw == "" && ok && strings.HasPrefix(bearer, store.APIKeyPrefix) {
+		raw = bearer
+	}
+	if raw == "" {
+		return nil, true
+	}
+	key, err := h.store.Authenticate(raw)
+	if errors.Is(err, store.ErrNotFound) {
+		failReason(c, reasonInvalidAPIKey)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, design.ErrorResponse{Error: "invalid API key"})
+		return nil, false
+	}
+	if err != nil {
+		c.Abort()
+		apiKeyError(c, err)
+		return nil, false
+	}
+	c.Set(apiKeyContextKey, &key)
+	return &key, true
+}
+
+// Scope returns middleware that refuses requests whose API key lacks
+// scope. Requests without a key, like those of the web UI, pass.
+func (h *Handler) Scope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, ok := h.requestAPIKey(c)
+		if ok && key != nil && !key.HasScope(scope) {
+			failReason(c, reasonScope)
+			c.AbortWithStatusJSON(http.StatusForbidden, design.ErrorResponse{Error: fmt.Sprintf("API key lacks the %s scope", scope)})
+		}
+	}
+}
+
+// RequireAdmin returns middleware that lets through requests carrying the
+// admin token as a Bearer token. Without a configured token the routes it
+// guards are disabled.
+func (h *Handler) RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.adminToken == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, design.ErrorResponse{Error: "API key management is disabled: set ADMIN_TOKEN"})
+			return
+		}
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, design.ErrorResponse{Error: "admin token required: send Authorization: Bearer <token>"})
+		}
+	}
+}
+
+// APIKeysHandler lists the API keys
+func (h *Handler) APIKeysHandler(c *gin.Context) {
+	keys, err := h.store.APIKeys()
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// APIKeyHandler returns one API key
+func (h *Handler) APIKeyHandler(c *gin.Context) {
+	k, err := h.store.APIKey(c.Param("id"))
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, k)
+}
+
+// CreateAPIKeyHandler creates an API key and returns it with the key,
+// which is not shown again
+func (h *Handler) CreateAPIKeyHandler(c *gin.Context) {
+	var doc design.APIKey
+	if err := design.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxDesignBytes), &doc); err != nil {
+		v1Error(c, err)
+		return
+	}
+	k, key, err := h.store.CreateAPIKey(doc)
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	logger(c).Info("API key created", "key", k.ID, "name", k.Name, "scopes", k.Scopes)
+	c.Header("Location", "/api/v1/keys/"+k.ID)
+	c.JSON(http.StatusCreated, NewAPIKey{APIKey: k, Key: key})
+}
+
+// UpdateAPIKeyHandler replaces the name, scopes and quota of an API key
+func (h *Handler) UpdateAPIKeyHandler(c *gin.Context) {
+	var doc design.APIKey
+	if err := design.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxDesignBytes), &doc); err != nil {
+		v1Error(c, err)
+		return
+	}
+	k, err := h.store.UpdateAPIKey(c.Param("id"), doc)
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	logger(c).Info("API key updated", "key", k.ID, "name", k.Name, "scopes", k.Scopes)
+	c.JSON(http.StatusOK, k)
+}
+
+// DeleteAPIKeyHandler deletes an API key, which stops working at once
+func (h *Handler) DeleteAPIKeyHandler(c *gin.Context) {
+	if err := h.store.DeleteAPIKey(c.Param("id")); err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	logger(c).Info("API key deleted", "key", c.Param("id"))
+	c.Status(http.StatusNoContent)
+}
+
+// APIKeyUsageHandler returns the usage of any API key
+func (h *Handler) APIKeyUsageHandler(c *gin.Context) {
+	k, err := h.store.APIKey(c.Param("id"))
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	h.writeUsage(c, k)
+}
+
+// UsageHandler returns the usage of the API key the request carries
+func (h *Handler) UsageHandler(c *gin.Context) {
+	k, ok := h.requestAPIKey(c)
+	if !ok {
+		return
+	}
+	if k == nil {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.JSON(http.StatusUnauthorized, design.ErrorResponse{Error: "API key required: send X-API-Key: <key>"})
+		return
+	}
+	h.writeUsage(c, *k)
+}
+
+// writeUsage sums up the usage of k over the days asked for.
+func (h *Handler) writeUsage(c *gin.Context, k store.APIKey) {
+	days, ok := queryDays(c)
+	if !ok {
+		return
+	}
+	now := h.clock().UTC()
+	since := now.AddDate(0, 0, 1-days)
+	usage, err := h.store.Usage(k.ID, since)
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	u := KeyUsage{Key: k, Since: since.Format(time.DateOnly), Days: usage}
+	for _, d := range usage {
+		u.Requests += d.Requests
+		u.Units += d.Units
+		if d.Day == now.Format(time.DateOnly) {
+			u.Today = d.Units
+		}
+	}
+	if k.DailyQuota > 0 {
+		remaining := max(0, float64(k.DailyQuota)-u.Today)
+		u.Remaining = &remaining
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// apiKeyError writes the error response of an API keys endpoint.
+func apiKeyError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, design.ErrorResponse{Error: "API key not found"})
+		return
+	}
+	logger(c).Error("API key store error", "err", err)
+	c.JSON(http.StatusInternalServerError, design.ErrorResponse{Error: "API key store error"})
+}
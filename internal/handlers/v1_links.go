@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// LinkResponse is a dynamic code as returned by the links endpoints.
+type LinkResponse struct {
+	store.Link
+	ShortURL     string `json:"shortUrl" doc:"URL to encode in the QR code."`
+	QRURL        string `json:"qrUrl" doc:"Image of the QR code of the short URL."`
+	DashboardURL string `json:"dashboardUrl" doc:"Page showing the scan counts; it asks for the owner key."`
+	Key          string `json:"key,omitempty" doc:"Owner key needed to edit the code, sent as Authorization: Bearer <key>. Returned only when the code is created."`
+}
+
+func linkResponse(c *gin.Context, l store.Link) LinkResponse {
+	short := requestBaseURL(c) + "/r/" + l.Slug
+	return LinkResponse{Link: l, ShortURL: short, QRURL: "/api/qr?size=download&url=" + url.QueryEscape(short), DashboardURL: "/links/" + l.Slug}
+}
+
+// CreateLinkHandler creates a dynamic code and returns it with its owner
+// key, which is not shown again
+func (h *Handler) CreateLinkHandler(c *gin.Context) {
+	var doc design.NewLink
+	if err := design.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxDesignBytes), &doc); err != nil {
+		v1Error(c, err)
+		return
+	}
+	if err := h.normalizeLink(c, &doc.Link); err != nil {
+		v1Error(c, err)
+		return
+	}
+	l, key, err := h.store.CreateLink(doc.Slug, doc.Link)
+	if errors.Is(err, store.ErrExists) {
+		c.JSON(http.StatusConflict, design.ErrorResponse{Error: fmt.Sprintf("slug %q is taken", doc.Slug)})
+		return
+	}
+	if err != nil {
+		linkError(c, err)
+		return
+	}
+	logger(c).Info("link created", "slug", l.Slug)
+	resp := linkResponse(c, l)
+	resp.Key = key
+	c.Header("Location", "/api/v1/links/"+l.Slug)
+	c.JSON(http.StatusCreated, resp)
+}
+
+// LinkHandler returns a dynamic code to its owner
+func (h *Handler) LinkHandler(c *gin.Context) {
+	l, ok := h.ownedLink(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, linkResponse(c, l))
+}
+
+// UpdateLinkHandler replaces the target, rules, limits and passcode of a
+// dynamic code
+func (h *Handler) UpdateLinkHandler(c *gin.Context) {
+	if _, ok := h.ownedLink(c); !ok {
+		return
+	}
+	var doc design.Link
+	if err := design.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxDesignBytes), &doc); err != nil {
+		v1Error(c, err)
+		return
+	}
+	if err := h.normalizeLink(c, &doc); err != nil {
+		v1Error(c, err)
+		return
+	}
+	l, err := h.store.UpdateLink(c.Param("slug"), doc)
+	if err != nil {
+		linkError(c, err)
+		return
+	}
+	logger(c).Info("link updated", "slug", l.Slug, "paused", l.Paused)
+	c.JSON(http.StatusOK, linkResponse(c, l))
+}
+
+// DeleteLinkHandler deletes a dynamic code; its short URL stops working
+func (h *Handler) DeleteLinkHandler(c *gin.Context) {
+	if _, ok := h.ownedLink(c); !ok {
+		return
+	}
+	if err := h.store.DeleteLink(c.Param("slug")); err != nil {
+		linkError(c, err)
+		return
+	}
+	logger(c).Info("link deleted", "slug", c.Param("slug"))
+	c.Status(http.StatusNoContent)
+}
+
+// ownedLink loads the link of the request and checks that the request
+// carries its owner key. It writes the error response when it fails.
+func (h *Handler) ownedLink(c *gin.Context) (store.Link, bool) {
+	l, err := h.store.Link(c.Param("slug"))
+	if err != nil {
+		linkError(c, err)
+		return store.Link{}, false
+	}
+	key, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || key == "" {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.JSON(http.StatusUnauthorized, design.ErrorResponse{Error: "owner key required: send Authorization: Bearer <key>"})
+		return store.Link{}, false
+	}
+	if !l.Owns(key) {
+		c.JSON(http.StatusForbidden, design.ErrorResponse{Error: "wrong owner key"})
+		return store.Link{}, false
+	}
+	return l, true
+}
+
+// normalizeLink checks the targets of a link and its rules and cleans them
+// up the way GET /api/qr does. The campaign template is read now, so later
+// edits of the template do not change the link.
+func (h *Handler) normalizeLink(c *gin.Context, doc *design.Link) error {
+	if err := h.fillCampaign(doc.Campaign); err != nil {
+		return design.PrefixFields(err, "campaign")
+	}
+	var errs []design.FieldError
+	normalize := func(field string, target *string) {
+		v, err := h.normalizeHTTPURL(*target)
+		if err == nil {
+			v, err = h.checkURL(c, v)
+		}
+		if err != nil {
+			errs = append(errs, design.FieldError{Field: field, Message: err.Error()})
+			return
+		}
+		*target = v
+	}
+	normalize("target", &doc.Target)
+	for i := range doc.Rules {
+		r := &doc.Rules[i]
+		if r.Target != "" {
+			normalize(fmt.Sprintf("rules[%d].target", i), &r.Target)
+		}
+		for j := range r.Split {
+			normalize(fmt.Sprintf("rules[%d].split[%d].target", i, j), &r.Split[j].Target)
+		}
+	}
+	if len(errs) > 0 {
+		return &design.ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// linkError writes the error response of a links endpoint.
+func linkError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, design.ErrorResponse{Error: "link not found"})
+		return
+	}
+	logger(c).Error("link store error", "err", err)
+	c.JSON(http.StatusInternalServerError, design.ErrorResponse{Error: "link store error"})
+}
+
+// requestBaseURL returns the scheme and host the request was made to.
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if xf := c.GetHeader("X-Forwarded-Proto"); xf != "" {
+		scheme = xf
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// NewPreset is a created preset with its owner key.
+type NewPreset struct {
+	store.Preset
+	Key string `json:"key" doc:"Owner key needed to replace or delete the preset, sent as Authorization: Bearer <key>. It is not shown again."`
+}
+
+// PresetsHandler lists the saved presets
+func (h *Handler) PresetsHandler(c *gin.Context) {
+	presets, err := h.store.Presets()
+	if err != nil {
+		presetError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, presets)
+}
+
+// PresetHandler returns one preset
+func (h *Handler) PresetHandler(c *gin.Context) {
+	p, err := h.store.Preset(c.Param("id"))
+	if err != nil {
+		presetError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// CreatePresetHandler saves a new preset and returns it with its owner
+// key, which is not shown again
+func (h *Handler) CreatePresetHandler(c *gin.Context) {
+	doc, err := h.readPreset(c)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	p, key, err := h.store.CreatePreset(*doc)
+	if err != nil {
+		presetError(c, err)
+		return
+	}
+	logger(c).Info("preset created", "preset", p.ID, "name", p.Name, "kind", p.Kind)
+	c.Header("Location", "/api/v1/presets/"+p.ID)
+	c.JSON(http.StatusCreated, NewPreset{Preset: p, Key: key})
+}
+
+// UpdatePresetHandler replaces the document of a preset for its owner
+func (h *Handler) UpdatePresetHandler(c *gin.Context) {
+	if _, ok := h.ownedPreset(c); !ok {
+		return
+	}
+	doc, err := h.readPreset(c)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	p, err := h.store.UpdatePreset(c.Param("id"), *doc)
+	if err != nil {
+		presetError(c, err)
+		return
+	}
+	logger(c).Info("preset updated", "preset", p.ID, "name", p.Name)
+	c.JSON(http.StatusOK, p)
+}
+
+// DeletePresetHandler deletes a preset for its owner
+func (h *Handler) DeletePresetHandler(c *gin.Context) {
+	if _, ok := h.ownedPreset(c); !ok {
+		return
+	}
+	if err := h.store.DeletePreset(c.Param("id")); err != nil {
+		presetError(c, err)
+		return
+	}
+	logger(c).Info("preset deleted", "preset", c.Param("id"))
+	c.Status(http.StatusNoContent)
+}
+
+// ownedPreset loads the preset of the request and checks that the request
+// carries its owner key or the admin token, which can also manage presets
+// saved before owner keys. It writes the error response when it fails.
+func (h *Handler) ownedPreset(c *gin.Context) (store.Preset, bool) {
+	p, err := h.store.Preset(c.Param("id"))
+	if err != nil {
+		presetError(c, err)
+		return store.Preset{}, false
+	}
+	key, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || key == "" {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.JSON(http.StatusUnauthorized, design.ErrorResponse{Error: "owner key required: send Authorization: Bearer <key>"})
+		return store.Preset{}, false
+	}
+	admin := h.adminToken != "" && subtle.ConstantTimeCompare([]byte(key), []byte(h.adminToken)) == 1
+	if !admin && !p.Owns(key) {
+		c.JSON(http.StatusForbidden, design.ErrorResponse{Error: "wrong owner key"})
+		return store.Preset{}, false
+	}
+	return p, true
+}
+
+// readPreset decodes and validates a preset document.
+func (h *Handler) readPreset(c *gin.Context) (*design.Preset, error) {
+	var doc design.Preset
+	if err := design.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxDesignBytes), &doc); err != nil {
+		return nil, err
+	}
+	if err := h.checkLogo(doc.Design.Logo); err != nil {
+		return nil, design.PrefixFields(err, "design")
+	}
+	return &doc, nil
+}
+
+// presetQuery returns the query of the request laid over the style of the
+// preset it names in preset, so that single parameters override the
+// preset. Without a preset it returns the request itself.
+func (h *Handler) presetQuery(c *gin.Context) (queryReader, error) {
+	id := c.Query("preset")
+	if id == "" {
+		return c, nil
+	}
+	p, err := h.store.Preset(id)
+	if err != nil {
+		return nil, err
+	}
+	q := p.Design.Query()
+	for key, values := range c.Request.URL.Query() {
+		q[key] = values
+	}
+	return valuesQuery(url.Values(q)), nil
+}
+
+// presetError writes the error response of a presets endpoint.
+func presetError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, design.ErrorResponse{Error: "preset not found"})
+		return
+	}
+	logger(c).Error("preset store error", "err", err)
+	c.JSON(http.StatusInternalServerError, design.ErrorResponse{Error: "preset store error"})
+}
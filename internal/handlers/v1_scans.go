@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/analytics"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/redirect"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/store"
+	"github.com/cristianadrielbraun/qrcreator.link/web/pages"
+	"github.com/gin-gonic/gin"
+)
+
+// ScanStats sums up the scans of a dynamic code over a period.
+type ScanStats struct {
+	Slug      string          `json:"slug"`
+	Since     string          `json:"since" doc:"First day counted, as YYYY-MM-DD."`
+	Total     int             `json:"total"`
+	Devices   map[string]int  `json:"devices"`
+	Countries map[string]int  `json:"countries"`
+	Days      []store.ScanDay `json:"days" doc:"Counters per day; days without scans are left out."`
+}
+
+// recordScan counts a scan of a dynamic code. The client address is only
+// used to look up its country and is never stored.
+func (h *Handler) recordScan(c *gin.Context, slug string, scan redirect.Scan) {
+	addr, _ := netip.ParseAddr(c.ClientIP())
+	country := h.geo.Country(addr)
+	if err := h.store.RecordScan(slug, scan.Time, scan.Device, country); err != nil {
+		logger(c).Warn("failed to record scan", "slug", slug, "err", err)
+	}
+}
+
+// ScansHandler returns the scan counts of a dynamic code to its owner, as
+// JSON or, with format=csv, as a CSV file with a row per day
+func (h *Handler) ScansHandler(c *gin.Context) {
+	l, ok := h.ownedLink(c)
+	if !ok {
+		return
+	}
+	days, ok := queryDays(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, design.ErrorResponse{Error: "format must be json or csv"})
+		return
+	}
+
+	since := h.clock().UTC().AddDate(0, 0, 1-days)
+	scans, err := h.store.Scans(l.Slug, since)
+	if err != nil {
+		linkError(c, err)
+		return
+	}
+	stats := ScanStats{Slug: l.Slug, Since: since.Format(time.DateOnly), Devices: map[string]int{}, Countries: map[string]int{}, Days: scans}
+	for _, d := range scans {
+		stats.Total += d.Total
+		for k, n := range d.Devices {
+			stats.Devices[k] += n
+		}
+		for k, n := range d.Countries {
+			stats.Countries[k] += n
+		}
+	}
+	if format == "csv" {
+		writeScansCSV(c, stats)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// queryDays reads the days parameter of a report, 30 by default. It
+// answers 400 and returns false when it is invalid.
+func queryDays(c *gin.Context) (int, bool) {
+	v := c.Query("days")
+	if v == "" {
+		return 30, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > 366 {
+		c.JSON(http.StatusBadRequest, design.ErrorResponse{Error: "days must be a number from 1 to 366"})
+		return 0, false
+	}
+	return n, true
+}
+
+// writeScansCSV writes the days of stats with a column per device class
+// and per country seen.
+func writeScansCSV(c *gin.Context, stats ScanStats) {
+	countries := slices.Sorted(maps.Keys(stats.Countries))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="scans-%s.csv"`, stats.Slug))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	header := append([]string{"day", "total"}, analytics.Devices...)
+	for _, cc := range countries {
+		header = append(header, "country_"+cc)
+	}
+	w.Write(header)
+	for _, d := range stats.Days {
+		row := []string{d.Day, strconv.Itoa(d.Total)}
+		for _, dev := range analytics.Devices {
+			row = append(row, strconv.Itoa(d.Devices[dev]))
+		}
+		for _, cc := range countries {
+			row = append(row, strconv.Itoa(d.Countries[cc]))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+}
+
+// LinkDashboardPage serves the scan dashboard of a dynamic code. The page
+// asks for the owner key and loads the counts from the API.
+func (h *Handler) LinkDashboardPage(c *gin.Context) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := pages.LinkDashboardPage(c.Param("slug")).Render(c.Request.Context(), c.Writer); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+	}
+}
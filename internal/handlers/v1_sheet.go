@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/jobs"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/qr"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/sheet"
+	"github.com/gin-gonic/gin"
+)
+
+// sheetLabel is a label whose code is encoded and ready to render.
+type sheetLabel struct {
+	code    *qr.Code
+	st      qrStyle
+	caption string
+}
+
+// SheetTemplatesHandler lists the bundled label templates
+func (h *Handler) SheetTemplatesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, sheet.Catalog)
+}
+
+// SheetHandler lays codes out on label stock and returns a PDF. Every label
+// is encoded before anything is rendered, so a label that cannot be drawn
+// rejects the request with its field errors. With async=true the sheet is
+// rendered as a job instead.
+func (h *Handler) SheetHandler(c *gin.Context) {
+	s, err := readSheet(c)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	if err := h.fillCampaign(s.Campaign); err != nil {
+		v1Error(c, design.PrefixFields(err, "campaign"))
+		return
+	}
+	labels, err := h.prepareSheet(s)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	// One unit per label; the middleware took one for the request
+	if !h.charge(c, float64(len(labels)-1)) {
+		return
+	}
+	t := s.LabelTemplate()
+	logger(c).Info("sheet request", "template", t.Name, "labels", len(labels), "copies", max(s.Copies, 1), "async", c.Query("async") == "true")
+
+	if c.Query("async") == "true" {
+		h.submitJob(c, jobs.Task{
+			Kind:        "sheet",
+			Total:       len(labels),
+			Filename:    "labels.pdf",
+			ContentType: "application/pdf",
+			Run: func(ctx context.Context, w io.Writer, progress func(int)) error {
+				return h.writeSheetPDF(ctx, w, s, labels, progress)
+			},
+		})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.writeSheetPDF(c.Request.Context(), &buf, s, labels, func(int) {}); err != nil {
+		c.JSON(http.StatusInternalServerError, design.ErrorResponse{Error: fmt.Sprintf("Failed to generate PDF: %v", err)})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="labels.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// readSheet reads a sheet from a JSON body or from a multipart form with a
+// CSV file in rows, a sheet document without labels in sheet, and an
+// optional caption field.
+func readSheet(c *gin.Context) (*design.Sheet, error) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBytes)
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		var s design.Sheet
+		if err := design.Decode(body, &s); err != nil {
+			return nil, err
+		}
+		return &s, nil
+	}
+
+	c.Request.Body = body
+	rows, err := formCSVRows(c)
+	if err != nil {
+		return nil, err
+	}
+	// The labels come from the CSV; the sheet field sets everything else.
+	s := design.Sheet{Labels: design.SheetLabels(rows)}
+	if v := c.PostForm("sheet"); v != "" {
+		if err := design.Decode(strings.NewReader(v), &s); err != nil {
+			return nil, design.PrefixFields(err, "sheet")
+		}
+	}
+	if v := c.PostForm("caption"); v != "" {
+		s.Caption = v
+	}
+	if err := design.Check(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// prepareSheet encodes every label of a validated sheet.
+func (h *Handler) prepareSheet(s *design.Sheet) ([]sheetLabel, error) {
+	labels := make([]sheetLabel, len(s.Labels))
+	for i, label := range s.Labels {
+		prefix := fmt.Sprintf("labels[%d]", i)
+		if err := design.Check(&label); err != nil {
+			return nil, design.PrefixFields(err, prefix)
+		}
+		d, err := s.LabelDesign(i, label)
+		if err != nil {
+			return nil, design.PrefixFields(err, prefix)
+		}
+		payload, st, opts, _, err := h.prepareDesign(d)
+		if err != nil {
+			return nil, design.PrefixFields(err, prefix)
+		}
+		code, err := qr.Encode(payload, opts)
+		if err != nil {
+			return nil, &design.ValidationError{Fields: []design.FieldError{{Field: prefix + ".content", Message: err.Error()}}}
+		}
+		// Codes are embedded at their natural preview resolution, 16
+		// pixels per module, which stays sharp at label sizes.
+		st.size = "preview"
+		st.previewSize = 0
+		labels[i] = sheetLabel{code: code, st: st, caption: s.LabelCaption(i, label)}
+	}
+	return labels, nil
+}
+
+// writeSheetPDF renders the labels and writes the sheet PDF to w,
+// reporting the number of labels rendered after each one.
+func (h *Handler) writeSheetPDF(ctx context.Context, w io.Writer, s *design.Sheet, labels []sheetLabel, progress func(done int)) error {
+	files := make([]string, 0, len(labels))
+	defer func() {
+		for _, f := range files {
+			os.Remove(f)
+		}
+	}()
+
+	copies := max(s.Copies, 1)
+	placed := make([]sheet.Label, 0, len(labels)*copies)
+	for i, l := range labels {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := h.renderSlots.Acquire(ctx); err != nil {
+			return err
+		}
+		f, err := h.renderPNGFile(ctx, l.code.Bitmap(), l.st)
+		h.renderSlots.Release()
+		if err != nil {
+			return fmt.Errorf("label %d: %v", i+1, err)
+		}
+		files = append(files, f)
+		for range copies {
+			placed = append(placed, sheet.Label{Image: f, Caption: l.caption, Background: l.st.bgColor, Color: l.st.fgColor})
+		}
+		progress(i + 1)
+	}
+
+	padding := 2.0
+	if s.Padding != nil {
+		padding = *s.Padding
+	}
+	return sheet.Render(w, s.LabelTemplate(), placed, sheet.Options{
+		Padding:  padding,
+		Bleed:    s.Bleed,
+		FontSize: s.FontSize,
+		CutLines: s.CutLines,
+		Skip:     s.Skip,
+	})
+}
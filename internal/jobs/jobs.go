@@ -0,0 +1,431 @@
+// Package jobs runs long renders (large batches, print sheets) in the
+// background. Each job writes one artifact file; its state and artifact
+// are kept in a directory so finished jobs survive a restart until they
+// expire.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Status is the lifecycle stage of a job.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// Done reports whether the job has finished, successfully or not.
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCanceled
+}
+
+// ErrNotFound is returned for unknown or expired jobs.
+var ErrNotFound = errors.New("job not found")
+
+// ErrNotReady is returned when the artifact of an unfinished or failed job
+// is requested.
+var ErrNotReady = errors.New("job has no artifact")
+
+// ErrShutdown is returned by Submit once Shutdown was called.
+var ErrShutdown = errors.New("job manager is shutting down")
+
+// Job is the state of a job as reported to clients and saved to disk.
+type Job struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`
+	Status      Status     `json:"status" enum:"queued,running,succeeded,failed,canceled"`
+	Done        int        `json:"done"`
+	Total       int        `json:"total"`
+	Error       string     `json:"error,omitempty"`
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"contentType"`
+	Size        int64      `json:"size,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Task describes the work of a job.
+type Task struct {
+	Kind        string // e.g. "batch" or "sheet"
+	Total       int    // units of progress, e.g. rows
+	Filename    string // suggested download name of the artifact
+	ContentType string
+	// Run writes the artifact to w, calling progress with the number of
+	// units done so far. It must return promptly once ctx is canceled.
+	Run func(ctx context.Context, w io.Writer, progress func(done int)) error
+}
+
+// Options configures a Manager.
+type Options struct {
+	Dir         string        // where state and artifacts are kept
+	TTL         time.Duration // how long finished jobs are kept
+	Concurrency int           // jobs running at once
+	// Now returns the current time; nil uses time.Now.
+	Now func() time.Time
+}
+
+// Manager queues, runs and keeps track of jobs.
+type Manager struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+	sem chan struct{}
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	cancels map[string]context.CancelFunc
+	running sync.WaitGroup // queued and running jobs
+	closed  bool           // set by Shutdown
+}
+
+// NewManager creates the job directory if needed and loads the jobs saved
+// there. Jobs that were still queued or running when the process stopped
+// are marked as failed.
+func NewManager(opts Options) (*Manager, error) {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating job directory: %w", err)
+	}
+	m := &Manager{
+		dir:     opts.Dir,
+		ttl:     opts.TTL,
+		now:     opts.Now,
+		sem:     make(chan struct{}, opts.Concurrency),
+		jobs:    map[string]*Job{},
+		cancels: map[string]context.CancelFunc{},
+	}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	m.Sweep()
+	return m, nil
+}
+
+// load reads the saved job states and removes the partial artifacts of
+// jobs that were running when the process stopped.
+func (m *Manager) load() error {
+	parts, err := filepath.Glob(filepath.Join(m.dir, "*.part"))
+	if err != nil {
+		return err
+	}
+	for _, path := range parts {
+		os.Remove(path)
+	}
+	states, err := filepath.Glob(filepath.Join(m.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range states {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading job state: %w", err)
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil || job.ID == "" {
+			slog.Warn("skipping unreadable job state", "path", path, "err", err)
+			continue
+		}
+		if !job.Status.Done() {
+			m.finish(&job, StatusFailed, "interrupted by a server restart")
+			m.save(&job)
+		}
+		if job.Status == StatusSucceeded {
+			if _, err := os.Stat(m.artifactPath(job.ID)); err != nil {
+				continue
+			}
+		}
+		m.jobs[job.ID] = &job
+	}
+	return nil
+}
+
+// Submit queues a task and returns its job. The task starts as soon as
+// fewer than the configured number of jobs are running. After Shutdown it
+// returns ErrShutdown.
+func (m *Manager) Submit(task Task) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+	job := &Job{
+		ID:          id,
+		Kind:        task.Kind,
+		Status:      StatusQueued,
+		Total:       task.Total,
+		Filename:    task.Filename,
+		ContentType: task.ContentType,
+		CreatedAt:   m.now().UTC(),
+	}
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return Job{}, ErrShutdown
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.jobs[id] = job
+	m.cancels[id] = cancel
+	m.save(job)
+	snapshot := *job
+	// Added under the lock, so Shutdown cannot be waiting already
+	m.running.Add(1)
+	m.mu.Unlock()
+
+	go m.run(ctx, job, task)
+	return snapshot, nil
+}
+
+// run waits for a slot, runs the task into a temporary file and moves it
+// into place when it succeeds.
+func (m *Manager) run(ctx context.Context, job *Job, task Task) {
+	defer m.running.Done()
+	defer m.dropCancel(job.ID)
+	select {
+	case m.sem <- struct{}{}:
+		defer func() { <-m.sem }()
+	case <-ctx.Done():
+		m.complete(job, StatusCanceled, "", 0)
+		return
+	}
+
+	m.mu.Lock()
+	if job.Status != StatusQueued {
+		m.mu.Unlock()
+		return
+	}
+	job.Status = StatusRunning
+	m.save(job)
+	m.mu.Unlock()
+
+	tmp, err := os.CreateTemp(m.dir, job.ID+"-*.part")
+	if err != nil {
+		m.complete(job, StatusFailed, err.Error(), 0)
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	progress := func(done int) {
+		m.mu.Lock()
+		job.Done = done
+		m.mu.Unlock()
+	}
+	err = task.Run(ctx, tmp, progress)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	switch {
+	case ctx.Err() != nil:
+		m.complete(job, StatusCanceled, "", 0)
+	case err != nil:
+		m.complete(job, StatusFailed, err.Error(), 0)
+	default:
+		info, err := os.Stat(tmp.Name())
+		if err == nil {
+			err = os.Rename(tmp.Name(), m.artifactPath(job.ID))
+		}
+		if err != nil {
+			m.complete(job, StatusFailed, err.Error(), 0)
+			return
+		}
+		m.complete(job, StatusSucceeded, "", info.Size())
+	}
+}
+
+// complete records the outcome of a job.
+func (m *Manager) complete(job *Job, status Status, msg string, size int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if job.Status.Done() {
+		return
+	}
+	if status == StatusSucceeded {
+		job.Done = job.Total
+	}
+	job.Size = size
+	m.finish(job, status, msg)
+	m.save(job)
+}
+
+// finish sets the final status and the expiry of a job.
+func (m *Manager) finish(job *Job, status Status, msg string) {
+	now := m.now().UTC()
+	expires := now.Add(m.ttl)
+	job.Status = status
+	job.Error = msg
+	job.FinishedAt = &now
+	job.ExpiresAt = &expires
+}
+
+func (m *Manager) dropCancel(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cancel, ok := m.cancels[id]; ok {
+		cancel()
+		delete(m.cancels, id)
+	}
+}
+
+// Get returns the current state of a job.
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok || m.expired(job) {
+		return Job{}, ErrNotFound
+	}
+	return *job, nil
+}
+
+// Cancel stops a queued or running job. Finished jobs are deleted along
+// with their artifact instead.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok || m.expired(job) {
+		m.mu.Unlock()
+		return Job{}, ErrNotFound
+	}
+	if job.Status.Done() {
+		m.remove(id)
+		snapshot := *job
+		m.mu.Unlock()
+		return snapshot, nil
+	}
+	if job.Status == StatusQueued {
+		m.finish(job, StatusCanceled, "")
+		m.save(job)
+	}
+	cancel := m.cancels[id]
+	snapshot := *job
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	return snapshot, nil
+}
+
+// Open returns the artifact of a succeeded job. The caller closes it.
+func (m *Manager) Open(id string) (*os.File, Job, error) {
+	job, err := m.Get(id)
+	if err != nil {
+		return nil, Job{}, err
+	}
+	if job.Status != StatusSucceeded {
+		return nil, job, ErrNotReady
+	}
+	f, err := os.Open(m.artifactPath(id))
+	if err != nil {
+		return nil, job, err
+	}
+	return f, job, nil
+}
+
+// Sweep deletes the jobs whose TTL has passed.
+func (m *Manager) Sweep() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, job := range m.jobs {
+		if m.expired(job) {
+			m.remove(id)
+		}
+	}
+}
+
+// Shutdown waits for the queued and running jobs to finish. If ctx is done
+// first, it cancels them, waits for them to stop and returns ctx's error.
+// Submit refuses jobs from then on.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	m.mu.Lock()
+	for _, cancel := range m.cancels {
+		cancel()
+	}
+	m.mu.Unlock()
+	<-done
+	return ctx.Err()
+}
+
+// Run sweeps expired jobs every interval until ctx is done.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			m.Sweep()
+		}
+	}
+}
+
+func (m *Manager) expired(job *Job) bool {
+	return job.ExpiresAt != nil && !m.now().Before(*job.ExpiresAt)
+}
+
+// remove deletes a job and its files. m.mu must be held.
+func (m *Manager) remove(id string) {
+	delete(m.jobs, id)
+	os.Remove(m.statePath(id))
+	os.Remove(m.artifactPath(id))
+}
+
+// save writes the state of a job atomically. m.mu must be held.
+func (m *Manager) save(job *Job) {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err == nil {
+		tmp := m.statePath(job.ID) + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o644); err == nil {
+			err = os.Rename(tmp, m.statePath(job.ID))
+		}
+	}
+	if err != nil {
+		slog.Warn("failed to save job", "job", job.ID, "err", err)
+	}
+}
+
+func (m *Manager) statePath(id string) string    { return filepath.Join(m.dir, id+".json") }
+func (m *Manager) artifactPath(id string) string { return filepath.Join(m.dir, id+".artifact") }
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
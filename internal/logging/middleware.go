@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID of a request, both ways.
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID accepts the IDs proxies and clients commonly send.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// Middleware returns gin middleware that gives each request an ID and a
+// logger carrying it, and logs the request once it is answered, with the
+// time spent in each stage. The ID is taken from X-Request-ID when the
+// client sends a sane one, and returned in it. Panics are logged with
+// their stack and answered 500.
+func Middleware(l *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		rl := l.With("request_id", id)
+		t := &timings{}
+		ctx := context.WithValue(NewContext(c.Request.Context(), rl), timingsKey{}, t)
+		c.Request = c.Request.WithContext(ctx)
+
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				rl.Error("panic", "err", fmt.Sprint(err), "stack", string(debug.Stack()))
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+			status := c.Writer.Status()
+			level := slog.LevelInfo
+			if status >= 500 {
+				level = slog.LevelError
+			}
+			attrs := []slog.Attr{
+				slog.String("method", c.Request.Method),
+				slog.String("route", c.FullPath()),
+			}
+			// The path only says more than the route when it has parameters
+			if path := c.Request.URL.Path; path != c.FullPath() {
+				attrs = append(attrs, slog.Any("path", Payload(path)))
+			}
+			if q := c.Request.URL.RawQuery; q != "" {
+				attrs = append(attrs, slog.Any("query", Payload(q)))
+			}
+			attrs = append(attrs,
+				slog.Int("status", status),
+				slog.Int("bytes", max(c.Writer.Size(), 0)),
+				slog.Float64("duration_ms", ms(time.Since(start))),
+				slog.Any("client", Payload(c.ClientIP())),
+			)
+			if stages := t.attrs(); len(stages) > 0 {
+				attrs = append(attrs, slog.Any("stages_ms", slog.GroupValue(stages...)))
+			}
+			if len(c.Errors) > 0 {
+				attrs = append(attrs, slog.String("errors", c.Errors.String()))
+			}
+			rl.LogAttrs(c.Request.Context(), level, "request", attrs...)
+		}()
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type timingsKey struct{}
+
+// timings sums the time a request spends in each stage, in the order the
+// stages first ran.
+type timings struct {
+	mu     sync.Mutex
+	names  []string
+	totals map[string]time.Duration
+}
+
+// Stage starts timing the named stage of the request of ctx and returns
+// the function that ends it. A stage that runs several times is summed.
+// Outside a request it does nothing.
+//
+//	defer logging.Stage(ctx, "render")()
+func Stage(ctx context.Context, name string) func() {
+	t, ok := ctx.Value(timingsKey{}).(*timings)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		t.add(name, time.Since(start))
+	}
+}
+
+func (t *timings) add(name string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.totals == nil {
+		t.totals = map[string]time.Duration{}
+	}
+	if _, ok := t.totals[name]; !ok {
+		t.names = append(t.names, name)
+	}
+	t.totals[name] += d
+}
+
+func (t *timings) attrs() []slog.Attr {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	attrs := make([]slog.Attr, 0, len(t.names))
+	for _, name := range t.names {
+		attrs = append(attrs, slog.Float64(name, ms(t.totals[name])))
+	}
+	return attrs
+}
+
+// ms returns d in milliseconds, to the microsecond.
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,280 @@
+// Package metrics keeps counters, gauges and histograms and writes them in
+// the Prometheus text format. It covers what the server exposes and no
+// more: metrics are registered once at start and label values are kept
+// few by the callers.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry holds metrics and writes them. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+type metric interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, old := range r.metrics {
+		if old.name() == m.name() {
+			panic("metrics: " + m.name() + " registered twice")
+		}
+	}
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteTo writes every metric in the Prometheus text format, sorted by
+// name.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	ms := slices.Clone(r.metrics)
+	r.mu.Unlock()
+	slices.SortFunc(ms, func(a, b metric) int { return strings.Compare(a.name(), b.name()) })
+
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, m := range ms {
+		m.write(bw)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP writes the metrics as a scrape response.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	r.WriteTo(w)
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// desc holds what every metric has: its name, help text, type and label
+// names.
+type desc struct {
+	metricName, help, kind string
+	labels                 []string
+}
+
+func (d *desc) name() string { return d.metricName }
+
+func (d *desc) header(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.metricName, escapeHelp(d.help), d.metricName, d.kind)
+}
+
+// key joins label values into a map key.
+func (d *desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s wants %d label values, got %d", d.metricName, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs formats the labels of key, plus extra ones, as {a="x",...},
+// or "" without any.
+func (d *desc) labelPairs(key string, extra ...string) string {
+	var pairs []string
+	if len(d.labels) > 0 {
+		for i, v := range strings.Split(key, "\xff") {
+			pairs = append(pairs, d.labels[i]+`="`+escapeValue(v)+`"`)
+		}
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeValue(extra[i+1])+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// CounterVec is a counter per combination of label values. A nil
+// CounterVec counts nothing.
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounter registers a counter with the given label names.
+func (r *Registry) NewCounter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{name, help, "counter", labels}, values: map[string]float64{}}
+	r.register(c)
+	return c
+}
+
+// Add adds v, which must not be negative, to the counter of the label
+// values.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if c == nil {
+		return
+	}
+	key := c.key(labelValues)
+	c.mu.Lock()
+	c.values[key] += v
+	c.mu.Unlock()
+}
+
+// Inc adds one to the counter of the label values.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.header(w)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.metricName, c.labelPairs(key), formatFloat(c.values[key]))
+	}
+}
+
+// funcMetric is a counter or gauge whose value is read when written.
+type funcMetric struct {
+	desc
+	value func() float64
+}
+
+// NewCounterFunc registers a counter read from value, which must never
+// decrease.
+func (r *Registry) NewCounterFunc(name, help string, value func() float64) {
+	r.register(&funcMetric{desc{name, help, "counter", nil}, value})
+}
+
+// NewGaugeFunc registers a gauge read from value.
+func (r *Registry) NewGaugeFunc(name, help string, value func() float64) {
+	r.register(&funcMetric{desc{name, help, "gauge", nil}, value})
+}
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	f.header(w)
+	fmt.Fprintf(w, "%s %s\n", f.metricName, formatFloat(f.value()))
+}
+
+// HistogramVec is a histogram per combination of label values. A nil
+// HistogramVec observes nothing.
+type HistogramVec struct {
+	desc
+	buckets []float64 // upper bounds, ascending, without +Inf
+	mu      sync.Mutex
+	values  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative; the last is +Inf
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds
+// and label names.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	b := slices.Clone(buckets)
+	slices.Sort(b)
+	h := &HistogramVec{desc: desc{name, help, "histogram", labels}, buckets: b, values: map[string]*histogram{}}
+	r.register(h)
+	return h
+}
+
+// Observe adds v to the histogram of the label values.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	if h == nil {
+		return
+	}
+	key := h.key(labelValues)
+	i, _ := slices.BinarySearch(h.buckets, v)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hist := h.values[key]
+	if hist == nil {
+		hist = &histogram{counts: make([]uint64, len(h.buckets)+1)}
+		h.values[key] = hist
+	}
+	hist.counts[i]++
+	hist.count++
+	hist.sum += v
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.header(w)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.values) {
+		hist := h.values[key]
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += hist.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, h.labelPairs(key, "le", formatFloat(le)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, h.labelPairs(key, "le", "+Inf"), hist.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.metricName, h.labelPairs(key), formatFloat(hist.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metricName, h.labelPairs(key), hist.count)
+	}
+}
+
+// ExponentialBuckets returns n bucket bounds starting at start, each
+// factor times the previous.
+func ExponentialBuckets(start, factor float64, n int) []float64 {
+	b := make([]float64, n)
+	for i := range b {
+		b[i] = start
+		start *= factor
+	}
+	return b
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	valueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeValue(s string) string { return valueEscaper.Replace(s) }
@@ -0,0 +1,295 @@
+package qr
+
+// matrix is a symbol under construction. function marks modules that belong
+// to function patterns and must not be used for data or masked.
+type matrix struct {
+	width, height int
+	modules       [][]bool
+	function      [][]bool
+}
+
+func newGrid(width, height int) *matrix {
+	m := &matrix{width: width, height: height, modules: make([][]bool, height), function: make([][]bool, height)}
+	for y := 0; y < height; y++ {
+		m.modules[y] = make([]bool, width)
+		m.function[y] = make([]bool, width)
+	}
+	return m
+}
+
+// newMatrix returns an empty QR Code symbol of the given version.
+func newMatrix(version int) *matrix {
+	size := version*4 + 17
+	return newGrid(size, size)
+}
+
+// version returns the QR Code version of a square symbol.
+func (m *matrix) version() int { return (m.width - 17) / 4 }
+
+// setFunction sets a function module at (x, y).
+func (m *matrix) setFunction(x, y int, dark bool) {
+	m.modules[y][x] = dark
+	m.function[y][x] = true
+}
+
+// drawFunctionPatterns draws finder, timing and alignment patterns and
+// reserves the format and version information areas.
+func (m *matrix) drawFunctionPatterns() {
+	for i := 0; i < m.width; i++ {
+		m.setFunction(6, i, i%2 == 0)
+		m.setFunction(i, 6, i%2 == 0)
+	}
+
+	m.drawFinderPattern(3, 3)
+	m.drawFinderPattern(m.width-4, 3)
+	m.drawFinderPattern(3, m.width-4)
+
+	pos := alignmentPatternPositions(m.version())
+	last := len(pos) - 1
+	for i := range pos {
+		for j := range pos {
+			// Skip the three corners occupied by finder patterns.
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			m.drawAlignmentPattern(pos[i], pos[j])
+		}
+	}
+
+	// Reserve the format areas with placeholder bits; drawFormatBits
+	// overwrites them once the mask is known.
+	m.drawFormatBits(ECLevelL, 0)
+	m.drawVersion()
+}
+
+// drawFinderPattern draws a finder pattern and its separator centered at (x, y).
+func (m *matrix) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= m.width || yy < 0 || yy >= m.height {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			m.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignmentPattern draws a 5x5 alignment pattern centered at (x, y).
+func (m *matrix) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits writes both copies of the format information.
+func (m *matrix) drawFormatBits(level ECLevel, mask int) {
+	data := level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>uint(i))&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		m.setFunction(8, i, bit(i))
+	}
+	m.setFunction(8, 7, bit(6))
+	m.setFunction(8, 8, bit(7))
+	m.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		m.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		m.setFunction(m.width-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.setFunction(8, m.width-15+i, bit(i))
+	}
+	m.setFunction(8, m.width-8, true) // dark module
+}
+
+// drawVersion writes both copies of the version information (version 7+).
+func (m *matrix) drawVersion() {
+	version := m.version()
+	if version < 7 {
+		return
+	}
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := (bits>>uint(i))&1 != 0
+		a, b := m.width-11+i%3, i/3
+		m.setFunction(a, b, dark)
+		m.setFunction(b, a, dark)
+	}
+}
+
+// placeBits writes bits into the non-function modules in the zigzag order:
+// two-module columns from startCol leftwards, the first going upward and
+// then alternating. Column skipCol (the QR vertical timing pattern) is
+// stepped over; pass -1 when there is none.
+func (m *matrix) placeBits(bits bitBuffer, startCol, skipCol int) {
+	i := 0
+	upward := true
+	for right := startCol; right >= 1; right -= 2 {
+		if right == skipCol {
+			right--
+		}
+		for vert := 0; vert < m.height; vert++ {
+			y := vert
+			if upward {
+				y = m.height - 1 - vert
+			}
+			for x := right; x > right-2; x-- {
+				if !m.function[y][x] && i < len(bits) {
+					m.modules[y][x] = bits[i] != 0
+					i++
+				}
+			}
+		}
+		upward = !upward
+	}
+}
+
+// maskBit reports whether mask pattern mask inverts the module at (x, y).
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	case 7:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+	return false
+}
+
+// applyMask XORs the data modules with the mask pattern. Applying the same
+// mask twice restores the original modules.
+func (m *matrix) applyMask(mask int) {
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			if !m.function[y][x] && maskBit(mask, x, y) {
+				m.modules[y][x] = !m.modules[y][x]
+			}
+		}
+	}
+}
+
+// maskPenalties returns the penalty score of every mask pattern.
+func (m *matrix) maskPenalties(level ECLevel) []int {
+	scores := make([]int, 8)
+	for mask := range scores {
+		m.applyMask(mask)
+		m.drawFormatBits(level, mask)
+		scores[mask] = m.penaltyScore()
+		m.applyMask(mask)
+	}
+	return scores
+}
+
+// penaltyScore evaluates the four mask penalty rules of ISO/IEC 18004 7.8.3.
+func (m *matrix) penaltyScore() int {
+	size := m.width
+	score := 0
+	at := func(x, y int, vertical bool) bool {
+		if vertical {
+			return m.modules[x][y]
+		}
+		return m.modules[y][x]
+	}
+
+	for _, vertical := range []bool{false, true} {
+		for y := 0; y < size; y++ {
+			// Rule 1: runs of five or more same-colored modules.
+			run := 1
+			for x := 1; x < size; x++ {
+				if at(x, y, vertical) == at(x-1, y, vertical) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += 3 + run - 5
+				}
+				run = 1
+			}
+			if run >= 5 {
+				score += 3 + run - 5
+			}
+
+			// Rule 3: 1:1:3:1:1 finder-like patterns with four light
+			// modules on either side.
+			for x := 0; x+7 <= size; x++ {
+				if at(x, y, vertical) && !at(x+1, y, vertical) && at(x+2, y, vertical) &&
+					at(x+3, y, vertical) && at(x+4, y, vertical) && !at(x+5, y, vertical) && at(x+6, y, vertical) {
+					if m.lightRun(x-4, x, y, vertical) || m.lightRun(x+7, x+11, y, vertical) {
+						score += 40
+					}
+				}
+			}
+		}
+	}
+
+	// Rule 2: 2x2 blocks of the same color.
+	dark := 0
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := m.modules[y][x]
+			if c {
+				dark++
+			}
+			if x+1 < size && y+1 < size && c == m.modules[y][x+1] && c == m.modules[y+1][x] && c == m.modules[y+1][x+1] {
+				score += 3
+			}
+		}
+	}
+
+	// Rule 4: deviation of the dark module ratio from 50%.
+	total := size * size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	score += k * 10
+	return score
+}
+
+// lightRun reports whether all modules in [from, to) along a row (or column
+// when vertical) are light. Modules outside the symbol count as light.
+func (m *matrix) lightRun(from, to, line int, vertical bool) bool {
+	for i := from; i < to; i++ {
+		if i < 0 || i >= m.width {
+			continue
+		}
+		if vertical && m.modules[i][line] {
+			return false
+		}
+		if !vertical && m.modules[line][i] {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
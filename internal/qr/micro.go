@@ -0,0 +1,178 @@
+package qr
+
+const microMaxVersion = 4
+
+// microDataBits is the data capacity in bits of Micro QR symbols, indexed by
+// [version][level]. Zero marks levels a version does not offer; M1 only
+// detects errors and is listed under L.
+var microDataBits = [microMaxVersion + 1][4]int{
+	1: {20, 0, 0, 0},
+	2: {40, 32, 0, 0},
+	3: {84, 68, 0, 0},
+	4: {128, 112, 80, 0},
+}
+
+// microECCodewords is the number of error correction codewords of Micro QR
+// symbols, indexed like microDataBits.
+var microECCodewords = [microMaxVersion + 1][4]int{
+	1: {2, 0, 0, 0},
+	2: {5, 6, 0, 0},
+	3: {6, 8, 0, 0},
+	4: {8, 10, 14, 0},
+}
+
+// microMaskPatterns maps the four Micro QR masks to the equivalent QR Code
+// mask patterns.
+var microMaskPatterns = [4]int{1, 4, 6, 7}
+
+// microFields returns the header layout of Micro QR version M1-M4.
+func microFields(version int) fieldSpec {
+	fs := fieldSpec{
+		modeBits:   version - 1,
+		indicators: map[Mode]int{ModeNumeric: 0},
+		countBits:  map[Mode]int{ModeNumeric: version + 2},
+	}
+	if version >= 2 {
+		fs.indicators[ModeAlphanumeric] = 1
+		fs.countBits[ModeAlphanumeric] = version + 1
+	}
+	if version >= 3 {
+		fs.indicators[ModeByte] = 2
+		fs.indicators[ModeKanji] = 3
+		fs.countBits[ModeByte] = version + 1
+		fs.countBits[ModeKanji] = version
+	}
+	return fs
+}
+
+// microSymbolNumber returns the symbol number written in the format
+// information for a version and level.
+func microSymbolNumber(version int, level ECLevel) int {
+	if version == 1 {
+		return 0
+	}
+	return [...]int{0, 0, 1, 3, 5}[version] + int(level)
+}
+
+// encodeMicro encodes text into the smallest Micro QR symbol that holds it
+// at the requested level. Versions that do not offer the level are skipped.
+func encodeMicro(text string, opts Options) (*Code, error) {
+	lo, hi := opts.versionRange(1, microMaxVersion)
+	for version := lo; version <= hi; version++ {
+		capacity := microDataBits[version][opts.Level]
+		if capacity == 0 {
+			continue
+		}
+		fs := microFields(version)
+		segs, err := makeSegments(text, opts.Encoding, opts.Charset, fs)
+		if err == errModeUnsupported {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if used := totalBits(segs, fs); used >= 0 && used <= capacity {
+			return buildMicro(segs, version, opts.Level, used, opts.Mask), nil
+		}
+	}
+	return nil, &DataTooLongError{Symbology: SymbologyMicroQR, Level: opts.Level, MinVersion: lo, MaxVersion: hi}
+}
+
+// buildMicro lays out the codewords of a Micro QR symbol and applies the
+// mask.
+func buildMicro(segs []Segment, version int, level ECLevel, used int, forcedMask *int) *Code {
+	capacity := microDataBits[version][level]
+	data := buildCodewords(segs, microFields(version), capacity, 2*version+1)
+	ec := reedSolomonRemainder(data, reedSolomonDivisor(microECCodewords[version][level]))
+
+	var bits bitBuffer
+	if capacity%8 != 0 {
+		// M1 and M3 end their data with a 4-bit codeword.
+		bits.appendBytes(data[:len(data)-1])
+		bits.appendBits(int(data[len(data)-1]>>4), 4)
+	} else {
+		bits.appendBytes(data)
+	}
+	bits.appendBytes(ec)
+
+	size := version*2 + 9
+	m := newGrid(size, size)
+	m.drawFinderPattern(3, 3)
+	for i := 8; i < size; i++ {
+		m.setFunction(i, 0, i%2 == 0)
+		m.setFunction(0, i, i%2 == 0)
+	}
+	symbol := microSymbolNumber(version, level)
+	m.drawMicroFormatBits(symbol, 0)
+	m.placeBits(bits, size-1, -1)
+
+	scores := make([]int, len(microMaskPatterns))
+	for mask, pattern := range microMaskPatterns {
+		m.applyMask(pattern)
+		scores[mask] = m.microMaskScore()
+		m.applyMask(pattern)
+	}
+	mask := 0
+	if forcedMask != nil {
+		mask = *forcedMask
+	} else {
+		for i, s := range scores {
+			if s > scores[mask] {
+				mask = i
+			}
+		}
+	}
+	m.applyMask(microMaskPatterns[mask])
+	m.drawMicroFormatBits(symbol, mask)
+
+	return &Code{
+		Symbology:    SymbologyMicroQR,
+		Version:      version,
+		Level:        level,
+		Mask:         mask,
+		Segments:     segs,
+		Penalties:    scores,
+		DataBits:     used,
+		CapacityBits: capacity,
+		width:        size,
+		height:       size,
+		modules:      m.modules,
+	}
+}
+
+// drawMicroFormatBits writes the single copy of the Micro QR format
+// information next to the finder pattern.
+func (m *matrix) drawMicroFormatBits(symbol, mask int) {
+	data := symbol<<2 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x4445
+	bit := func(i int) bool { return (bits>>uint(i))&1 != 0 }
+
+	for i := 0; i < 8; i++ {
+		m.setFunction(8, i+1, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.setFunction(15-i, 8, bit(i))
+	}
+}
+
+// microMaskScore evaluates a masked Micro QR symbol by the number of dark
+// modules along its right and bottom edges; higher is better.
+func (m *matrix) microMaskScore() int {
+	sum1, sum2 := 0, 0
+	for i := 1; i < m.width; i++ {
+		if m.modules[i][m.width-1] {
+			sum1++
+		}
+		if m.modules[m.height-1][i] {
+			sum2++
+		}
+	}
+	if sum1 <= sum2 {
+		return sum1*16 + sum2
+	}
+	return sum2*16 + sum1
+}
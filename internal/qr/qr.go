@@ -0,0 +1,425 @@
+// Package qr implements encoders for QR Code and Micro QR Code (ISO/IEC
+// 18004) and rectangular Micro QR Code (rMQR, ISO/IEC 23941) with explicit
+// control over segment modes and ECI character set headers.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ECLevel is the error correction level of a symbol.
+type ECLevel int
+
+const (
+	ECLevelL ECLevel = iota
+	ECLevelM
+	ECLevelQ
+	ECLevelH
+)
+
+// String returns the single-letter name of the level.
+func (l ECLevel) String() string {
+	return [...]string{"L", "M", "Q", "H"}[l]
+}
+
+// formatBits returns the two bits used for the level in format information.
+func (l ECLevel) formatBits() int {
+	return [...]int{1, 0, 3, 2}[l]
+}
+
+const (
+	minVersion = 1
+	maxVersion = 40
+)
+
+// Symbology selects the symbol family to encode.
+type Symbology int
+
+const (
+	SymbologyQR Symbology = iota
+	SymbologyMicroQR
+	SymbologyRMQR
+)
+
+// ParseSymbology parses the symbology names accepted by the API.
+func ParseSymbology(s string) (Symbology, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "qr":
+		return SymbologyQR, nil
+	case "micro", "microqr", "micro-qr":
+		return SymbologyMicroQR, nil
+	case "rmqr":
+		return SymbologyRMQR, nil
+	}
+	return SymbologyQR, fmt.Errorf("unsupported symbology %q (use qr, micro or rmqr)", s)
+}
+
+// String returns the API name of the symbology.
+func (s Symbology) String() string {
+	switch s {
+	case SymbologyMicroQR:
+		return "micro"
+	case SymbologyRMQR:
+		return "rmqr"
+	}
+	return "qr"
+}
+
+// DefaultLevel returns the error correction level used when none is
+// asked for: Q, or M for Micro QR, where only M4 offers Q. M rules out M1,
+// which only detects errors.
+func (s Symbology) DefaultLevel() ECLevel {
+	if s == SymbologyMicroQR {
+		return ECLevelM
+	}
+	return ECLevelQ
+}
+
+// ParseLevel parses an error correction level name (L, M, Q or H).
+func ParseLevel(s string) (ECLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "L":
+		return ECLevelL, nil
+	case "M":
+		return ECLevelM, nil
+	case "Q":
+		return ECLevelQ, nil
+	case "H":
+		return ECLevelH, nil
+	}
+	return ECLevelQ, fmt.Errorf("unsupported ecc %q (use L, M, Q or H)", s)
+}
+
+// ErrDataTooLong is returned when the payload does not fit the allowed
+// versions. Errors returned by Encode wrap it in a *DataTooLongError.
+var ErrDataTooLong = errors.New("data too long for a QR code")
+
+// DataTooLongError reports the smallest version that would hold the payload.
+type DataTooLongError struct {
+	Symbology Symbology
+	Level     ECLevel
+	// RequiredVersion is the smallest version that fits, or 0 if the payload
+	// is too long even for the largest symbol.
+	RequiredVersion        int
+	MinVersion, MaxVersion int
+}
+
+func (e *DataTooLongError) Error() string {
+	switch {
+	case e.Symbology == SymbologyMicroQR:
+		return fmt.Sprintf("payload is too long for Micro QR versions M%d-M%d at ecc %s", e.MinVersion, e.MaxVersion, e.Level)
+	case e.Symbology == SymbologyRMQR:
+		return fmt.Sprintf("payload is too long for rMQR symbols R7x43-R17x139 at ecc %s", e.Level)
+	case e.RequiredVersion == 0:
+		return fmt.Sprintf("payload is too long for QR versions %d-%d at ecc %s", e.MinVersion, maxVersion, e.Level)
+	}
+	return fmt.Sprintf("payload requires QR version %d at ecc %s but maxVersion is %d", e.RequiredVersion, e.Level, e.MaxVersion)
+}
+
+func (e *DataTooLongError) Unwrap() error { return ErrDataTooLong }
+
+// Options controls how a payload is encoded.
+type Options struct {
+	Symbology Symbology
+	// Level is the error correction level, see Symbology.DefaultLevel. It
+	// is a minimum: Micro QR skips versions that lack it (M1 only counts as
+	// L) and rMQR rounds L up to M and Q up to H.
+	Level    ECLevel
+	Encoding Encoding
+	Charset  Charset
+	// MinVersion and MaxVersion bound the symbol version (1-40, or 1-4 for
+	// Micro QR M1-M4). Zero leaves that end unbounded. rMQR picks the
+	// smallest symbol by area and does not take bounds.
+	MinVersion, MaxVersion int
+	// Mask forces a mask pattern (0-7, or 0-3 for Micro QR). Nil picks the
+	// best pattern. rMQR uses a single fixed mask.
+	Mask *int
+}
+
+// Validate checks the version and mask bounds for the symbology.
+func (o Options) Validate() error {
+	lo, hi, masks := minVersion, maxVersion, 8
+	switch o.Symbology {
+	case SymbologyMicroQR:
+		hi, masks = microMaxVersion, 4
+	case SymbologyRMQR:
+		if o.MinVersion != 0 || o.MaxVersion != 0 {
+			return fmt.Errorf("minVersion and maxVersion are not supported for rmqr")
+		}
+		if o.Mask != nil {
+			return fmt.Errorf("mask is not supported for rmqr")
+		}
+	}
+	if o.Symbology == SymbologyMicroQR {
+		if o.Level == ECLevelH {
+			return fmt.Errorf("ecc H is not supported for micro (use L, M or Q)")
+		}
+		if o.Level == ECLevelQ && o.MaxVersion != 0 && o.MaxVersion < microMaxVersion {
+			return fmt.Errorf("ecc Q needs Micro QR version M4, but maxVersion is %d", o.MaxVersion)
+		}
+	}
+	if o.Symbology != SymbologyQR && o.Charset != CharsetDefault {
+		return fmt.Errorf("eci is not supported for %s", o.Symbology)
+	}
+	for _, v := range []int{o.MinVersion, o.MaxVersion} {
+		if v != 0 && (v < lo || v > hi) {
+			return fmt.Errorf("version must be between %d and %d", lo, hi)
+		}
+	}
+	if o.MinVersion != 0 && o.MaxVersion != 0 && o.MinVersion > o.MaxVersion {
+		return fmt.Errorf("minVersion %d is greater than maxVersion %d", o.MinVersion, o.MaxVersion)
+	}
+	if o.Mask != nil && (*o.Mask < 0 || *o.Mask >= masks) {
+		return fmt.Errorf("mask must be between 0 and %d", masks-1)
+	}
+	return nil
+}
+
+// versionRange returns the allowed versions, limited to [lo, hi].
+func (o Options) versionRange(lo, hi int) (int, int) {
+	if o.MinVersion != 0 {
+		lo = o.MinVersion
+	}
+	if o.MaxVersion != 0 {
+		hi = o.MaxVersion
+	}
+	return lo, hi
+}
+
+// Code is an encoded symbol.
+type Code struct {
+	Symbology Symbology
+	// Version is 1-40 for QR Code, 1-4 (M1-M4) for Micro QR and 1-32
+	// (R7x43-R17x139) for rMQR.
+	Version  int
+	Level    ECLevel
+	Mask     int
+	Segments []Segment
+	// Penalties holds the score of each mask pattern: the ISO/IEC 18004
+	// penalty (lower is better) for QR Code, the edge score (higher is
+	// better) for Micro QR. rMQR has a single fixed mask and no scores.
+	Penalties []int
+	// DataBits is the encoded payload length; CapacityBits is the number of
+	// data bits the symbol holds.
+	DataBits, CapacityBits int
+	// Append is set when the symbol is part of a Structured Append sequence.
+	Append *StructuredAppend
+
+	width, height int
+	modules       [][]bool
+}
+
+// RemainingBits returns the unused data capacity of the symbol.
+func (c *Code) RemainingBits() int { return c.CapacityBits - c.DataBits }
+
+// Size returns the number of modules along each side of a square symbol,
+// or the width of an rMQR symbol.
+func (c *Code) Size() int { return c.width }
+
+// Width returns the number of modules across the symbol.
+func (c *Code) Width() int { return c.width }
+
+// Height returns the number of modules down the symbol.
+func (c *Code) Height() int { return c.height }
+
+// VersionName returns the version as printed in the standards, e.g. "7",
+// "M3" or "R13x77".
+func (c *Code) VersionName() string {
+	switch c.Symbology {
+	case SymbologyMicroQR:
+		return "M" + strconv.Itoa(c.Version)
+	case SymbologyRMQR:
+		return fmt.Sprintf("R%dx%d", c.height, c.width)
+	}
+	return strconv.Itoa(c.Version)
+}
+
+// LevelName returns the error correction level, or "detection" for M1
+// symbols, which only detect errors.
+func (c *Code) LevelName() string {
+	if c.Symbology == SymbologyMicroQR && c.Version == 1 {
+		return "detection"
+	}
+	return c.Level.String()
+}
+
+// Bitmap returns the symbol modules indexed by [y][x]; true means dark.
+// The slice must not be modified.
+func (c *Code) Bitmap() [][]bool { return c.modules }
+
+// SegmentSummary describes the segments as "mode:count" pairs, e.g.
+// "eci:26,byte:12,numeric:20".
+func (c *Code) SegmentSummary() string {
+	parts := make([]string, 0, len(c.Segments))
+	for _, s := range c.Segments {
+		parts = append(parts, fmt.Sprintf("%s:%d", s.Mode, s.Count))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Encode encodes text into the smallest symbol of the requested symbology
+// that holds it.
+func Encode(text string, opts Options) (*Code, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	switch opts.Symbology {
+	case SymbologyMicroQR:
+		return encodeMicro(text, opts)
+	case SymbologyRMQR:
+		return encodeRMQR(text, opts)
+	}
+	return encode(text, opts, nil)
+}
+
+// encode builds a QR symbol for text, with header segments written before
+// the payload.
+func encode(text string, opts Options, header []Segment) (*Code, error) {
+	segs, version, err := fitSegments(text, opts, header)
+	if err != nil {
+		return nil, err
+	}
+	fs := qrFields(version)
+	data := buildCodewords(segs, fs, numDataCodewords(version, opts.Level)*8, 4)
+	m := newMatrix(version)
+	m.drawFunctionPatterns()
+	var bits bitBuffer
+	bits.appendBytes(addErrorCorrection(data, numErrorCorrectionBlocks[opts.Level][version],
+		eccCodewordsPerBlock[opts.Level][version], numRawDataModules(version)/8))
+	m.placeBits(bits, m.width-1, 6)
+	penalties := m.maskPenalties(opts.Level)
+	mask := 0
+	if opts.Mask != nil {
+		mask = *opts.Mask
+	} else {
+		for i, p := range penalties {
+			if p < penalties[mask] {
+				mask = i
+			}
+		}
+	}
+	m.applyMask(mask)
+	m.drawFormatBits(opts.Level, mask)
+
+	return &Code{
+		Symbology:    SymbologyQR,
+		Version:      version,
+		Level:        opts.Level,
+		Mask:         mask,
+		Segments:     segs,
+		Penalties:    penalties,
+		DataBits:     totalBits(segs, fs),
+		CapacityBits: numDataCodewords(version, opts.Level) * 8,
+		width:        m.width,
+		height:       m.height,
+		modules:      m.modules,
+	}, nil
+}
+
+// fitSegments segments text and picks the smallest allowed version that
+// holds it.
+func fitSegments(text string, opts Options, header []Segment) ([]Segment, int, error) {
+	lo, hi := opts.versionRange(minVersion, maxVersion)
+	var segs []Segment
+	segClass := -1
+	for version := lo; version <= maxVersion; version++ {
+		if class := versionClass(version); class != segClass {
+			payload, err := makeSegments(text, opts.Encoding, opts.Charset, qrFields(version))
+			if err != nil {
+				return nil, 0, err
+			}
+			segs = append(append([]Segment(nil), header...), payload...)
+			segClass = class
+		}
+		used := totalBits(segs, qrFields(version))
+		if used >= 0 && used <= numDataCodewords(version, opts.Level)*8 {
+			if version > hi {
+				return nil, 0, &DataTooLongError{Level: opts.Level, RequiredVersion: version, MinVersion: lo, MaxVersion: hi}
+			}
+			return segs, version, nil
+		}
+	}
+	return nil, 0, &DataTooLongError{Level: opts.Level, MinVersion: lo, MaxVersion: hi}
+}
+
+// versionClass groups versions that share character count field widths.
+func versionClass(version int) int {
+	switch {
+	case version <= 9:
+		return 0
+	case version <= 26:
+		return 1
+	}
+	return 2
+}
+
+// buildCodewords concatenates the segments, adds the terminator and padding
+// and packs the result into data codewords holding capacity bits. When
+// capacity is not a multiple of 8 the final codeword is 4 bits long and
+// occupies the high nibble of the last byte.
+func buildCodewords(segs []Segment, fs fieldSpec, capacity, terminator int) []byte {
+	var bb bitBuffer
+	for _, s := range segs {
+		bb.appendBits(fs.indicators[s.Mode], fs.modeBits)
+		if s.Mode.hasCharCount() {
+			bb.appendBits(s.Count, fs.countBits[s.Mode])
+		}
+		bb = append(bb, s.data...)
+	}
+
+	bb.appendBits(0, min(terminator, capacity-len(bb)))
+	bb.appendBits(0, min((8-len(bb)%8)%8, capacity-len(bb)))
+	for pad := 0xEC; len(bb) < capacity; pad ^= 0xEC ^ 0x11 {
+		if capacity-len(bb) < 8 {
+			bb.appendBits(0, capacity-len(bb))
+			break
+		}
+		bb.appendBits(pad, 8)
+	}
+
+	data := make([]byte, (len(bb)+7)/8)
+	for i, bit := range bb {
+		data[i>>3] |= bit << uint(7-i&7)
+	}
+	return data
+}
+
+// addErrorCorrection splits data into numBlocks blocks, appends eccLen
+// Reed-Solomon codewords to each and interleaves the result. When the data
+// does not divide evenly, the later blocks hold one more data codeword.
+func addErrorCorrection(data []byte, numBlocks, eccLen, rawCodewords int) []byte {
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := reedSolomonDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := range blocks {
+		n := shortBlockLen - eccLen
+		if i >= numShortBlocks {
+			n++
+		}
+		dat := data[k : k+n]
+		k += n
+		block := make([]byte, 0, shortBlockLen+1)
+		block = append(block, dat...)
+		if i < numShortBlocks {
+			// Placeholder keeps the ECC columns aligned while interleaving.
+			block = append(block, 0)
+		}
+		blocks[i] = append(block, reedSolomonRemainder(dat, divisor)...)
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := 0; i < len(blocks[0]); i++ {
+		for j, block := range blocks {
+			if i != shortBlockLen-eccLen || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
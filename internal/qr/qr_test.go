@@ -0,0 +1,385 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	bq "github.com/boombuler/barcode/qr"
+)
+
+func TestErrorCorrectionKnownAnswer(t *testing.T) {
+	// ISO/IEC 18004 Annex I: "01234567" as a 1-M and an M2-L symbol.
+	segs, err := makeSegments("01234567", EncodingNumeric, CharsetDefault, qrFields(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buildCodewords(segs, qrFields(1), numDataCodewords(1, ECLevelM)*8, 4)
+	got := addErrorCorrection(data, 1, eccCodewordsPerBlock[ECLevelM][1], numRawDataModules(1)/8)
+	want := []byte{
+		0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11,
+		0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55,
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("1-M codewords\n% X, want\n% X", got, want)
+	}
+
+	segs, err = makeSegments("01234567", EncodingNumeric, CharsetDefault, microFields(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = buildCodewords(segs, microFields(2), microDataBits[2][ECLevelL], 5)
+	got = append(data, reedSolomonRemainder(data, reedSolomonDivisor(microECCodewords[2][ECLevelL]))...)
+	want = []byte{0x40, 0x18, 0xAC, 0xC3, 0x00, 0x86, 0x0D, 0x22, 0xAE, 0x30}
+	if !bytes.Equal(got, want) {
+		t.Errorf("M2-L codewords\n% X, want\n% X", got, want)
+	}
+}
+
+func TestFormatInformation(t *testing.T) {
+	tests := []struct {
+		level ECLevel
+		mask  int
+		want  string
+	}{
+		{ECLevelL, 0, "111011111000100"},
+		{ECLevelL, 1, "111001011110011"},
+		{ECLevelL, 4, "110011000101111"},
+		{ECLevelM, 0, "101010000010010"},
+		{ECLevelM, 5, "100000011001110"},
+		{ECLevelQ, 0, "011010101011111"},
+		{ECLevelH, 0, "001011010001001"},
+		{ECLevelH, 7, "000100000111011"},
+	}
+	for _, tt := range tests {
+		m := newMatrix(1)
+		m.drawFormatBits(tt.level, tt.mask)
+		// The copy along the bottom and right edges holds bits 0-7 right
+		// to left, then bits 8-14 top to bottom.
+		var bits [15]byte
+		for i := range bits {
+			x, y := m.width-1-i, 8
+			if i >= 8 {
+				x, y = 8, m.width-15+i
+			}
+			bits[14-i] = '0'
+			if m.modules[y][x] {
+				bits[14-i] = '1'
+			}
+		}
+		if got := string(bits[:]); got != tt.want {
+			t.Errorf("format %s-%d = %s, want %s", tt.level, tt.mask, got, tt.want)
+		}
+	}
+}
+
+func TestVersionInformation(t *testing.T) {
+	for version, want := range map[int]int{7: 0x07C94, 8: 0x085BC, 21: 0x15683, 40: 0x28C69} {
+		m := newMatrix(version)
+		m.drawVersion()
+		got := 0
+		for i := 17; i >= 0; i-- {
+			got <<= 1
+			if m.modules[i/3][m.width-11+i%3] {
+				got |= 1
+			}
+		}
+		if got != want {
+			t.Errorf("version %d information = %05X, want %05X", version, got, want)
+		}
+	}
+}
+
+func TestKanjiValue(t *testing.T) {
+	// ISO/IEC 18004 7.4.6 examples, and characters outside Kanji mode.
+	for r, want := range map[rune]int{'点': 0xD9F, '茗': 0x1AAA, 'A': -1, 'ｱ': -1, 'é': -1} {
+		if got := kanjiValue(r); got != want {
+			t.Errorf("kanjiValue(%q) = %#x, want %#x", r, got, want)
+		}
+	}
+}
+
+// TestMatchesReference compares QR Code symbols with an independent
+// encoder. The two pick masks with slightly different penalty rules, so the
+// reference's mask is read from its format information and forced.
+func TestMatchesReference(t *testing.T) {
+	tests := []struct {
+		text string
+		enc  Encoding
+		mode bq.Encoding
+	}{
+		{"01234567", EncodingNumeric, bq.Numeric},
+		{strings.Repeat("31415926535897932384626433832795", 12), EncodingNumeric, bq.Numeric},
+		{"HELLO WORLD", EncodingAlphanumeric, bq.AlphaNumeric},
+		{"HTTPS://EXAMPLE.COM/A/B-C:1", EncodingAlphanumeric, bq.AlphaNumeric},
+		{"https://example.com/abc?x=1", EncodingByte, bq.Unicode},
+		{"Ünïcödé text", EncodingByte, bq.Unicode},
+		{strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20), EncodingByte, bq.Unicode},
+	}
+	for _, tt := range tests {
+		for level := ECLevelL; level <= ECLevelH; level++ {
+			name := fmt.Sprintf("%.20s/%s", tt.text, level)
+			ref, err := bq.Encode(tt.text, bq.ErrorCorrectionLevel(level), tt.mode)
+			if err != nil {
+				t.Fatalf("%s: reference: %v", name, err)
+			}
+			size := ref.Bounds().Dx()
+			dark := func(x, y int) bool {
+				r, _, _, _ := ref.At(x, y).RGBA()
+				return r == 0
+			}
+			// Bits 8-14 of the format information run down column 8.
+			format := 0
+			for i := 8; i < 15; i++ {
+				if dark(8, size-15+i) {
+					format |= 1 << i
+				}
+			}
+			mask := (format ^ 0x5412) >> 10 & 7
+
+			code, err := Encode(tt.text, Options{Level: level, Encoding: tt.enc, Mask: &mask})
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if code.Size() != size {
+				t.Errorf("%s: version %d, reference %d", name, code.Version, (size-17)/4)
+				continue
+			}
+		compare:
+			for y := range size {
+				for x := range size {
+					if code.Bitmap()[y][x] != dark(x, y) {
+						t.Errorf("%s: version %d mask %d differs from the reference at (%d, %d)", name, code.Version, mask, x, y)
+						break compare
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestMaskSelection(t *testing.T) {
+	tests := []struct {
+		text          string
+		level         ECLevel
+		version, mask int
+	}{
+		{"01234567", ECLevelM, 1, 2},
+		{"HELLO WORLD", ECLevelQ, 1, 0},
+		{"https://example.com/abc?x=1", ECLevelM, 3, 6},
+		{strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20), ECLevelL, 21, 2},
+	}
+	for _, tt := range tests {
+		code, err := Encode(tt.text, Options{Level: tt.level})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code.Version != tt.version || code.Mask != tt.mask {
+			t.Errorf("%.20q at %s: version %d mask %d, want version %d mask %d", tt.text, tt.level, code.Version, code.Mask, tt.version, tt.mask)
+		}
+		for mask, p := range code.Penalties {
+			if p < code.Penalties[code.Mask] {
+				t.Errorf("%.20q at %s: mask %d scores %d, lower than the chosen mask %d", tt.text, tt.level, mask, p, code.Mask)
+			}
+		}
+	}
+}
+
+func TestSegmentation(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts Options
+		want string
+	}{
+		{"numeric", "0123456789", Options{}, "numeric:10"},
+		{"alphanumeric", "HELLO WORLD", Options{}, "alphanumeric:11"},
+		{"byte", "hello", Options{}, "byte:5"},
+		{"short digit run stays in byte", "a123b", Options{}, "byte:5"},
+		{"long digit run", "a1234567890123", Options{}, "byte:1,numeric:13"},
+		{"alphanumeric then numeric", "ABC1234567890123", Options{}, "alphanumeric:3,numeric:13"},
+		{"url", "HTTPS://EXAMPLE.COM/0123456789012345", Options{}, "alphanumeric:20,numeric:16"},
+		{"utf-8 counts bytes", "añb", Options{}, "byte:4"},
+		{"forced byte", "0123456789", Options{Encoding: EncodingByte}, "byte:10"},
+		{"eci utf-8", "añb", Options{Charset: CharsetUTF8}, "eci:26,byte:4"},
+		{"eci latin-1", "añb", Options{Charset: CharsetISO8859_1}, "eci:3,byte:3"},
+		{"shift jis picks kanji", "点茗", Options{Charset: CharsetShiftJIS}, "eci:20,kanji:2"},
+		{"shift jis mixed", "ｱ点茗点茗点茗12345678", Options{Charset: CharsetShiftJIS}, "eci:20,byte:1,kanji:6,numeric:8"},
+		{"kanji", "点茗", Options{Encoding: EncodingKanji}, "kanji:2"},
+		{"micro numeric", "12345", Options{Symbology: SymbologyMicroQR}, "numeric:5"},
+		{"micro mixed", "AB12345678901", Options{Symbology: SymbologyMicroQR}, "alphanumeric:2,numeric:11"},
+		{"rmqr", "HELLO 123456789", Options{Symbology: SymbologyRMQR}, "alphanumeric:6,numeric:9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Encode(tt.text, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := code.SegmentSummary(); got != tt.want {
+				t.Errorf("SegmentSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	ptr := func(v int) *int { return &v }
+	long := strings.Repeat("https://example.com/path?query=value&n=0123456789 ", 30)
+	tests := []struct {
+		name    string
+		text    string
+		opts    Options
+		version string
+	}{
+		{"numeric", "01234567", Options{Level: ECLevelM}, "1"},
+		{"alphanumeric", "HELLO WORLD", Options{Level: ECLevelQ}, "1"},
+		{"byte", "https://example.com/", Options{Level: ECLevelH}, "3"},
+		{"mixed modes", "Order 1234567890123 for ACME/42", Options{Level: ECLevelL}, "2"},
+		{"utf-8 without eci", "Grüße, Ünïcödé", Options{}, "2"},
+		{"eci utf-8", "Grüße, 点茗", Options{Charset: CharsetUTF8}, "1"},
+		{"eci latin-1", "Grüße, señor", Options{Charset: CharsetISO8859_1}, "1"},
+		{"eci shift jis", "ｱｲｳ 点茗 12345678", Options{Charset: CharsetShiftJIS}, "1"},
+		{"kanji", "点茗点茗", Options{Encoding: EncodingKanji}, "1"},
+		{"forced mask", "HELLO WORLD 0123456789", Options{Mask: ptr(5)}, "1"},
+		{"version 7", "0123456789", Options{MinVersion: 7}, "7"},
+		{"version 10-26 counts", strings.Repeat("A1", 150), Options{Level: ECLevelH}, "15"},
+		{"version 27-40 counts", long, Options{Level: ECLevelL}, "28"},
+		{"version 40", long[:1000], Options{Level: ECLevelH, MinVersion: 40}, "40"},
+		{"micro M1", "12345", Options{Symbology: SymbologyMicroQR, Level: ECLevelL}, "M1"},
+		{"micro M2", "HELLO", Options{Symbology: SymbologyMicroQR, Level: ECLevelL}, "M2"},
+		{"micro M2-M", "01234567", Options{Symbology: SymbologyMicroQR, Level: ECLevelM}, "M2"},
+		{"micro M3", "hello wor", Options{Symbology: SymbologyMicroQR, Level: ECLevelL}, "M3"},
+		{"micro M3-M", "ABC12345", Options{Symbology: SymbologyMicroQR, Level: ECLevelM, MinVersion: 3}, "M3"},
+		{"micro M4-L", "https://ex.com/", Options{Symbology: SymbologyMicroQR, Level: ECLevelL}, "M4"},
+		{"micro M4-M", "hello world", Options{Symbology: SymbologyMicroQR, Level: ECLevelM}, "M4"},
+		{"micro M4-Q", "HELLO 123", Options{Symbology: SymbologyMicroQR, Level: ECLevelQ, MinVersion: 4}, "M4"},
+		{"micro kanji", "点茗点", Options{Symbology: SymbologyMicroQR, Encoding: EncodingKanji, Level: ECLevelL}, "M3"},
+		{"micro mask", "1234567", Options{Symbology: SymbologyMicroQR, Level: ECLevelL, Mask: ptr(2)}, "M2"},
+		{"rmqr smallest", "123", Options{Symbology: SymbologyRMQR}, "R11x27"},
+		{"rmqr M", "https://example.com/", Options{Symbology: SymbologyRMQR, Level: ECLevelM}, "R9x59"},
+		{"rmqr H", "https://example.com/", Options{Symbology: SymbologyRMQR, Level: ECLevelH}, "R11x77"},
+		{"rmqr two blocks", strings.Repeat("ABCDEFGH", 10), Options{Symbology: SymbologyRMQR, Level: ECLevelH}, "R15x139"},
+		{"rmqr largest", strings.Repeat("abcdefgh", 18), Options{Symbology: SymbologyRMQR, Level: ECLevelM}, "R17x139"},
+		{"rmqr kanji", "点茗点茗", Options{Symbology: SymbologyRMQR, Encoding: EncodingKanji}, "R13x27"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Encode(tt.text, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := read(code)
+			if err != nil {
+				t.Fatalf("%s: %v", code.VersionName(), err)
+			}
+			if code.VersionName() != tt.version {
+				t.Errorf("version %s, want %s", code.VersionName(), tt.version)
+			}
+			if got.text != tt.text {
+				t.Errorf("%s read %q, want %q", code.VersionName(), got.text, tt.text)
+			}
+			if got.segments != code.SegmentSummary() {
+				t.Errorf("%s segments %s, want %s", code.VersionName(), got.segments, code.SegmentSummary())
+			}
+			if got.version != code.Version || got.level != code.Level || got.mask != code.Mask {
+				t.Errorf("read version %d level %s mask %d, encoded %d %s %d",
+					got.version, got.level, got.mask, code.Version, code.Level, code.Mask)
+			}
+			if tt.opts.Mask != nil && code.Mask != *tt.opts.Mask {
+				t.Errorf("mask %d, want %d", code.Mask, *tt.opts.Mask)
+			}
+		})
+	}
+}
+
+func TestMicroLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		opts    Options
+		version string
+		level   string
+		err     string
+	}{
+		{"L allows M1", "123", Options{Level: ECLevelL}, "M1", "detection", ""},
+		{"M skips M1", "123", Options{Level: ECLevelM}, "M2", "M", ""},
+		{"M grows the version", "hello wor", Options{Level: ECLevelM}, "M4", "M", ""},
+		{"too long at M", "https://ex.com/", Options{Level: ECLevelM, MaxVersion: 3}, "", "",
+			"payload is too long for Micro QR versions M1-M3 at ecc M"},
+		{"Q needs M4", "123", Options{Level: ECLevelQ}, "M4", "Q", ""},
+		{"Q below M4", "123", Options{Level: ECLevelQ, MaxVersion: 3}, "", "",
+			"ecc Q needs Micro QR version M4, but maxVersion is 3"},
+		{"no H", "123", Options{Level: ECLevelH}, "", "", "ecc H is not supported for micro (use L, M or Q)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Symbology = SymbologyMicroQR
+			code, err := Encode(tt.text, tt.opts)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code.VersionName() != tt.version || code.LevelName() != tt.level {
+				t.Errorf("%s-%s, want %s-%s", code.VersionName(), code.LevelName(), tt.version, tt.level)
+			}
+		})
+	}
+}
+
+func TestStructuredAppend(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		opts    Options
+		symbols int
+	}{
+		{"two", "https://example.com/a/very/long/path", Options{}, 2},
+		{"fewest within max version", strings.Repeat("Lorem ipsum dolor sit amet. ", 12), Options{MaxVersion: 5}, 0},
+		{"utf-8", strings.Repeat("Grüße 点茗 ", 8), Options{Charset: CharsetUTF8}, 4},
+		{"sixteen", strings.Repeat("0123456789", 16), Options{}, 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes, err := EncodeStructured(tt.text, tt.opts, tt.symbols)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.symbols != 0 && len(codes) != tt.symbols {
+				t.Fatalf("%d symbols, want %d", len(codes), tt.symbols)
+			}
+
+			var parity byte
+			for _, b := range []byte(tt.text) {
+				parity ^= b
+			}
+			var text strings.Builder
+			for i, code := range codes {
+				if tt.opts.MaxVersion != 0 && code.Version > tt.opts.MaxVersion {
+					t.Errorf("symbol %d is version %d, above maxVersion %d", i, code.Version, tt.opts.MaxVersion)
+				}
+				got, err := read(code)
+				if err != nil {
+					t.Fatalf("symbol %d: %v", i, err)
+				}
+				want := StructuredAppend{Index: i, Total: len(codes), Parity: parity}
+				if got.append == nil || *got.append != want {
+					t.Errorf("symbol %d header %+v, want %+v", i, got.append, want)
+				}
+				if *code.Append != want {
+					t.Errorf("symbol %d Append %+v, want %+v", i, *code.Append, want)
+				}
+				text.WriteString(got.text)
+			}
+			if text.String() != tt.text {
+				t.Errorf("reassembled %q, want %q", text.String(), tt.text)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// decoded is what read recovers from a symbol.
+type decoded struct {
+	version int
+	level   ECLevel
+	mask    int
+	text    string
+	// segments lists the modes found in the bit stream, in the format of
+	// Code.SegmentSummary.
+	segments string
+	append   *StructuredAppend
+}
+
+// read decodes a symbol the way a reader would: it finds the format
+// information, unmasks the data modules, checks the Reed-Solomon codewords
+// of every block and parses the bit stream. It only relies on the package
+// for the function pattern layouts and the Reed-Solomon arithmetic, which
+// the known-answer tests cover separately.
+func read(c *Code) (*decoded, error) {
+	switch c.Symbology {
+	case SymbologyMicroQR:
+		return readMicro(c)
+	case SymbologyRMQR:
+		return readRMQR(c)
+	}
+	return readQR(c)
+}
+
+func readQR(c *Code) (*decoded, error) {
+	version := (c.width - 17) / 4
+	var m *matrix
+	d := &decoded{version: version, level: -1}
+	for level := ECLevelL; level <= ECLevelH && d.level < 0; level++ {
+		for mask := 0; mask < 8; mask++ {
+			m = newMatrix(version)
+			m.drawFunctionPatterns()
+			m.drawFormatBits(level, mask)
+			if sameFunctionModules(m, c) {
+				d.level, d.mask = level, mask
+				break
+			}
+		}
+	}
+	if d.level < 0 {
+		return nil, fmt.Errorf("no format information matches the function patterns")
+	}
+
+	codewords := packBits(readBits(m, c, d.mask, m.width-1, 6))
+	numBlocks := numErrorCorrectionBlocks[d.level][version]
+	eccLen := eccCodewordsPerBlock[d.level][version]
+	raw := numRawDataModules(version) / 8
+	lens := make([]int, numBlocks)
+	for i := range lens {
+		lens[i] = raw/numBlocks - eccLen
+		if i >= numBlocks-raw%numBlocks {
+			lens[i]++
+		}
+	}
+	data, err := deinterleave(codewords[:raw], lens, eccLen)
+	if err != nil {
+		return nil, err
+	}
+	return d, parseStream(d, unpackBits(data), qrFields(version), 4)
+}
+
+// microMatrix returns the function patterns of a Micro QR symbol.
+func microMatrix(version int) *matrix {
+	size := version*2 + 9
+	m := newGrid(size, size)
+	m.drawFinderPattern(3, 3)
+	for i := 8; i < size; i++ {
+		m.setFunction(i, 0, i%2 == 0)
+		m.setFunction(0, i, i%2 == 0)
+	}
+	return m
+}
+
+func readMicro(c *Code) (*decoded, error) {
+	version := (c.width - 9) / 2
+	var m *matrix
+	d := &decoded{version: version, level: -1}
+	for level := ECLevelL; level <= ECLevelQ && d.level < 0; level++ {
+		if microDataBits[version][level] == 0 {
+			continue
+		}
+		for mask := range microMaskPatterns {
+			m = microMatrix(version)
+			m.drawMicroFormatBits(microSymbolNumber(version, level), mask)
+			if sameFunctionModules(m, c) {
+				d.level, d.mask = level, mask
+				break
+			}
+		}
+	}
+	if d.level < 0 {
+		return nil, fmt.Errorf("no format information matches the function patterns")
+	}
+
+	bits := readBits(m, c, microMaskPatterns[d.mask], m.width-1, -1)
+	capacity := microDataBits[version][d.level]
+	data := packBits(bits[:capacity])
+	ecc := packBits(bits[capacity : capacity+8*microECCodewords[version][d.level]])
+	if want := reedSolomonRemainder(data, reedSolomonDivisor(len(ecc))); !bytes.Equal(ecc, want) {
+		return nil, fmt.Errorf("error correction codewords % X, want % X", ecc, want)
+	}
+	return d, parseStream(d, bits[:capacity], microFields(version), 2*version+1)
+}
+
+func readRMQR(c *Code) (*decoded, error) {
+	var m *matrix
+	d := &decoded{level: -1, mask: 0}
+	for i, v := range rmqrVersions {
+		if v.width != c.width || v.height != c.height {
+			continue
+		}
+		d.version = i + 1
+		for _, level := range []ECLevel{ECLevelM, ECLevelH} {
+			m = newGrid(v.width, v.height)
+			m.drawRMQRFunctionPatterns()
+			m.drawRMQRFormatBits(level, i)
+			if sameFunctionModules(m, c) {
+				d.level = level
+				break
+			}
+		}
+	}
+	if d.level < 0 {
+		return nil, fmt.Errorf("no format information matches the function patterns")
+	}
+
+	v := rmqrVersions[d.version-1]
+	bits := readBits(m, c, rmqrMask, m.width-2, -1)
+	if len(bits) != v.codewords*8+v.remainder {
+		return nil, fmt.Errorf("symbol holds %d bits, want %d", len(bits), v.codewords*8+v.remainder)
+	}
+	var lens []int
+	blocks := v.blocks(d.level)
+	for _, b := range blocks {
+		for range b.count {
+			lens = append(lens, b.data)
+		}
+	}
+	data, err := deinterleave(packBits(bits[:v.codewords*8]), lens, blocks[0].total-blocks[0].data)
+	if err != nil {
+		return nil, err
+	}
+	return d, parseStream(d, unpackBits(data), v.fields(), 3)
+}
+
+// sameFunctionModules reports whether every function module of m matches c.
+func sameFunctionModules(m *matrix, c *Code) bool {
+	if m.width != c.width || m.height != c.height {
+		return false
+	}
+	for y := range m.function {
+		for x, f := range m.function[y] {
+			if f && m.modules[y][x] != c.modules[y][x] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// readBits unmasks the data modules of c and reads them in the zigzag
+// order: two-module columns from startCol leftwards, skipping column
+// skipCol.
+func readBits(m *matrix, c *Code, pattern, startCol, skipCol int) []byte {
+	var bits []byte
+	upward := true
+	for right := startCol; right >= 1; right -= 2 {
+		if right == skipCol {
+			right--
+		}
+		for vert := 0; vert < m.height; vert++ {
+			y := vert
+			if upward {
+				y = m.height - 1 - vert
+			}
+			for x := right; x > right-2; x-- {
+				if m.function[y][x] {
+					continue
+				}
+				bit := c.modules[y][x] != maskBit(pattern, x, y)
+				if bit {
+					bits = append(bits, 1)
+				} else {
+					bits = append(bits, 0)
+				}
+			}
+		}
+		upward = !upward
+	}
+	return bits
+}
+
+// deinterleave splits interleaved codewords into blocks holding lens data
+// codewords and eccLen error correction codewords each, checks the error
+// correction of every block and returns the data codewords in order.
+func deinterleave(codewords []byte, lens []int, eccLen int) ([]byte, error) {
+	blocks := make([][]byte, len(lens))
+	k := 0
+	for i := 0; i < lens[len(lens)-1]; i++ {
+		for j, n := range lens {
+			if i < n {
+				blocks[j] = append(blocks[j], codewords[k])
+				k++
+			}
+		}
+	}
+	var data []byte
+	for j, block := range blocks {
+		ecc := make([]byte, eccLen)
+		for i := range ecc {
+			ecc[i] = codewords[k+i*len(blocks)+j]
+		}
+		if want := reedSolomonRemainder(block, reedSolomonDivisor(eccLen)); !bytes.Equal(ecc, want) {
+			return nil, fmt.Errorf("block %d: error correction codewords % X, want % X", j, ecc, want)
+		}
+		data = append(data, block...)
+	}
+	if k+eccLen*len(blocks) != len(codewords) {
+		return nil, fmt.Errorf("%d codewords left over", len(codewords)-k-eccLen*len(blocks))
+	}
+	return data, nil
+}
+
+func packBits(bits []byte) []byte {
+	data := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		data[i/8] |= bit << uint(7-i%8)
+	}
+	return data
+}
+
+func unpackBits(data []byte) []byte {
+	var bits bitBuffer
+	bits.appendBytes(data)
+	return bits
+}
+
+// parseStream decodes the segments in bits into d. A run of terminator
+// zero bits, or too few bits for another mode indicator, ends the stream.
+func parseStream(d *decoded, bits []byte, fs fieldSpec, terminator int) error {
+	pos := 0
+	take := func(n int) int {
+		v := 0
+		for ; n > 0; n-- {
+			v = v<<1 | int(bits[pos])
+			pos++
+		}
+		return v
+	}
+	modes := map[int]Mode{}
+	for mode, indicator := range fs.indicators {
+		modes[indicator] = mode
+	}
+
+	var text strings.Builder
+	var segments []string
+	eci := -1
+	for pos+fs.modeBits <= len(bits) {
+		if pos+terminator > len(bits) || bytes.IndexByte(bits[pos:pos+terminator], 1) < 0 {
+			break
+		}
+		mode, ok := modes[take(fs.modeBits)]
+		if !ok {
+			return fmt.Errorf("unknown mode indicator at bit %d", pos-fs.modeBits)
+		}
+		count := 0
+		if mode.hasCharCount() {
+			count = take(fs.countBits[mode])
+		}
+		switch mode {
+		case ModeECI:
+			if take(1) != 0 {
+				return fmt.Errorf("multi-byte ECI designators are not used")
+			}
+			eci = take(7)
+			count = eci
+		case ModeStructuredAppend:
+			d.append = &StructuredAppend{Index: take(4), Total: take(4) + 1, Parity: byte(take(8))}
+			count = d.append.Index
+		case ModeNumeric:
+			for i := 0; i < count; i += 3 {
+				n := min(3, count-i)
+				fmt.Fprintf(&text, "%0*d", n, take(n*3+1))
+			}
+		case ModeAlphanumeric:
+			for i := 0; i < count; i += 2 {
+				if count-i == 1 {
+					text.WriteByte(alphanumericCharset[take(6)])
+					continue
+				}
+				v := take(11)
+				text.WriteByte(alphanumericCharset[v/45])
+				text.WriteByte(alphanumericCharset[v%45])
+			}
+		case ModeByte:
+			b := make([]byte, count)
+			for i := range b {
+				b[i] = byte(take(8))
+			}
+			s, err := decodeBytes(b, eci)
+			if err != nil {
+				return err
+			}
+			text.WriteString(s)
+		case ModeKanji:
+			for range count {
+				v := take(13)
+				code := v/0xC0<<8 | v%0xC0
+				if code+0x8140 <= 0x9FFC {
+					code += 0x8140
+				} else {
+					code += 0xC140
+				}
+				s, err := decodeBytes([]byte{byte(code >> 8), byte(code)}, 20)
+				if err != nil {
+					return err
+				}
+				text.WriteString(s)
+			}
+		}
+		segments = append(segments, fmt.Sprintf("%s:%d", mode, count))
+	}
+	d.text, d.segments = text.String(), strings.Join(segments, ",")
+	return nil
+}
+
+// decodeBytes decodes byte mode data in the character set of an ECI
+// designator; without one the bytes are taken as UTF-8.
+func decodeBytes(b []byte, eci int) (string, error) {
+	switch eci {
+	case -1, 26:
+		return string(b), nil
+	case 3:
+		return charmap.ISO8859_1.NewDecoder().String(string(b))
+	case 20:
+		return japanese.ShiftJIS.NewDecoder().String(string(b))
+	}
+	return "", fmt.Errorf("unexpected ECI designator %d", eci)
+}
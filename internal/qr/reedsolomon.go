@@ -0,0 +1,43 @@
+package qr
+
+// gfMultiply multiplies two elements of GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+// reedSolomonDivisor returns the generator polynomial of the given degree,
+// highest-order coefficient first (the leading 1 is implied).
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords for data.
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= gfMultiply(coef, factor)
+		}
+	}
+	return result
+}
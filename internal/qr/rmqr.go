@@ -0,0 +1,259 @@
+package qr
+
+// rmqrBlocks describes the error correction blocks of an rMQR symbol at one
+// level: count blocks of total codewords, data of which are data codewords.
+// When a level has two block groups, the second holds one more data
+// codeword per block.
+type rmqrBlocks []struct{ count, total, data int }
+
+// rmqrVersion is one of the 32 rMQR symbol sizes (ISO/IEC 23941 Tables 6
+// and 8).
+type rmqrVersion struct {
+	height, width int
+	codewords     int
+	remainder     int
+	// countBits holds the character count widths for numeric,
+	// alphanumeric, byte and kanji mode.
+	countBits [4]int
+	m, h      rmqrBlocks
+}
+
+var rmqrVersions = [32]rmqrVersion{
+	{7, 43, 13, 0, [4]int{4, 3, 3, 2}, rmqrBlocks{{1, 13, 6}}, rmqrBlocks{{1, 13, 3}}},
+	{7, 59, 21, 3, [4]int{5, 5, 4, 3}, rmqrBlocks{{1, 21, 12}}, rmqrBlocks{{1, 21, 7}}},
+	{7, 77, 32, 5, [4]int{6, 5, 5, 4}, rmqrBlocks{{1, 32, 20}}, rmqrBlocks{{1, 32, 10}}},
+	{7, 99, 44, 6, [4]int{7, 6, 5, 5}, rmqrBlocks{{1, 44, 28}}, rmqrBlocks{{1, 44, 14}}},
+	{7, 139, 68, 1, [4]int{7, 6, 6, 5}, rmqrBlocks{{1, 68, 44}}, rmqrBlocks{{2, 34, 12}}},
+	{9, 43, 21, 2, [4]int{5, 5, 4, 3}, rmqrBlocks{{1, 21, 12}}, rmqrBlocks{{1, 21, 7}}},
+	{9, 59, 33, 3, [4]int{6, 5, 5, 4}, rmqrBlocks{{1, 33, 21}}, rmqrBlocks{{1, 33, 11}}},
+	{9, 77, 49, 1, [4]int{7, 6, 5, 5}, rmqrBlocks{{1, 49, 31}}, rmqrBlocks{{1, 24, 8}, {1, 25, 9}}},
+	{9, 99, 66, 4, [4]int{7, 6, 6, 5}, rmqrBlocks{{1, 66, 42}}, rmqrBlocks{{2, 33, 11}}},
+	{9, 139, 99, 5, [4]int{8, 7, 6, 6}, rmqrBlocks{{1, 49, 31}, {1, 50, 32}}, rmqrBlocks{{3, 33, 11}}},
+	{11, 27, 15, 2, [4]int{4, 4, 3, 2}, rmqrBlocks{{1, 15, 7}}, rmqrBlocks{{1, 15, 5}}},
+	{11, 43, 31, 1, [4]int{6, 5, 5, 4}, rmqrBlocks{{1, 31, 19}}, rmqrBlocks{{1, 31, 11}}},
+	{11, 59, 47, 0, [4]int{7, 6, 5, 5}, rmqrBlocks{{1, 47, 31}}, rmqrBlocks{{1, 23, 7}, {1, 24, 8}}},
+	{11, 77, 67, 2, [4]int{7, 6, 6, 5}, rmqrBlocks{{1, 67, 43}}, rmqrBlocks{{1, 33, 11}, {1, 34, 12}}},
+	{11, 99, 89, 7, [4]int{8, 7, 6, 6}, rmqrBlocks{{1, 44, 28}, {1, 45, 29}}, rmqrBlocks{{1, 44, 14}, {1, 45, 15}}},
+	{11, 139, 132, 6, [4]int{8, 7, 7, 6}, rmqrBlocks{{2, 66, 42}}, rmqrBlocks{{3, 44, 14}}},
+	{13, 27, 21, 4, [4]int{5, 5, 4, 3}, rmqrBlocks{{1, 21, 12}}, rmqrBlocks{{1, 21, 7}}},
+	{13, 43, 41, 1, [4]int{6, 6, 5, 5}, rmqrBlocks{{1, 41, 27}}, rmqrBlocks{{1, 41, 13}}},
+	{13, 59, 60, 6, [4]int{7, 6, 6, 5}, rmqrBlocks{{1, 60, 38}}, rmqrBlocks{{2, 30, 10}}},
+	{13, 77, 85, 4, [4]int{7, 7, 6, 6}, rmqrBlocks{{1, 42, 26}, {1, 43, 27}}, rmqrBlocks{{1, 42, 14}, {1, 43, 15}}},
+	{13, 99, 113, 3, [4]int{8, 7, 7, 6}, rmqrBlocks{{1, 56, 36}, {1, 57, 37}}, rmqrBlocks{{1, 37, 11}, {2, 38, 12}}},
+	{13, 139, 166, 0, [4]int{8, 8, 7, 7}, rmqrBlocks{{2, 55, 35}, {1, 56, 36}}, rmqrBlocks{{2, 41, 13}, {2, 42, 14}}},
+	{15, 43, 51, 1, [4]int{7, 6, 6, 5}, rmqrBlocks{{1, 51, 33}}, rmqrBlocks{{1, 25, 7}, {1, 26, 8}}},
+	{15, 59, 74, 4, [4]int{7, 7, 6, 5}, rmqrBlocks{{1, 74, 48}}, rmqrBlocks{{2, 37, 13}}},
+	{15, 77, 103, 6, [4]int{8, 7, 7, 6}, rmqrBlocks{{1, 51, 33}, {1, 52, 34}}, rmqrBlocks{{2, 34, 10}, {1, 35, 11}}},
+	{15, 99, 136, 7, [4]int{8, 7, 7, 6}, rmqrBlocks{{2, 68, 44}}, rmqrBlocks{{4, 34, 12}}},
+	{15, 139, 199, 2, [4]int{9, 8, 7, 7}, rmqrBlocks{{2, 66, 42}, {1, 67, 43}}, rmqrBlocks{{1, 39, 13}, {4, 40, 14}}},
+	{17, 43, 61, 1, [4]int{7, 6, 6, 5}, rmqrBlocks{{1, 61, 39}}, rmqrBlocks{{1, 30, 10}, {1, 31, 11}}},
+	{17, 59, 88, 2, [4]int{8, 7, 6, 6}, rmqrBlocks{{2, 44, 28}}, rmqrBlocks{{2, 44, 14}}},
+	{17, 77, 122, 0, [4]int{8, 7, 7, 6}, rmqrBlocks{{2, 61, 39}}, rmqrBlocks{{1, 40, 12}, {2, 41, 13}}},
+	{17, 99, 160, 3, [4]int{8, 8, 7, 6}, rmqrBlocks{{2, 53, 33}, {1, 54, 34}}, rmqrBlocks{{4, 40, 14}}},
+	{17, 139, 232, 4, [4]int{9, 8, 8, 7}, rmqrBlocks{{4, 58, 38}}, rmqrBlocks{{2, 38, 12}, {4, 39, 13}}},
+}
+
+// rmqrAlignmentColumns lists the centre columns of the alignment patterns
+// for each symbol width.
+var rmqrAlignmentColumns = map[int][]int{
+	27:  nil,
+	43:  {21},
+	59:  {19, 39},
+	77:  {25, 51},
+	99:  {23, 49, 75},
+	139: {27, 55, 83, 111},
+}
+
+// rmqrMask is the single data mask of rMQR, ((y/2)+(x/3)) mod 2 == 0,
+// which is QR Code mask pattern 4.
+const rmqrMask = 4
+
+// fields returns the rMQR header layout of the version.
+func (v rmqrVersion) fields() fieldSpec {
+	return fieldSpec{
+		modeBits: 3,
+		indicators: map[Mode]int{
+			ModeNumeric:      1,
+			ModeAlphanumeric: 2,
+			ModeByte:         3,
+			ModeKanji:        4,
+		},
+		countBits: map[Mode]int{
+			ModeNumeric:      v.countBits[0],
+			ModeAlphanumeric: v.countBits[1],
+			ModeByte:         v.countBits[2],
+			ModeKanji:        v.countBits[3],
+		},
+	}
+}
+
+// blocks returns the block structure for level, which must be M or H.
+func (v rmqrVersion) blocks(level ECLevel) rmqrBlocks {
+	if level == ECLevelH {
+		return v.h
+	}
+	return v.m
+}
+
+// dataCodewords returns the number of data codewords at level.
+func (v rmqrVersion) dataCodewords(level ECLevel) int {
+	n := 0
+	for _, b := range v.blocks(level) {
+		n += b.count * b.data
+	}
+	return n
+}
+
+// rmqrLevel maps a requested level to the M and H levels rMQR offers,
+// rounding up.
+func rmqrLevel(level ECLevel) ECLevel {
+	if level >= ECLevelQ {
+		return ECLevelH
+	}
+	return ECLevelM
+}
+
+// encodeRMQR encodes text into the rMQR symbol with the smallest area that
+// holds it, preferring the shorter symbol between equal areas.
+func encodeRMQR(text string, opts Options) (*Code, error) {
+	level := rmqrLevel(opts.Level)
+	best := -1
+	var bestSegs []Segment
+	bestUsed := 0
+	for i, v := range rmqrVersions {
+		segs, err := makeSegments(text, opts.Encoding, opts.Charset, v.fields())
+		if err != nil {
+			return nil, err
+		}
+		used := totalBits(segs, v.fields())
+		if used < 0 || used > v.dataCodewords(level)*8 {
+			continue
+		}
+		area := v.width * v.height
+		if best < 0 || area < rmqrVersions[best].width*rmqrVersions[best].height {
+			best, bestSegs, bestUsed = i, segs, used
+		}
+	}
+	if best < 0 {
+		return nil, &DataTooLongError{Symbology: SymbologyRMQR, Level: level}
+	}
+	return buildRMQR(bestSegs, best, level, bestUsed), nil
+}
+
+// buildRMQR lays out the codewords of an rMQR symbol and applies the mask.
+func buildRMQR(segs []Segment, index int, level ECLevel, used int) *Code {
+	v := rmqrVersions[index]
+	blocks := v.blocks(level)
+	numBlocks := 0
+	for _, b := range blocks {
+		numBlocks += b.count
+	}
+	capacity := v.dataCodewords(level) * 8
+	data := buildCodewords(segs, v.fields(), capacity, 3)
+
+	var bits bitBuffer
+	bits.appendBytes(addErrorCorrection(data, numBlocks, blocks[0].total-blocks[0].data, v.codewords))
+	bits.appendBits(0, v.remainder)
+
+	m := newGrid(v.width, v.height)
+	m.drawRMQRFunctionPatterns()
+	m.drawRMQRFormatBits(level, index)
+	m.placeBits(bits, v.width-2, -1)
+	m.applyMask(rmqrMask)
+
+	return &Code{
+		Symbology:    SymbologyRMQR,
+		Version:      index + 1,
+		Level:        level,
+		Mask:         0,
+		Segments:     segs,
+		DataBits:     used,
+		CapacityBits: capacity,
+		width:        v.width,
+		height:       v.height,
+		modules:      m.modules,
+	}
+}
+
+// drawRMQRFunctionPatterns draws the timing patterns, the finder pattern,
+// the finder sub-pattern, the corner finder patterns and the alignment
+// patterns.
+func (m *matrix) drawRMQRFunctionPatterns() {
+	w, h := m.width, m.height
+	columns := append([]int{0, w - 1}, rmqrAlignmentColumns[w]...)
+
+	for x := 0; x < w; x++ {
+		m.setFunction(x, 0, x%2 == 0)
+		m.setFunction(x, h-1, x%2 == 0)
+	}
+	for _, x := range columns {
+		for y := 1; y < h-1; y++ {
+			m.setFunction(x, y, y%2 == 0)
+		}
+	}
+
+	// Finder pattern with its separator.
+	m.drawFinderPattern(3, 3)
+
+	// Alignment patterns at both ends of each inner timing column.
+	for _, cx := range rmqrAlignmentColumns[w] {
+		for _, cy := range []int{1, h - 2} {
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					m.setFunction(cx+dx, cy+dy, dx != 0 || dy != 0)
+				}
+			}
+		}
+	}
+
+	// Finder sub-pattern in the bottom right corner.
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.setFunction(w-3+dx, h-3+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+
+	// Corner finder patterns.
+	for x := w - 5; x < w; x++ {
+		m.setFunction(x, 0, true)
+	}
+	m.setFunction(w-2, 1, false)
+	m.setFunction(w-1, 1, true)
+	for x := 0; x < 3; x++ {
+		m.setFunction(x, h-1, true)
+	}
+	if h >= 11 {
+		m.setFunction(0, h-2, true)
+		m.setFunction(1, h-2, false)
+	}
+
+	// Reserve the format areas; drawRMQRFormatBits fills them in.
+	m.drawRMQRFormatBits(ECLevelM, 0)
+}
+
+// drawRMQRFormatBits writes both copies of the format information: the
+// level and version index protected by a (18,6) BCH code.
+func (m *matrix) drawRMQRFormatBits(level ECLevel, index int) {
+	data := index
+	if level == ECLevelH {
+		data |= 1 << 5
+	}
+	rem := data
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := data<<12 | rem
+	left, right := bits^0x1FAB2, bits^0x20A7B
+	bit := func(v, i int) bool { return (v>>uint(i))&1 != 0 }
+
+	w, h := m.width, m.height
+	for i := 0; i < 18; i++ {
+		m.setFunction(8+i/5, 1+i%5, bit(left, i))
+	}
+	for i := 0; i < 15; i++ {
+		m.setFunction(w-8+i/5, h-6+i%5, bit(right, i))
+	}
+	for i := 15; i < 18; i++ {
+		m.setFunction(w-5+i-15, h-6, bit(right, i))
+	}
+}
@@ -0,0 +1,541 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// Mode is a QR segment mode.
+type Mode int
+
+const (
+	ModeNumeric Mode = iota
+	ModeAlphanumeric
+	ModeByte
+	ModeKanji
+	ModeECI
+	ModeStructuredAppend
+)
+
+// String returns the lowercase mode name used in API responses.
+func (m Mode) String() string {
+	switch m {
+	case ModeNumeric:
+		return "numeric"
+	case ModeAlphanumeric:
+		return "alphanumeric"
+	case ModeByte:
+		return "byte"
+	case ModeKanji:
+		return "kanji"
+	case ModeECI:
+		return "eci"
+	case ModeStructuredAppend:
+		return "append"
+	}
+	return "unknown"
+}
+
+// hasCharCount reports whether segments of the mode carry a character
+// count field. ECI and Structured Append headers do not.
+func (m Mode) hasCharCount() bool {
+	return m != ModeECI && m != ModeStructuredAppend
+}
+
+// fieldSpec describes the segment header layout of a symbol version: the
+// width of the mode indicator, the indicator value of every supported mode
+// and the width of each character count field.
+type fieldSpec struct {
+	modeBits   int
+	indicators map[Mode]int
+	countBits  map[Mode]int
+}
+
+func (fs fieldSpec) supports(m Mode) bool {
+	_, ok := fs.indicators[m]
+	return ok
+}
+
+// qrFieldSpecs holds the QR Code header layouts for versions 1-9, 10-26
+// and 27-40.
+var qrFieldSpecs = func() [3]fieldSpec {
+	var specs [3]fieldSpec
+	for class := range specs {
+		specs[class] = fieldSpec{
+			modeBits: 4,
+			indicators: map[Mode]int{
+				ModeNumeric:          0x1,
+				ModeAlphanumeric:     0x2,
+				ModeByte:             0x4,
+				ModeKanji:            0x8,
+				ModeECI:              0x7,
+				ModeStructuredAppend: 0x3,
+			},
+			countBits: map[Mode]int{
+				ModeNumeric:      [3]int{10, 12, 14}[class],
+				ModeAlphanumeric: [3]int{9, 11, 13}[class],
+				ModeByte:         [3]int{8, 16, 16}[class],
+				ModeKanji:        [3]int{8, 10, 12}[class],
+			},
+		}
+	}
+	return specs
+}()
+
+// qrFields returns the header layout of a QR Code version.
+func qrFields(version int) fieldSpec {
+	return qrFieldSpecs[versionClass(version)]
+}
+
+// Encoding selects how the payload is split into segments.
+type Encoding int
+
+const (
+	// EncodingAuto picks the cheapest mix of segment modes for the payload.
+	EncodingAuto Encoding = iota
+	EncodingNumeric
+	EncodingAlphanumeric
+	EncodingByte
+	EncodingKanji
+)
+
+// ParseEncoding parses the encoding names accepted by the API.
+func ParseEncoding(s string) (Encoding, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "auto":
+		return EncodingAuto, nil
+	case "numeric":
+		return EncodingNumeric, nil
+	case "alphanumeric", "alnum":
+		return EncodingAlphanumeric, nil
+	case "byte", "binary":
+		return EncodingByte, nil
+	case "kanji":
+		return EncodingKanji, nil
+	}
+	return EncodingAuto, fmt.Errorf("unsupported encoding %q (use auto, numeric, alphanumeric, byte or kanji)", s)
+}
+
+// Charset is the character set used for byte segments. Every charset except
+// CharsetDefault is announced to readers with an ECI header.
+type Charset int
+
+const (
+	// CharsetDefault writes UTF-8 bytes without an ECI header, leaving the
+	// reader to guess the character set.
+	CharsetDefault Charset = iota
+	CharsetUTF8
+	CharsetISO8859_1
+	CharsetShiftJIS
+)
+
+// ParseCharset parses the eci names accepted by the API.
+func ParseCharset(s string) (Charset, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none":
+		return CharsetDefault, nil
+	case "utf8", "utf-8":
+		return CharsetUTF8, nil
+	case "iso8859-1", "iso-8859-1", "latin1":
+		return CharsetISO8859_1, nil
+	case "shift-jis", "shift_jis", "sjis":
+		return CharsetShiftJIS, nil
+	}
+	return CharsetDefault, fmt.Errorf("unsupported eci %q (use utf8, iso8859-1 or shift-jis)", s)
+}
+
+// String returns the API name of the charset.
+func (c Charset) String() string {
+	switch c {
+	case CharsetUTF8:
+		return "utf8"
+	case CharsetISO8859_1:
+		return "iso8859-1"
+	case CharsetShiftJIS:
+		return "shift-jis"
+	}
+	return "none"
+}
+
+// eciDesignator returns the ECI assignment number, or -1 when no ECI header
+// should be written.
+func (c Charset) eciDesignator() int {
+	switch c {
+	case CharsetUTF8:
+		return 26
+	case CharsetISO8859_1:
+		return 3
+	case CharsetShiftJIS:
+		return 20
+	}
+	return -1
+}
+
+// encodeRune returns the bytes representing r in the charset.
+func (c Charset) encodeRune(r rune) ([]byte, bool) {
+	switch c {
+	case CharsetISO8859_1:
+		b, ok := charmap.ISO8859_1.EncodeRune(r)
+		return []byte{b}, ok
+	case CharsetShiftJIS:
+		return shiftJISBytes(r)
+	}
+	if r == utf8.RuneError {
+		return nil, false
+	}
+	return []byte(string(r)), true
+}
+
+// shiftJISBytes returns the Shift JIS encoding of r.
+func shiftJISBytes(r rune) ([]byte, bool) {
+	b, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(string(r)))
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// kanjiValue returns the 13-bit Kanji mode value for r, or -1 when r is not
+// a double-byte Shift JIS character in the Kanji mode ranges.
+func kanjiValue(r rune) int {
+	b, ok := shiftJISBytes(r)
+	if !ok || len(b) != 2 {
+		return -1
+	}
+	code := int(b[0])<<8 | int(b[1])
+	switch {
+	case code >= 0x8140 && code <= 0x9FFC:
+		code -= 0x8140
+	case code >= 0xE040 && code <= 0xEBBF:
+		code -= 0xC140
+	default:
+		return -1
+	}
+	return (code>>8)*0xC0 + code&0xFF
+}
+
+const alphanumericCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// Segment is one run of the payload encoded in a single mode.
+type Segment struct {
+	Mode Mode
+	// Count is the number of characters in the segment (bytes for byte
+	// mode), the ECI designator for ECI segments, or the symbol position
+	// for Structured Append headers.
+	Count int
+
+	data bitBuffer
+}
+
+// bitLength returns the number of bits the segment takes with the header
+// layout fs, or -1 if the mode is not supported or the character count does
+// not fit its field.
+func (s Segment) bitLength(fs fieldSpec) int {
+	if !fs.supports(s.Mode) {
+		return -1
+	}
+	if !s.Mode.hasCharCount() {
+		return fs.modeBits + len(s.data)
+	}
+	ccBits := fs.countBits[s.Mode]
+	if s.Count >= 1<<uint(ccBits) {
+		return -1
+	}
+	return fs.modeBits + ccBits + len(s.data)
+}
+
+// totalBits returns the encoded length of segs, or -1 if any segment does
+// not fit the header layout.
+func totalBits(segs []Segment, fs fieldSpec) int {
+	n := 0
+	for _, s := range segs {
+		l := s.bitLength(fs)
+		if l < 0 {
+			return -1
+		}
+		n += l
+	}
+	return n
+}
+
+// payloadChar is a single payload character with its precomputed encodings.
+type payloadChar struct {
+	r     rune
+	bytes []byte
+	kanji int
+}
+
+func (p payloadChar) isNumeric() bool { return p.r >= '0' && p.r <= '9' }
+
+func (p payloadChar) isAlphanumeric() bool {
+	return p.r < 128 && strings.ContainsRune(alphanumericCharset, p.r)
+}
+
+// analyzePayload splits text into characters and encodes each one in the
+// byte-mode charset. Kanji values are only computed when withKanji is set.
+func analyzePayload(text string, cs Charset, withKanji bool) ([]payloadChar, error) {
+	chars := make([]payloadChar, 0, len(text))
+	for i, r := range text {
+		b, ok := cs.encodeRune(r)
+		if !ok {
+			return nil, fmt.Errorf("character %q at offset %d cannot be encoded as %s", r, i, cs)
+		}
+		k := -1
+		if withKanji {
+			k = kanjiValue(r)
+		}
+		chars = append(chars, payloadChar{r: r, bytes: b, kanji: k})
+	}
+	return chars, nil
+}
+
+// errModeUnsupported is returned by makeSegments when the payload needs a
+// mode the symbol version does not have.
+var errModeUnsupported = errors.New("mode not supported by this symbol version")
+
+// makeSegments encodes text for the requested encoding and charset. Auto
+// segmentation depends on the header layout, so the result is only optimal
+// for versions that share fs.
+func makeSegments(text string, enc Encoding, cs Charset, fs fieldSpec) ([]Segment, error) {
+	withKanji := enc == EncodingKanji || (enc == EncodingAuto && cs == CharsetShiftJIS)
+	chars, err := analyzePayload(text, cs, withKanji)
+	if err != nil {
+		return nil, err
+	}
+
+	var modes []Mode
+	switch enc {
+	case EncodingAuto:
+		modes = optimalModes(chars, fs, withKanji)
+		if modes == nil && len(chars) > 0 {
+			return nil, errModeUnsupported
+		}
+	default:
+		mode := map[Encoding]Mode{
+			EncodingNumeric:      ModeNumeric,
+			EncodingAlphanumeric: ModeAlphanumeric,
+			EncodingByte:         ModeByte,
+			EncodingKanji:        ModeKanji,
+		}[enc]
+		if !fs.supports(mode) {
+			return nil, errModeUnsupported
+		}
+		modes = make([]Mode, len(chars))
+		for i, ch := range chars {
+			if !modeAccepts(mode, ch) {
+				return nil, fmt.Errorf("character %q cannot be encoded in %s mode", ch.r, mode)
+			}
+			modes[i] = mode
+		}
+	}
+
+	var segs []Segment
+	if d := cs.eciDesignator(); d >= 0 {
+		segs = append(segs, eciSegment(d))
+	}
+	for start := 0; start < len(chars); {
+		end := start + 1
+		for end < len(chars) && modes[end] == modes[start] {
+			end++
+		}
+		segs = append(segs, encodeSegment(modes[start], chars[start:end]))
+		start = end
+	}
+	return segs, nil
+}
+
+// modeAccepts reports whether ch can be encoded in mode.
+func modeAccepts(mode Mode, ch payloadChar) bool {
+	switch mode {
+	case ModeNumeric:
+		return ch.isNumeric()
+	case ModeAlphanumeric:
+		return ch.isAlphanumeric()
+	case ModeKanji:
+		return ch.kanji >= 0
+	}
+	return true
+}
+
+// optimalModes assigns a mode to every character so that the total encoded
+// length is minimal, accounting for the header cost of switching modes.
+// Costs are tracked in sixths of a bit so alphanumeric (5.5 bits) and
+// numeric (3.33 bits) characters stay integral. It returns nil if some
+// character cannot be encoded in any mode fs supports.
+func optimalModes(chars []payloadChar, fs fieldSpec, withKanji bool) []Mode {
+	if len(chars) == 0 {
+		return nil
+	}
+	var modeTypes []Mode
+	for _, m := range []Mode{ModeByte, ModeAlphanumeric, ModeNumeric, ModeKanji} {
+		if fs.supports(m) && (m != ModeKanji || withKanji) {
+			modeTypes = append(modeTypes, m)
+		}
+	}
+	n := len(modeTypes)
+	const inf = int(^uint(0) >> 2)
+
+	headCosts := make([]int, n)
+	for i, m := range modeTypes {
+		headCosts[i] = (fs.modeBits + fs.countBits[m]) * 6
+	}
+
+	charModes := make([][]Mode, len(chars))
+	prevCosts := append([]int(nil), headCosts...)
+	for i, ch := range chars {
+		curCosts := make([]int, n)
+		curModes := make([]Mode, n)
+		valid := make([]bool, n)
+		for j := range curCosts {
+			curCosts[j] = inf
+		}
+		for j, m := range modeTypes {
+			if !modeAccepts(m, ch) {
+				continue
+			}
+			var cost int
+			switch m {
+			case ModeByte:
+				cost = len(ch.bytes) * 8 * 6
+			case ModeAlphanumeric:
+				cost = 33
+			case ModeNumeric:
+				cost = 20
+			case ModeKanji:
+				cost = 13 * 6
+			}
+			curCosts[j] = prevCosts[j] + cost
+			curModes[j] = m
+			valid[j] = true
+		}
+		// Allow a new segment to start after this character.
+		for j := range modeTypes {
+			for k := range modeTypes {
+				if !valid[k] {
+					continue
+				}
+				newCost := (curCosts[k]+5)/6*6 + headCosts[j]
+				if newCost < curCosts[j] {
+					curCosts[j] = newCost
+					curModes[j] = modeTypes[k]
+					valid[j] = true
+				}
+			}
+		}
+		charModes[i] = curModes
+		prevCosts = curCosts
+	}
+
+	best := 0
+	for j := range modeTypes {
+		if prevCosts[j] < prevCosts[best] {
+			best = j
+		}
+	}
+	if prevCosts[best] >= inf {
+		return nil
+	}
+	result := make([]Mode, len(chars))
+	cur := modeTypes[best]
+	for i := len(chars) - 1; i >= 0; i-- {
+		for j, m := range modeTypes {
+			if m == cur {
+				cur = charModes[i][j]
+				result[i] = cur
+				break
+			}
+		}
+	}
+	return result
+}
+
+// encodeSegment encodes a run of characters that share a mode.
+func encodeSegment(mode Mode, chars []payloadChar) Segment {
+	var bb bitBuffer
+	count := len(chars)
+	switch mode {
+	case ModeNumeric:
+		for i := 0; i < len(chars); i += 3 {
+			end := i + 3
+			if end > len(chars) {
+				end = len(chars)
+			}
+			v := 0
+			for _, ch := range chars[i:end] {
+				v = v*10 + int(ch.r-'0')
+			}
+			bb.appendBits(v, (end-i)*3+1)
+		}
+	case ModeAlphanumeric:
+		for i := 0; i < len(chars); i += 2 {
+			v := strings.IndexRune(alphanumericCharset, chars[i].r)
+			if i+1 < len(chars) {
+				v = v*45 + strings.IndexRune(alphanumericCharset, chars[i+1].r)
+				bb.appendBits(v, 11)
+			} else {
+				bb.appendBits(v, 6)
+			}
+		}
+	case ModeKanji:
+		for _, ch := range chars {
+			bb.appendBits(ch.kanji, 13)
+		}
+	default:
+		count = 0
+		for _, ch := range chars {
+			for _, b := range ch.bytes {
+				bb.appendBits(int(b), 8)
+				count++
+			}
+		}
+	}
+	return Segment{Mode: mode, Count: count, data: bb}
+}
+
+// eciSegment returns an ECI header announcing the given designator.
+func eciSegment(designator int) Segment {
+	var bb bitBuffer
+	switch {
+	case designator < 1<<7:
+		bb.appendBits(designator, 8)
+	case designator < 1<<14:
+		bb.appendBits(0x2, 2)
+		bb.appendBits(designator, 14)
+	default:
+		bb.appendBits(0x6, 3)
+		bb.appendBits(designator, 21)
+	}
+	return Segment{Mode: ModeECI, Count: designator, data: bb}
+}
+
+// structuredAppendSegment returns the header marking a symbol as part index
+// (0-based) of total, with the parity of the whole message.
+func structuredAppendSegment(index, total int, parity byte) Segment {
+	var bb bitBuffer
+	bb.appendBits(index, 4)
+	bb.appendBits(total-1, 4)
+	bb.appendBits(int(parity), 8)
+	return Segment{Mode: ModeStructuredAppend, Count: index, data: bb}
+}
+
+// bitBuffer is a sequence of bits, one per element.
+type bitBuffer []byte
+
+// appendBits appends the n low-order bits of v, most significant first.
+func (b *bitBuffer) appendBits(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, byte(v>>uint(i))&1)
+	}
+}
+
+// appendBytes appends every bit of data.
+func (b *bitBuffer) appendBytes(data []byte) {
+	for _, v := range data {
+		b.appendBits(int(v), 8)
+	}
+}
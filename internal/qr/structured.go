@@ -0,0 +1,105 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxAppendSymbols is the longest Structured Append sequence a reader can
+// reassemble.
+const MaxAppendSymbols = 16
+
+// StructuredAppend is the position of a symbol in a Structured Append
+// sequence.
+type StructuredAppend struct {
+	// Index is the 0-based position of the symbol; Total is the number of
+	// symbols in the sequence.
+	Index, Total int
+	// Parity is the XOR of every payload byte of the whole message, used by
+	// readers to check that the symbols belong together.
+	Parity byte
+}
+
+// EncodeStructured splits text across a Structured Append sequence. symbols
+// fixes the number of symbols (2-16); zero picks the fewest symbols whose
+// versions stay within the bounds in opts.
+func EncodeStructured(text string, opts Options, symbols int) ([]*Code, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Symbology != SymbologyQR {
+		return nil, fmt.Errorf("structured append is only supported for qr")
+	}
+	if symbols != 0 && (symbols < 2 || symbols > MaxAppendSymbols) {
+		return nil, fmt.Errorf("symbols must be between 2 and %d", MaxAppendSymbols)
+	}
+	chars, err := analyzePayload(text, opts.Charset, false)
+	if err != nil {
+		return nil, err
+	}
+	var parity byte
+	for _, ch := range chars {
+		for _, b := range ch.bytes {
+			parity ^= b
+		}
+	}
+
+	if symbols != 0 {
+		return encodeParts(chars, symbols, parity, opts)
+	}
+	for n := 2; n <= MaxAppendSymbols && n <= len(chars); n++ {
+		codes, err := encodeParts(chars, n, parity, opts)
+		if errors.Is(err, ErrDataTooLong) {
+			continue
+		}
+		return codes, err
+	}
+	return nil, fmt.Errorf("payload does not fit in %d symbols: %w", MaxAppendSymbols, ErrDataTooLong)
+}
+
+// encodeParts encodes chars as a sequence of n symbols of roughly equal
+// payload size.
+func encodeParts(chars []payloadChar, n int, parity byte, opts Options) ([]*Code, error) {
+	parts := splitPayload(chars, n)
+	if len(parts) < n {
+		return nil, fmt.Errorf("payload is too short for %d symbols", n)
+	}
+	codes := make([]*Code, n)
+	for i, part := range parts {
+		header := []Segment{structuredAppendSegment(i, n, parity)}
+		code, err := encode(part, opts, header)
+		if err != nil {
+			return nil, fmt.Errorf("symbol %d of %d: %w", i+1, n, err)
+		}
+		code.Append = &StructuredAppend{Index: i, Total: n, Parity: parity}
+		codes[i] = code
+	}
+	return codes, nil
+}
+
+// splitPayload cuts chars into at most n non-empty parts of roughly equal
+// encoded byte length, never splitting a character.
+func splitPayload(chars []payloadChar, n int) []string {
+	total := 0
+	for _, ch := range chars {
+		total += len(ch.bytes)
+	}
+	parts := make([]string, 0, n)
+	var sb strings.Builder
+	size := 0
+	for i, ch := range chars {
+		sb.WriteRune(ch.r)
+		size += len(ch.bytes)
+		remaining := len(chars) - i - 1
+		target := total * (len(parts) + 1) / n
+		if len(parts) < n-1 && remaining > 0 && (size >= target || remaining == n-1-len(parts)) {
+			parts = append(parts, sb.String())
+			sb.Reset()
+		}
+	}
+	if sb.Len() > 0 {
+		parts = append(parts, sb.String())
+	}
+	return parts
+}
@@ -0,0 +1,69 @@
+package qr
+
+// eccCodewordsPerBlock holds the number of error correction codewords in each
+// block, indexed by [ECLevel][version]. Index 0 is unused.
+var eccCodewordsPerBlock = [4][41]int{
+	// L
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	// M
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	// Q
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	// H
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// numErrorCorrectionBlocks holds the number of Reed-Solomon blocks, indexed
+// by [ECLevel][version]. Index 0 is unused.
+var numErrorCorrectionBlocks = [4][41]int{
+	// L
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	// M
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	// Q
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	// H
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// numRawDataModules returns the number of modules available for data and
+// error correction codewords in a symbol of the given version, i.e. everything
+// except function patterns, format and version information.
+func numRawDataModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		n -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+// numDataCodewords returns the number of 8-bit data codewords (excluding
+// error correction) a symbol of the given version and level can hold.
+func numDataCodewords(version int, level ECLevel) int {
+	return numRawDataModules(version)/8 -
+		eccCodewordsPerBlock[level][version]*numErrorCorrectionBlocks[level][version]
+}
+
+// alignmentPatternPositions returns the row/column coordinates of the
+// alignment pattern centers for the given version.
+func alignmentPatternPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := 26
+	if version != 32 {
+		step = (version*4 + numAlign*2 + 1) / (numAlign*2 - 2) * 2
+	}
+	size := version*4 + 17
+	pos := make([]int, numAlign)
+	pos[0] = 6
+	for i, p := numAlign-1, size-7; i >= 1; i, p = i-1, p-step {
+		pos[i] = p
+	}
+	return pos
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy is the rate and burst of a Limiter: N tokens every Period, and up
+// to Burst at once. The zero Policy limits nothing.
+type Policy struct {
+	N      int
+	Period time.Duration
+	Burst  int
+}
+
+// Limiter returns a new limiter with the policy, or nil for the zero
+// policy.
+func (p Policy) Limiter() *Limiter {
+	if p.N <= 0 || p.Period <= 0 {
+		return nil
+	}
+	return New(p.N, p.Period, max(p.Burst, 1))
+}
+
+func (p Policy) String() string {
+	if p.N <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s:%d", p.N, p.Period, p.Burst)
+}
+
+// ParsePolicies reads named policies as comma-separated name=N/period:burst
+// entries, like "render=300/1m:120,batch=off", over a copy of defaults.
+// The burst defaults to N, and "off" turns a policy off. Only the names in
+// defaults are accepted.
+func ParsePolicies(s string, defaults map[string]Policy) (map[string]Policy, error) {
+	policies := maps.Clone(defaults)
+	for entry := range strings.SplitSeq(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if _, known := defaults[name]; !ok || !known {
+			return nil, fmt.Errorf("invalid rate limit %q: unknown name", entry)
+		}
+		p, err := parsePolicy(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit %q: %w", entry, err)
+		}
+		policies[name] = p
+	}
+	return policies, nil
+}
+
+func parsePolicy(spec string) (Policy, error) {
+	if spec == "off" {
+		return Policy{}, nil
+	}
+	rate, burst, hasBurst := strings.Cut(spec, ":")
+	n, period, ok := strings.Cut(rate, "/")
+	var p Policy
+	var err error
+	if p.N, err = strconv.Atoi(n); !ok || err != nil || p.N <= 0 {
+		return Policy{}, fmt.Errorf("want N/period[:burst] or off")
+	}
+	if p.Period, err = time.ParseDuration(period); err != nil || p.Period <= 0 {
+		return Policy{}, fmt.Errorf("invalid period %q", period)
+	}
+	p.Burst = p.N
+	if hasBurst {
+		if p.Burst, err = strconv.Atoi(burst); err != nil || p.Burst <= 0 {
+			return Policy{}, fmt.Errorf("invalid burst %q", burst)
+		}
+	}
+	return p, nil
+}
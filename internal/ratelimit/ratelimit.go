@@ -0,0 +1,109 @@
+// Package ratelimit limits how often each key, such as a client address,
+// may do something. Every key has a token bucket kept in memory: it holds
+// up to burst tokens and refills at a steady rate.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter is a set of token buckets with the same rate and burst. It is
+// safe for concurrent use.
+type Limiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+
+	// Now returns the current time; nil uses time.Now.
+	Now func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New returns a limiter that allows burst tokens at once and refills n
+// tokens every period.
+func New(n int, period time.Duration, burst int) *Limiter {
+	return &Limiter{
+		rate:    float64(n) / period.Seconds(),
+		burst:   float64(burst),
+		buckets: map[string]*bucket{},
+	}
+}
+
+func (l *Limiter) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
+
+// Allow takes cost tokens from the bucket of key. When there are not
+// enough, it takes none and returns false with the time until there will
+// be.
+func (l *Limiter) Allow(key string, cost float64) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= cost {
+		b.tokens -= cost
+		return true, 0
+	}
+	wait := (cost - b.tokens) / l.rate
+	if cost > l.burst || math.IsInf(wait, 0) {
+		// The bucket can never hold enough
+		return false, time.Duration(math.MaxInt64)
+	}
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// Burst returns the most tokens a bucket holds.
+func (l *Limiter) Burst() float64 {
+	return l.burst
+}
+
+// Full reports whether the bucket of key holds all its tokens, i.e. key
+// has taken none lately. It takes nothing.
+func (l *Limiter) Full(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	return !ok || b.tokens+l.now().Sub(b.last).Seconds()*l.rate >= l.burst
+}
+
+// Reset forgets the bucket of key, e.g. after a successful attempt.
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.buckets, key)
+}
+
+// sweep drops the buckets that have refilled completely, at most once a
+// minute, so idle keys do not pile up.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package ratelimit
+
+import "context"
+
+// Semaphore bounds how many callers do something at once. A nil Semaphore
+// bounds nothing.
+type Semaphore chan struct{}
+
+// NewSemaphore returns a semaphore with n slots, or nil if n is not
+// positive.
+func NewSemaphore(n int) Semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return make(Semaphore, n)
+}
+
+// Acquire waits for a free slot until ctx is done.
+func (s Semaphore) Acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire.
+func (s Semaphore) Release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// InUse returns the number of slots taken.
+func (s Semaphore) InUse() int {
+	return len(s)
+}
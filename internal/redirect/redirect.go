@@ -0,0 +1,192 @@
+// Package redirect picks the destination of a scan of a dynamic code from
+// the code's rules. Resolution is a pure function of the link and the
+// scan: the clock is a field of Scan, and splits hash the visitor instead
+// of drawing random numbers, so the same scan always gets the same
+// destination.
+package redirect
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/analytics"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+)
+
+// Fallback is the rule index Resolve returns for the link's own target.
+const Fallback = -1
+
+// Scan is what rules can depend on.
+type Scan struct {
+	Time     time.Time
+	Device   string // analytics device class
+	OS       string // analytics operating system
+	Language string // preferred language tag, lower case
+	// Visitor identifies the visitor for splits, e.g. their address and
+	// User-Agent. It is only hashed, never stored.
+	Visitor string
+}
+
+// NewScan describes a scan at time now from the headers of the request and
+// the client address.
+func NewScan(now time.Time, userAgent, acceptLanguage, address string) Scan {
+	return Scan{
+		Time:     now,
+		Device:   analytics.Device(userAgent),
+		OS:       analytics.OS(userAgent),
+		Language: PreferredLanguage(acceptLanguage),
+		Visitor:  address + " " + userAgent,
+	}
+}
+
+// Resolve returns the destination of scan and the index of the rule that
+// picked it, or Fallback.
+func Resolve(l *design.Link, scan Scan) (string, int) {
+	for i := range l.Rules {
+		r := &l.Rules[i]
+		if !Matches(&r.When, scan) {
+			continue
+		}
+		if len(r.Split) > 0 {
+			return pick(r.Split, scan.Visitor, i), i
+		}
+		return r.Target, i
+	}
+	return l.Target, Fallback
+}
+
+// Matches reports whether every condition of c holds for scan.
+func Matches(c *design.Condition, scan Scan) bool {
+	if c.Start != nil && scan.Time.Before(*c.Start) {
+		return false
+	}
+	if c.End != nil && !scan.Time.Before(*c.End) {
+		return false
+	}
+	if len(c.Days) > 0 || c.From != "" {
+		local := scan.Time.In(location(c.TimeZone))
+		if len(c.Days) > 0 && !containsFold(c.Days, dayNames[local.Weekday()]) {
+			return false
+		}
+		if c.From != "" && !inWindow(local, c.From, c.Until) {
+			return false
+		}
+	}
+	if len(c.Devices) > 0 && !containsFold(c.Devices, scan.Device) {
+		return false
+	}
+	if len(c.OS) > 0 && !containsFold(c.OS, scan.OS) {
+		return false
+	}
+	if len(c.Languages) > 0 && !matchesLanguage(c.Languages, scan.Language) {
+		return false
+	}
+	return true
+}
+
+var dayNames = [...]string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// inWindow reports whether the time of day of t is in [from, until), both
+// as HH:MM. A window whose end is before its start runs past midnight.
+func inWindow(t time.Time, from, until string) bool {
+	now := t.Hour()*60 + t.Minute()
+	start, end := minutes(from), minutes(until)
+	if start <= end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// minutes converts a validated HH:MM to minutes after midnight.
+func minutes(hhmm string) int {
+	h, _ := strconv.Atoi(hhmm[:2])
+	m, _ := strconv.Atoi(hhmm[3:])
+	return h*60 + m
+}
+
+// pick chooses a variant of a split by hashing the visitor with the rule
+// index, so each visitor lands on the same variant every time.
+func pick(split []design.Variant, visitor string, rule int) string {
+	total := 0
+	for _, v := range split {
+		total += v.Share()
+	}
+	h := fnv.New64a()
+	h.Write([]byte(strconv.Itoa(rule) + "\x00" + visitor))
+	n := int(h.Sum64() % uint64(total))
+	for _, v := range split {
+		if n < v.Share() {
+			return v.Target
+		}
+		n -= v.Share()
+	}
+	return split[len(split)-1].Target
+}
+
+// PreferredLanguage returns the language tag with the highest quality in
+// an Accept-Language header, in lower case, or "" if there is none.
+func PreferredLanguage(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
+
+// matchesLanguage reports whether lang is one of tags or a subtag of one
+// of them: es matches es and es-ar, pt-br only pt-br.
+func matchesLanguage(tags []string, lang string) bool {
+	if lang == "" {
+		return false
+	}
+	for _, tag := range tags {
+		tag = strings.ToLower(tag)
+		if lang == tag || strings.HasPrefix(lang, tag+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// locations caches loaded time zones, which are read from disk.
+var locations sync.Map
+
+// location returns the validated time zone name, UTC if it cannot be
+// loaded.
+func location(name string) *time.Location {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = time.UTC
+	}
+	locations.Store(name, loc)
+	return loc
+}
@@ -0,0 +1,197 @@
+package redirect
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+)
+
+// friday is a fixed scan time: Friday 6 March 2026, 12:00 UTC.
+var friday = time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC)
+
+// at returns friday with the time of day set to hh:mm UTC.
+func at(hh, mm int) time.Time {
+	return time.Date(friday.Year(), friday.Month(), friday.Day(), hh, mm, 0, 0, time.UTC)
+}
+
+func TestMatchesSchedule(t *testing.T) {
+	start, end := at(9, 0), at(18, 0)
+	tests := []struct {
+		name string
+		when design.Condition
+		time time.Time
+		want bool
+	}{
+		{"window", design.Condition{From: "09:00", Until: "17:00"}, at(9, 0), true},
+		{"window end is exclusive", design.Condition{From: "09:00", Until: "17:00"}, at(17, 0), false},
+		{"before window", design.Condition{From: "09:00", Until: "17:00"}, at(8, 59), false},
+		{"past midnight, late", design.Condition{From: "22:00", Until: "06:00"}, at(23, 30), true},
+		{"past midnight, early", design.Condition{From: "22:00", Until: "06:00"}, at(5, 59), true},
+		{"past midnight, end", design.Condition{From: "22:00", Until: "06:00"}, at(6, 0), false},
+		{"past midnight, midday", design.Condition{From: "22:00", Until: "06:00"}, at(12, 0), false},
+		{"time zone", design.Condition{From: "09:00", Until: "17:00", TimeZone: "America/New_York"}, at(14, 0), true},
+		{"time zone, before", design.Condition{From: "09:00", Until: "17:00", TimeZone: "America/New_York"}, at(13, 59), false},
+		{"time zone, past midnight", design.Condition{From: "22:00", Until: "02:00", TimeZone: "Asia/Tokyo"}, at(16, 30), true},
+		{"day", design.Condition{Days: []string{"fri"}}, friday, true},
+		{"other day", design.Condition{Days: []string{"sat", "sun"}}, friday, false},
+		{"day in time zone", design.Condition{Days: []string{"sat"}, TimeZone: "Asia/Tokyo"}, at(20, 0), true},
+		{"day in UTC", design.Condition{Days: []string{"sat"}, TimeZone: "UTC"}, at(20, 0), false},
+		{"unknown time zone is UTC", design.Condition{From: "09:00", Until: "17:00", TimeZone: "Nowhere/Nothing"}, at(16, 0), true},
+		{"start", design.Condition{Start: &start}, at(9, 0), true},
+		{"before start", design.Condition{Start: &start}, at(8, 59), false},
+		{"end is exclusive", design.Condition{End: &end}, at(18, 0), false},
+		{"empty", design.Condition{}, friday, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(&tt.when, Scan{Time: tt.time}); got != tt.want {
+				t.Errorf("Matches at %s = %v, want %v", tt.time.Format(time.RFC3339), got, tt.want)
+			}
+		})
+	}
+}
+
+const (
+	iPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+	android = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+	iPad    = "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+	mac     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Version/17.0 Safari/605.1.15"
+)
+
+func TestMatchesDevice(t *testing.T) {
+	tests := []struct {
+		name      string
+		when      design.Condition
+		userAgent string
+		want      bool
+	}{
+		{"mobile", design.Condition{Devices: []string{"mobile"}}, iPhone, true},
+		{"mobile, desktop", design.Condition{Devices: []string{"mobile"}}, mac, false},
+		{"tablet is not mobile", design.Condition{Devices: []string{"mobile"}}, iPad, false},
+		{"tablet", design.Condition{Devices: []string{"tablet", "desktop"}}, iPad, true},
+		{"os", design.Condition{OS: []string{"android"}}, android, true},
+		{"other os", design.Condition{OS: []string{"android"}}, iPhone, false},
+		{"ios tablet", design.Condition{OS: []string{"ios"}}, iPad, true},
+		{"case", design.Condition{OS: []string{"MacOS"}}, mac, true},
+		{"device and os", design.Condition{Devices: []string{"mobile"}, OS: []string{"ios"}}, android, false},
+		{"no user agent", design.Condition{Devices: []string{"mobile", "tablet", "desktop"}}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scan := NewScan(friday, tt.userAgent, "", "192.0.2.1")
+			if got := Matches(&tt.when, scan); got != tt.want {
+				t.Errorf("Matches(%s/%s) = %v, want %v", scan.Device, scan.OS, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	tests := []struct {
+		header, want string
+	}{
+		{"", ""},
+		{"es-AR", "es-ar"},
+		{"en-US,en;q=0.9,es;q=0.8", "en-us"},
+		{"de;q=0.5, fr;q=0.9, it;q=0.7", "fr"},
+		{"fr;q=0.9, es", "es"},
+		{"*, pt-BR;q=0.8", "pt-br"},
+		{"es;q=0.8, en;q=0.8", "es"},
+		{"en;q=bad, de;q=0.1", "de"},
+		{"en;q=0", ""},
+	}
+	for _, tt := range tests {
+		if got := PreferredLanguage(tt.header); got != tt.want {
+			t.Errorf("PreferredLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesLanguage(t *testing.T) {
+	tests := []struct {
+		languages []string
+		header    string
+		want      bool
+	}{
+		{[]string{"es"}, "es-AR,en;q=0.5", true},
+		{[]string{"es"}, "en,es-AR;q=0.5", false},
+		{[]string{"pt-BR"}, "pt-br", true},
+		{[]string{"pt-BR"}, "pt", false},
+		{[]string{"pt-BR"}, "pt-PT", false},
+		{[]string{"en", "de"}, "de-CH", true},
+		{[]string{"es"}, "", false},
+	}
+	for _, tt := range tests {
+		when := design.Condition{Languages: tt.languages}
+		if got := Matches(&when, NewScan(friday, mac, tt.header, "192.0.2.1")); got != tt.want {
+			t.Errorf("languages %v, Accept-Language %q: Matches = %v, want %v", tt.languages, tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	link := &design.Link{
+		Target: "https://example.com/",
+		Rules: []design.Rule{
+			{When: design.Condition{From: "22:00", Until: "06:00"}, Target: "https://example.com/night"},
+			{When: design.Condition{Devices: []string{"mobile"}, Languages: []string{"es"}}, Target: "https://example.com/es/app"},
+			{When: design.Condition{Devices: []string{"mobile"}}, Target: "https://example.com/app"},
+		},
+	}
+	tests := []struct {
+		name      string
+		scan      Scan
+		want      string
+		wantIndex int
+	}{
+		{"first match wins", NewScan(at(23, 0), iPhone, "es", "192.0.2.1"), "https://example.com/night", 0},
+		{"all conditions", NewScan(friday, iPhone, "es-MX", "192.0.2.1"), "https://example.com/es/app", 1},
+		{"next rule", NewScan(friday, iPhone, "en", "192.0.2.1"), "https://example.com/app", 2},
+		{"fallback", NewScan(friday, mac, "es", "192.0.2.1"), "https://example.com/", Fallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, i := Resolve(link, tt.scan)
+			if got != tt.want || i != tt.wantIndex {
+				t.Errorf("Resolve = %q, %d, want %q, %d", got, i, tt.want, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestResolveSplit(t *testing.T) {
+	link := &design.Link{
+		Target: "https://example.com/",
+		Rules: []design.Rule{{Split: []design.Variant{
+			{Target: "https://example.com/a", Weight: 1},
+			{Target: "https://example.com/b", Weight: 3},
+			{Target: "https://example.com/c"}, // counts as 1
+		}}},
+	}
+	want := map[string]float64{
+		"https://example.com/a": 0.2,
+		"https://example.com/b": 0.6,
+		"https://example.com/c": 0.2,
+	}
+	const visitors = 20000
+	counts := map[string]int{}
+	for i := range visitors {
+		scan := NewScan(friday, mac, "", fmt.Sprintf("198.51.100.%d:%d", i%256, i))
+		got, _ := Resolve(link, scan)
+		counts[got]++
+
+		// The same visitor keeps their variant, whatever the time
+		scan.Time = scan.Time.Add(time.Duration(i) * time.Hour)
+		if again, _ := Resolve(link, scan); again != got {
+			t.Fatalf("visitor %q got %q, then %q", scan.Visitor, got, again)
+		}
+	}
+	for target, share := range want {
+		if got := float64(counts[target]) / visitors; got < share-0.02 || got > share+0.02 {
+			t.Errorf("%s got %.3f of the visitors, want %.2f", target, got, share)
+		}
+	}
+}
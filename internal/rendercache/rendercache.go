@@ -0,0 +1,326 @@
+// Package rendercache keeps rendered images so identical requests are not
+// rendered again. Entries are addressed by a hash of everything that went
+// into the image and kept in memory, least recently used first out, with
+// an optional second tier of files on disk.
+package rendercache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// entryOverhead approximates the memory an entry takes besides its body.
+const entryOverhead = 128
+
+// tmpPrefix starts the names of files being written to the disk tier.
+const tmpPrefix = ".tmp-"
+
+// Options configures a Cache.
+type Options struct {
+	MemoryBytes int64  // bound of the memory tier
+	Dir         string // directory of the disk tier; empty disables it
+	DiskBytes   int64  // bound of the disk tier
+}
+
+// Cache is a two-tier cache of rendered images. It is safe for concurrent
+// use.
+type Cache struct {
+	dir string
+
+	mu   sync.Mutex
+	mem  *lru
+	disk *lru // nil without a disk tier
+
+	hits, misses int64
+
+	writes sync.WaitGroup // disk writes in progress
+}
+
+// New returns a cache. With a directory, files left there by an earlier
+// run are picked up, oldest first out.
+func New(opts Options) (*Cache, error) {
+	c := &Cache{dir: opts.Dir, mem: newLRU(opts.MemoryBytes)}
+	if opts.Dir == "" {
+		return c, nil
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	c.disk = newLRU(opts.DiskBytes)
+
+	type file struct {
+		key  string
+		size int64
+		mod  time.Time
+	}
+	// Only the shard subdirectories are read, and only the cache's own
+	// files in them are touched, so a directory shared by mistake loses
+	// nothing
+	shards, err := os.ReadDir(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("render cache: %w", err)
+	}
+	var files []file
+	for _, shard := range shards {
+		if !shard.IsDir() || !validShard(shard.Name()) {
+			continue
+		}
+		dir := filepath.Join(opts.Dir, shard.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("render cache: %w", err)
+		}
+		for _, e := range entries {
+			name := e.Name()
+			switch {
+			case !e.Type().IsRegular():
+			case strings.HasPrefix(name, tmpPrefix):
+				// An interrupted write
+				os.Remove(filepath.Join(dir, name))
+			case validKey(name) && name[:2] == shard.Name():
+				info, err := e.Info()
+				if err != nil {
+					return nil, fmt.Errorf("render cache: %w", err)
+				}
+				files = append(files, file{name, info.Size(), info.ModTime()})
+			}
+		}
+	}
+	slices.SortFunc(files, func(a, b file) int { return a.mod.Compare(b.mod) })
+	for _, f := range files {
+		for _, key := range c.disk.add(f.key, f.size, nil) {
+			os.Remove(c.path(key))
+		}
+	}
+	return c, nil
+}
+
+// Get returns the body cached under key. A body found on disk is moved
+// back to memory.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	if body, ok := c.mem.get(key); ok {
+		c.hits++
+		c.mu.Unlock()
+		return body, true
+	}
+	onDisk := false
+	if c.disk != nil {
+		_, onDisk = c.disk.get(key)
+	}
+	c.mu.Unlock()
+
+	if onDisk {
+		if body, err := os.ReadFile(c.path(key)); err == nil {
+			c.mu.Lock()
+			c.hits++
+			c.mem.add(key, int64(len(body)), body)
+			c.mu.Unlock()
+			return body, true
+		}
+	}
+	c.mu.Lock()
+	c.misses++
+	c.mu.Unlock()
+	return nil, false
+}
+
+// Put caches body under key. The disk copy is written in the background.
+func (c *Cache) Put(key string, body []byte) {
+	if c == nil || !validKey(key) {
+		return
+	}
+	c.mu.Lock()
+	c.mem.add(key, int64(len(body)), body)
+	c.mu.Unlock()
+	if c.disk != nil {
+		c.writes.Add(1)
+		go func() {
+			defer c.writes.Done()
+			c.write(key, body)
+		}()
+	}
+}
+
+// Close waits for the disk writes in progress, so none is left half
+// written when the process exits.
+func (c *Cache) Close() {
+	if c == nil {
+		return
+	}
+	c.writes.Wait()
+}
+
+// write saves body to the disk tier, replacing the file atomically.
+func (c *Cache) write(key string, body []byte) {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		slog.Warn("render cache write failed", "err", err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), tmpPrefix+"*")
+	if err != nil {
+		slog.Warn("render cache write failed", "err", err)
+		return
+	}
+	_, err = tmp.Write(body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		slog.Warn("render cache write failed", "err", err)
+		return
+	}
+	c.mu.Lock()
+	evicted := c.disk.add(key, int64(len(body)), nil)
+	c.mu.Unlock()
+	for _, k := range evicted {
+		os.Remove(c.path(k))
+	}
+}
+
+// Stats returns the number of lookups that hit and missed, and the bytes
+// held in memory and on disk.
+func (c *Cache) Stats() (hits, misses, memBytes, diskBytes int64) {
+	if c == nil {
+		return 0, 0, 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.disk != nil {
+		diskBytes = c.disk.size
+	}
+	return c.hits, c.misses, c.mem.size, diskBytes
+}
+
+// path returns the file of key, spread over 256 subdirectories.
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// Key returns the cache key of the parts of a render. It also covers the
+// build of the server, so images rendered by an older version are not
+// served after an upgrade.
+func Key(parts ...[]byte) string {
+	h := sha256.New()
+	h.Write([]byte(build()))
+	for _, p := range parts {
+		fmt.Fprintf(h, "%d:", len(p))
+		h.Write(p)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// build identifies the running binary: its VCS revision, or the size and
+// time of the executable when it was built without one.
+var build = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		var rev, modified string
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				rev = s.Value
+			case "vcs.modified":
+				modified = s.Value
+			}
+		}
+		if rev != "" && modified != "true" {
+			return rev
+		}
+	}
+	if exe, err := os.Executable(); err == nil {
+		if info, err := os.Stat(exe); err == nil {
+			return fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return ""
+})
+
+// validShard reports whether name can be a subdirectory made by path.
+func validShard(name string) bool {
+	if len(name) != 2 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
+
+func validKey(key string) bool {
+	if len(key) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
+
+// lru tracks entries by size, evicting the least recently used ones once
+// the total is over max. Values are optional.
+type lru struct {
+	max   int64
+	size  int64
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	size  int64
+	value []byte
+}
+
+func newLRU(max int64) *lru {
+	return &lru{max: max, order: list.New(), items: map[string]*list.Element{}}
+}
+
+func (l *lru) get(key string) ([]byte, bool) {
+	e, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+// add inserts or replaces an entry and returns the keys it evicted. An
+// entry larger than the whole cache is not kept and is returned as
+// evicted itself.
+func (l *lru) add(key string, size int64, value []byte) []string {
+	size += entryOverhead
+	if e, ok := l.items[key]; ok {
+		l.size -= e.Value.(*lruEntry).size
+		l.order.Remove(e)
+		delete(l.items, key)
+	}
+	if size > l.max {
+		return []string{key}
+	}
+	l.items[key] = l.order.PushFront(&lruEntry{key, size, value})
+	l.size += size
+	var evicted []string
+	for l.size > l.max {
+		e := l.order.Back()
+		old := e.Value.(*lruEntry)
+		l.order.Remove(e)
+		delete(l.items, old.key)
+		l.size -= old.size
+		evicted = append(evicted, old.key)
+	}
+	return evicted
+}
@@ -0,0 +1,165 @@
+package screen
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Blocklist holds blocked hosts and Safe Browsing style hash prefixes of
+// blocked URLs. It is read-only once loaded and safe for concurrent use.
+type Blocklist struct {
+	domains map[string]bool
+	// prefixes maps a prefix length in bytes to the prefixes of that length.
+	prefixes map[int]map[string]bool
+}
+
+// LoadBlocklist reads blocklist files. Each line of a file is one of:
+//
+//   - a domain, like example.com, which blocks it and all its subdomains;
+//     a leading "*." or "." is allowed, so .zip blocks a whole TLD;
+//   - a hosts file entry, like "0.0.0.0 example.com www.example.com";
+//   - a hex SHA-256 hash prefix of 4 to 32 bytes of a URL expression in the
+//     Safe Browsing format, like the hash of "example.com/login/".
+//
+// Empty lines and text after "#" are ignored.
+func LoadBlocklist(paths ...string) (*Blocklist, error) {
+	l := &Blocklist{domains: map[string]bool{}, prefixes: map[int]map[string]bool{}}
+	for _, path := range paths {
+		if err := l.load(path); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *Blocklist) load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		text, _, _ := strings.Cut(s.Text(), "#")
+		fields := strings.Fields(strings.ToLower(text))
+		if len(fields) == 0 {
+			continue
+		}
+		if _, err := netip.ParseAddr(fields[0]); err == nil {
+			for _, host := range fields[1:] {
+				l.addDomain(host)
+			}
+			continue
+		}
+		if len(fields) > 1 {
+			return fmt.Errorf("%s:%d: want a domain, a hosts file entry or a hash prefix", path, line)
+		}
+		if prefix, err := hex.DecodeString(fields[0]); err == nil && len(prefix) >= 4 && len(prefix) <= sha256.Size {
+			if l.prefixes[len(prefix)] == nil {
+				l.prefixes[len(prefix)] = map[string]bool{}
+			}
+			l.prefixes[len(prefix)][string(prefix)] = true
+			continue
+		}
+		l.addDomain(strings.TrimPrefix(fields[0], "*"))
+	}
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func (l *Blocklist) addDomain(host string) {
+	host = strings.Trim(host, ".")
+	switch host {
+	case "", "localhost", "localhost.localdomain", "local", "broadcasthost", "ip6-localhost", "ip6-loopback":
+		return
+	}
+	if ascii, err := idnaProfile.ToASCII(host); err == nil {
+		host = ascii
+	}
+	l.domains[host] = true
+}
+
+// Len returns the number of domains and hash prefixes in the list.
+func (l *Blocklist) Len() int {
+	if l == nil {
+		return 0
+	}
+	n := len(l.domains)
+	for _, p := range l.prefixes {
+		n += len(p)
+	}
+	return n
+}
+
+// Match returns the listed domain or URL expression that u, on the ASCII
+// host name host, falls under, or "". A nil Blocklist matches nothing.
+func (l *Blocklist) Match(host string, u *url.URL) string {
+	if l == nil {
+		return ""
+	}
+	for d := host; d != ""; {
+		if l.domains[d] {
+			return d
+		}
+		_, d, _ = strings.Cut(d, ".")
+	}
+	if len(l.prefixes) == 0 {
+		return ""
+	}
+	for _, expr := range expressions(host, u) {
+		sum := sha256.Sum256([]byte(expr))
+		for n, set := range l.prefixes {
+			if set[string(sum[:n])] {
+				return expr
+			}
+		}
+	}
+	return ""
+}
+
+// expressions returns the host suffix and path prefix combinations a URL
+// is looked up by in Safe Browsing lists: the exact host and up to four
+// suffixes of its last five labels, each with the exact path and query,
+// the exact path, and up to four path prefixes starting with "/".
+func expressions(host string, u *url.URL) []string {
+	hosts := []string{host}
+	if !isIPHost(host) {
+		labels := strings.Split(host, ".")
+		for i := max(1, len(labels)-5); i < len(labels)-1; i++ {
+			hosts = append(hosts, strings.Join(labels[i:], "."))
+		}
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	var paths []string
+	if u.RawQuery != "" {
+		paths = append(paths, path+"?"+u.RawQuery)
+	}
+	paths = append(paths, path)
+	for i, n := 0, 0; i < len(path) && n < 4; i++ {
+		if path[i] == '/' && path[:i+1] != path {
+			paths = append(paths, path[:i+1])
+			n++
+		}
+	}
+
+	var exprs []string
+	for _, h := range hosts {
+		for _, p := range paths {
+			exprs = append(exprs, h+p)
+		}
+	}
+	return exprs
+}
@@ -0,0 +1,168 @@
+package screen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// idnaProfile converts host names the way browsers look them up, but lets
+// through the underscores some ASCII hosts use.
+var idnaProfile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false))
+
+// scripts are the writing systems told apart in a label. Letters of other
+// scripts are not checked.
+var scripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"Latin", unicode.Latin},
+	{"Cyrillic", unicode.Cyrillic},
+	{"Greek", unicode.Greek},
+	{"Armenian", unicode.Armenian},
+	{"Georgian", unicode.Georgian},
+	{"Cherokee", unicode.Cherokee},
+	{"Arabic", unicode.Arabic},
+	{"Hebrew", unicode.Hebrew},
+	{"Thai", unicode.Thai},
+	{"Han", unicode.Han},
+	{"Hiragana", unicode.Hiragana},
+	{"Katakana", unicode.Katakana},
+	{"Hangul", unicode.Hangul},
+	{"Bopomofo", unicode.Bopomofo},
+}
+
+// scriptTLDs are the ASCII country domains where names written only in a
+// script that imitates Latin are expected.
+var scriptTLDs = map[string][]string{
+	"Cyrillic": {"ru", "su", "by", "ua", "kz", "bg", "mk", "rs", "me", "mn", "kg", "tj", "uz"},
+	"Greek":    {"gr", "cy"},
+	"Armenian": {"am"},
+}
+
+// cjk are the scripts that are written together, also with Latin.
+var cjk = map[string]bool{"Han": true, "Hiragana": true, "Katakana": true, "Hangul": true, "Bopomofo": true}
+
+// confusables maps letters of other scripts, and Latin letters outside
+// ASCII, to the ASCII letter they are drawn like. It covers the letters
+// used in known homograph attacks, not the whole Unicode list.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j',
+	'к': 'k', 'ӏ': 'l', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'ѕ': 's',
+	'т': 't', 'ԝ': 'w', 'х': 'x', 'у': 'y', 'ү': 'y', 'ь': 'b', 'ѡ': 'w', 'ғ': 'f',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'γ': 'y', 'ω': 'w',
+	// Armenian
+	'ա': 'w', 'ց': 'g', 'հ': 'h', 'ո': 'n', 'ս': 'u', 'օ': 'o', 'զ': 'q',
+	// Latin outside ASCII
+	'ı': 'i', 'ɑ': 'a', 'ɡ': 'g', 'ɩ': 'i', 'ʏ': 'y', 'ɢ': 'g', 'ȷ': 'j', 'ḷ': 'l',
+	'ℓ': 'l', 'ǀ': 'l', 'ɒ': 'a', 'ɵ': 'o',
+}
+
+// checkIDN returns the ASCII form of host and, when host is an
+// internationalized name that imitates another, why. ASCII hosts without
+// punycode labels are returned as they are.
+func checkIDN(host string) (ascii, reason string) {
+	if isIPHost(host) || (isASCII(host) && !strings.Contains(host, "xn--")) {
+		return host, ""
+	}
+	ascii, err := idnaProfile.ToASCII(host)
+	if err != nil {
+		return host, "host is not a valid internationalized name"
+	}
+	uni, err := idnaProfile.ToUnicode(ascii)
+	if err != nil {
+		return ascii, "host is not a valid internationalized name"
+	}
+	labels := strings.Split(uni, ".")
+	tld := labels[len(labels)-1]
+	imitated := false
+	for i, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+		found := labelScripts(label)
+		if mixed := mixedScripts(found); mixed != "" {
+			return ascii, fmt.Sprintf("%s mixes %s letters", uni, mixed)
+		}
+		// A Cyrillic name under .ru or .рф is just Russian
+		if len(found) == 1 && (!isASCII(tld) || slices.Contains(scriptTLDs[found[0]], tld)) {
+			continue
+		}
+		if skeleton, ok := imitation(label); ok {
+			labels[i] = skeleton
+			imitated = true
+		}
+	}
+	if imitated {
+		return ascii, fmt.Sprintf("%s (%s) looks like %s", uni, ascii, strings.Join(labels, "."))
+	}
+	return ascii, ""
+}
+
+// labelScripts returns the scripts of the letters of a label in order of
+// appearance.
+func labelScripts(label string) []string {
+	var found []string
+	for _, r := range label {
+		for _, s := range scripts {
+			if unicode.Is(s.table, r) {
+				if !slices.Contains(found, s.name) {
+					found = append(found, s.name)
+				}
+				break
+			}
+		}
+	}
+	return found
+}
+
+// mixedScripts returns the scripts of a label that mixes scripts that are
+// not written together, like "Latin and Cyrillic", or "".
+func mixedScripts(found []string) string {
+	if len(found) < 2 {
+		return ""
+	}
+	together := true
+	for _, name := range found {
+		together = together && (cjk[name] || name == "Latin")
+	}
+	if together {
+		return ""
+	}
+	return strings.Join(found[:len(found)-1], ", ") + " and " + found[len(found)-1]
+}
+
+// imitation returns the ASCII letters a label is drawn like when every
+// letter of it outside ASCII is a confusable, so the label passes for an
+// ASCII one.
+func imitation(label string) (string, bool) {
+	var b strings.Builder
+	for _, r := range label {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+			continue
+		}
+		c, ok := confusables[r]
+		if !ok {
+			return "", false
+		}
+		b.WriteRune(c)
+	}
+	return b.String(), true
+}
+
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+// Package screen flags URLs that are likely to be used for phishing
+// before they are encoded: hosts on a local blocklist, internationalized
+// names that imitate other names, raw IP address hosts and URLs carrying
+// credentials. Everything is checked offline.
+package screen
+
+import (
+	"fmt"
+	"maps"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Checks run by a Screener.
+const (
+	CheckBlocklist   = "blocklist"
+	CheckHomograph   = "homograph"
+	CheckIP          = "ip"
+	CheckCredentials = "credentials"
+)
+
+// Checks lists the checks of a Screener.
+var Checks = []string{CheckBlocklist, CheckHomograph, CheckIP, CheckCredentials}
+
+// Action is what happens to a URL a check flags.
+type Action int
+
+const (
+	// Allow ignores the check.
+	Allow Action = iota
+	// Warn lets the URL through and reports the finding.
+	Warn
+	// Block refuses the URL.
+	Block
+)
+
+var actionNames = []string{"allow", "warn", "block"}
+
+func (a Action) String() string {
+	if int(a) < len(actionNames) {
+		return actionNames[a]
+	}
+	return strconv.Itoa(int(a))
+}
+
+// DefaultActions blocks listed hosts and lookalike names and warns about
+// IP hosts and credentials, which have legitimate uses.
+var DefaultActions = map[string]Action{
+	CheckBlocklist:   Block,
+	CheckHomograph:   Block,
+	CheckIP:          Warn,
+	CheckCredentials: Warn,
+}
+
+// ParseActions reads actions as comma-separated check=action pairs, e.g.
+// "ip=block,credentials=allow". Checks left out keep their default.
+func ParseActions(s string) (map[string]Action, error) {
+	actions := maps.Clone(DefaultActions)
+	for pair := range strings.SplitSeq(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		check, name, ok := strings.Cut(pair, "=")
+		check = strings.ToLower(strings.TrimSpace(check))
+		if _, known := DefaultActions[check]; !ok || !known {
+			return nil, fmt.Errorf("invalid screen action %q: want check=action with check one of %s", pair, strings.Join(Checks, ", "))
+		}
+		action := -1
+		for i, n := range actionNames {
+			if strings.EqualFold(strings.TrimSpace(name), n) {
+				action = i
+			}
+		}
+		if action < 0 {
+			return nil, fmt.Errorf("invalid screen action %q: want allow, warn or block", pair)
+		}
+		actions[check] = Action(action)
+	}
+	return actions, nil
+}
+
+// Finding is a problem found in a URL.
+type Finding struct {
+	Check  string
+	Action Action
+	Reason string
+}
+
+func (f Finding) String() string {
+	return f.Check + ": " + f.Reason
+}
+
+// URLScreener inspects URLs before they are encoded. Screen returns the
+// findings that are not allowed, blocks first.
+type URLScreener interface {
+	Screen(u *url.URL) []Finding
+}
+
+// Blocked returns the first blocking finding, or nil.
+func Blocked(findings []Finding) *Finding {
+	for i := range findings {
+		if findings[i].Action == Block {
+			return &findings[i]
+		}
+	}
+	return nil
+}
+
+// Screener is the default URLScreener. It is safe for concurrent use.
+type Screener struct {
+	// List holds the blocked hosts; nil blocks none.
+	List *Blocklist
+	// Actions says what each check does; nil uses DefaultActions.
+	Actions map[string]Action
+}
+
+// Screen implements URLScreener.
+func (s *Screener) Screen(u *url.URL) []Finding {
+	actions := s.Actions
+	if actions == nil {
+		actions = DefaultActions
+	}
+	var findings []Finding
+	add := func(check, reason string) {
+		if a := actions[check]; a != Allow {
+			findings = append(findings, Finding{check, a, reason})
+		}
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	ascii, reason := checkIDN(host)
+	if reason != "" {
+		add(CheckHomograph, reason)
+	}
+	if listed := s.List.Match(ascii, u); listed != "" {
+		add(CheckBlocklist, listed+" is on the blocklist")
+	}
+	if isIPHost(host) {
+		add(CheckIP, "host is an IP address")
+	}
+	if u.User != nil {
+		if name := u.User.Username(); strings.Contains(name, ".") {
+			add(CheckCredentials, fmt.Sprintf("URL starts with %q but leads to %s", name, host))
+		} else {
+			add(CheckCredentials, "URL contains credentials")
+		}
+	}
+
+	// Blocks first, so callers can stop at the first finding
+	slices.SortStableFunc(findings, func(a, b Finding) int { return int(b.Action - a.Action) })
+	return findings
+}
+
+// isIPHost reports whether host is an IP address, including the decimal,
+// octal and hexadecimal IPv4 forms browsers accept, like 3232235777 or
+// 0x7f.1.
+func isIPHost(host string) bool {
+	if _, err := netip.ParseAddr(host); err == nil {
+		return true
+	}
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return false
+	}
+	for _, p := range parts {
+		if _, err := strconv.ParseUint(p, 0, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,259 @@
+// Package sheet lays codes out on label stock: a template describes the
+// page and the grid of labels, and Render tiles rendered images with
+// optional captions onto as many PDF pages as needed.
+package sheet
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Template describes a sheet of labels. All lengths are in millimetres.
+type Template struct {
+	Name        string  `json:"name,omitempty" maxLength:"60"`
+	Description string  `json:"description,omitempty" maxLength:"200"`
+	PageWidth   float64 `json:"pageWidth" required:"true" min:"20" max:"1200" doc:"Page width in mm."`
+	PageHeight  float64 `json:"pageHeight" required:"true" min:"20" max:"1200" doc:"Page height in mm."`
+	MarginTop   float64 `json:"marginTop" min:"0" max:"1200" doc:"Distance from the top of the page to the first row, in mm."`
+	MarginLeft  float64 `json:"marginLeft" min:"0" max:"1200" doc:"Distance from the left of the page to the first column, in mm."`
+	Columns     int     `json:"columns" required:"true" min:"1" max:"50"`
+	Rows        int     `json:"rows" required:"true" min:"1" max:"100"`
+	PitchX      float64 `json:"pitchX,omitempty" min:"0" max:"1200" doc:"Distance between the left edges of neighbouring labels in mm; 0 when labels touch."`
+	PitchY      float64 `json:"pitchY,omitempty" min:"0" max:"1200" doc:"Distance between the top edges of neighbouring labels in mm; 0 when labels touch."`
+	LabelWidth  float64 `json:"labelWidth" required:"true" min:"5" max:"1200" doc:"Label width in mm."`
+	LabelHeight float64 `json:"labelHeight" required:"true" min:"5" max:"1200" doc:"Label height in mm."`
+	Radius      float64 `json:"radius,omitempty" min:"0" max:"50" doc:"Corner radius of the labels in mm, used for cut lines."`
+}
+
+// Catalog lists the bundled templates, after the manufacturers' specs.
+var Catalog = []Template{
+	{Name: "avery-l7160", Description: "A4, 21 labels of 63.5 x 38.1 mm (3 x 7)", PageWidth: 210, PageHeight: 297, MarginTop: 15.15, MarginLeft: 7.25, Columns: 3, Rows: 7, PitchX: 66, PitchY: 38.1, LabelWidth: 63.5, LabelHeight: 38.1, Radius: 1.5},
+	{Name: "avery-l7163", Description: "A4, 14 labels of 99.1 x 38.1 mm (2 x 7)", PageWidth: 210, PageHeight: 297, MarginTop: 15.15, MarginLeft: 4.65, Columns: 2, Rows: 7, PitchX: 101.6, PitchY: 38.1, LabelWidth: 99.1, LabelHeight: 38.1, Radius: 1.5},
+	{Name: "avery-l7165", Description: "A4, 8 labels of 99.1 x 67.7 mm (2 x 4)", PageWidth: 210, PageHeight: 297, MarginTop: 13.1, MarginLeft: 4.65, Columns: 2, Rows: 4, PitchX: 101.6, PitchY: 67.7, LabelWidth: 99.1, LabelHeight: 67.7, Radius: 1.5},
+	{Name: "avery-l7173", Description: "A4, 10 labels of 99.1 x 57 mm (2 x 5)", PageWidth: 210, PageHeight: 297, MarginTop: 6, MarginLeft: 4.65, Columns: 2, Rows: 5, PitchX: 101.6, PitchY: 57, LabelWidth: 99.1, LabelHeight: 57, Radius: 1.5},
+	{Name: "avery-l7651", Description: "A4, 65 labels of 38.1 x 21.2 mm (5 x 13)", PageWidth: 210, PageHeight: 297, MarginTop: 10.7, MarginLeft: 4.75, Columns: 5, Rows: 13, PitchX: 40.6, PitchY: 21.2, LabelWidth: 38.1, LabelHeight: 21.2, Radius: 1.5},
+	{Name: "avery-5160", Description: "Letter, 30 labels of 2.625 x 1 in (3 x 10)", PageWidth: 215.9, PageHeight: 279.4, MarginTop: 12.7, MarginLeft: 4.7625, Columns: 3, Rows: 10, PitchX: 69.85, PitchY: 25.4, LabelWidth: 66.675, LabelHeight: 25.4, Radius: 1.5},
+	{Name: "avery-5163", Description: "Letter, 10 labels of 4 x 2 in (2 x 5)", PageWidth: 215.9, PageHeight: 279.4, MarginTop: 12.7, MarginLeft: 3.96875, Columns: 2, Rows: 5, PitchX: 106.3625, PitchY: 50.8, LabelWidth: 101.6, LabelHeight: 50.8, Radius: 1.5},
+	{Name: "avery-5164", Description: "Letter, 6 labels of 4 x 3.33 in (2 x 3)", PageWidth: 215.9, PageHeight: 279.4, MarginTop: 12.7, MarginLeft: 3.96875, Columns: 2, Rows: 3, PitchX: 106.3625, PitchY: 84.666, LabelWidth: 101.6, LabelHeight: 84.666, Radius: 1.5},
+	{Name: "avery-5167", Description: "Letter, 80 labels of 1.75 x 0.5 in (4 x 20)", PageWidth: 215.9, PageHeight: 279.4, MarginTop: 12.7, MarginLeft: 7.62, Columns: 4, Rows: 20, PitchX: 52.07, PitchY: 12.7, LabelWidth: 44.45, LabelHeight: 12.7, Radius: 1},
+}
+
+// Lookup returns the bundled template called name.
+func Lookup(name string) (Template, bool) {
+	for _, t := range Catalog {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// Check reports a template whose labels overlap or run off the page.
+func (t Template) Check() error {
+	const eps = 0.01
+	pitchX, pitchY := t.pitch()
+	switch {
+	case t.Columns > 1 && pitchX+eps < t.LabelWidth:
+		return fmt.Errorf("pitchX must be at least the label width")
+	case t.Rows > 1 && pitchY+eps < t.LabelHeight:
+		return fmt.Errorf("pitchY must be at least the label height")
+	case t.MarginLeft+float64(t.Columns-1)*pitchX+t.LabelWidth > t.PageWidth+eps:
+		return fmt.Errorf("%d columns of %gmm do not fit across the page", t.Columns, t.LabelWidth)
+	case t.MarginTop+float64(t.Rows-1)*pitchY+t.LabelHeight > t.PageHeight+eps:
+		return fmt.Errorf("%d rows of %gmm do not fit down the page", t.Rows, t.LabelHeight)
+	}
+	return nil
+}
+
+// PerPage returns the number of labels on a page.
+func (t Template) PerPage() int { return t.Columns * t.Rows }
+
+func (t Template) pitch() (float64, float64) {
+	x, y := t.PitchX, t.PitchY
+	if x == 0 {
+		x = t.LabelWidth
+	}
+	if y == 0 {
+		y = t.LabelHeight
+	}
+	return x, y
+}
+
+// slot returns the top-left corner of label i of a page, in reading order.
+func (t Template) slot(i int) (float64, float64) {
+	pitchX, pitchY := t.pitch()
+	return t.MarginLeft + float64(i%t.Columns)*pitchX, t.MarginTop + float64(i/t.Columns)*pitchY
+}
+
+// Label is one label of a sheet.
+type Label struct {
+	Image      string // path of a PNG file
+	Caption    string
+	Background color.RGBA // label fill; transparent for none
+	Color      color.RGBA // caption color
+}
+
+// Options controls how labels are drawn.
+type Options struct {
+	Padding  float64 // space between the edge of a label and its content, in mm
+	Bleed    float64 // how far the background extends past a label, in mm
+	FontSize float64 // caption size in points; 0 picks one from the label height
+	CutLines bool    // draw a hairline around every label
+	Skip     int     // positions left empty at the start of the first page
+}
+
+// Render writes a PDF with the labels laid out on t, in reading order.
+func Render(w io.Writer, t Template, labels []Label, opts Options) error {
+	orientation := "P"
+	if t.PageWidth > t.PageHeight {
+		orientation = "L"
+	}
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: orientation,
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: t.PageWidth, Ht: t.PageHeight},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddUTF8FontFromBytes("goregular", "", goregular.TTF)
+
+	fontSize := opts.FontSize
+	if fontSize == 0 {
+		// About a sixth of the label height, within readable bounds.
+		fontSize = math.Max(5, math.Min(11, t.LabelHeight/6*72/25.4))
+	}
+	pdf.SetFont("goregular", "", fontSize)
+
+	// Backgrounds go first on every page so that one label's bleed never
+	// covers its neighbour's content.
+	perPage := t.PerPage()
+	for start := 0; start < len(labels); {
+		first := (opts.Skip + start) % perPage
+		end := min(len(labels), start+perPage-first)
+		pdf.AddPage()
+		for i := start; i < end; i++ {
+			x, y := t.slot(first + i - start)
+			drawBackground(pdf, t, x, y, labels[i], opts)
+		}
+		for i := start; i < end; i++ {
+			x, y := t.slot(first + i - start)
+			drawLabel(pdf, t, x, y, labels[i], opts)
+		}
+		start = end
+	}
+	return pdf.Output(w)
+}
+
+// drawBackground fills a label and its bleed.
+func drawBackground(pdf *gofpdf.Fpdf, t Template, x, y float64, l Label, opts Options) {
+	if l.Background.A == 0 {
+		return
+	}
+	b := opts.Bleed
+	pdf.SetFillColor(int(l.Background.R), int(l.Background.G), int(l.Background.B))
+	pdf.Rect(x-b, y-b, t.LabelWidth+2*b, t.LabelHeight+2*b, "F")
+}
+
+// drawLabel draws the content of one label with its top-left corner at
+// x, y. Wide labels get the caption beside the code, others below it.
+func drawLabel(pdf *gofpdf.Fpdf, t Template, x, y float64, l Label, opts Options) {
+	w, h := t.LabelWidth, t.LabelHeight
+	if opts.CutLines {
+		pdf.SetLineWidth(0.1)
+		pdf.SetDrawColor(160, 160, 160)
+		if t.Radius > 0 {
+			roundedRect(pdf, x, y, w, h, math.Min(t.Radius, math.Min(w, h)/2))
+		} else {
+			pdf.Rect(x, y, w, h, "D")
+		}
+	}
+
+	pad := opts.Padding
+	ix, iy, iw, ih := x+pad, y+pad, w-2*pad, h-2*pad
+	if iw <= 0 || ih <= 0 {
+		return
+	}
+	_, fontMM := pdf.GetFontSize()
+	lineH := fontMM * 1.2
+	pdf.SetTextColor(int(l.Color.R), int(l.Color.G), int(l.Color.B))
+
+	if l.Caption == "" {
+		side := math.Min(iw, ih)
+		drawImage(pdf, l.Image, ix+(iw-side)/2, iy+(ih-side)/2, side)
+		return
+	}
+
+	if iw >= ih*1.8 {
+		side := ih
+		drawImage(pdf, l.Image, ix, iy, side)
+		tx, tw := ix+side+pad, iw-side-pad
+		lines := fitLines(pdf.SplitText(l.Caption, tw), int(ih/lineH))
+		ty := iy + (ih-float64(len(lines))*lineH)/2
+		for k, line := range lines {
+			pdf.SetXY(tx, ty+float64(k)*lineH)
+			pdf.CellFormat(tw, lineH, line, "", 0, "LM", false, 0, "")
+		}
+		return
+	}
+
+	lines := fitLines(pdf.SplitText(l.Caption, iw), 2)
+	textH := float64(len(lines)) * lineH
+	side := math.Min(iw, ih-textH-pad/2)
+	if side <= 0 {
+		side = math.Min(iw, ih)
+		lines = nil
+	}
+	top := iy + (ih-side-textH)/2
+	drawImage(pdf, l.Image, ix+(iw-side)/2, top, side)
+	for k, line := range lines {
+		pdf.SetXY(ix, top+side+float64(k)*lineH)
+		pdf.CellFormat(iw, lineH, line, "", 0, "CM", false, 0, "")
+	}
+}
+
+// roundedRect outlines a rectangle with rounded corners. gofpdf's own
+// RoundedRect leaves an unbalanced graphics state on the page.
+func roundedRect(pdf *gofpdf.Fpdf, x, y, w, h, r float64) {
+	k := r * 0.5523 // control point distance of a quarter circle
+	pdf.MoveTo(x+r, y)
+	pdf.LineTo(x+w-r, y)
+	pdf.CurveBezierCubicTo(x+w-r+k, y, x+w, y+r-k, x+w, y+r)
+	pdf.LineTo(x+w, y+h-r)
+	pdf.CurveBezierCubicTo(x+w, y+h-r+k, x+w-r+k, y+h, x+w-r, y+h)
+	pdf.LineTo(x+r, y+h)
+	pdf.CurveBezierCubicTo(x+r-k, y+h, x, y+h-r+k, x, y+h-r)
+	pdf.LineTo(x, y+r)
+	pdf.CurveBezierCubicTo(x, y+r-k, x+r-k, y, x+r, y)
+	pdf.ClosePath()
+	pdf.DrawPath("D")
+}
+
+// drawImage fits a PNG into the square of the given side, keeping its
+// aspect ratio.
+func drawImage(pdf *gofpdf.Fpdf, path string, x, y, side float64) {
+	opts := gofpdf.ImageOptions{ImageType: "PNG"}
+	info := pdf.RegisterImageOptions(path, opts)
+	if info == nil || info.Width() == 0 || info.Height() == 0 {
+		return
+	}
+	w, h := side, side
+	if ratio := info.Width() / info.Height(); ratio > 1 {
+		h = side / ratio
+	} else {
+		w = side * ratio
+	}
+	pdf.ImageOptions(path, x+(side-w)/2, y+(side-h)/2, w, h, false, opts, 0, "")
+}
+
+// fitLines keeps at most n lines.
+func fitLines(lines []string, n int) []string {
+	if len(lines) > n {
+		lines = lines[:max(n, 0)]
+	}
+	return lines
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	apiKeysBucket = []byte("apikeys")
+	usageBucket   = []byte("usage")
+)
+
+// APIKeyPrefix starts every API key, which tells them apart from the owner
+// keys of dynamic codes.
+const APIKeyPrefix = "qrk_"
+
+// APIKey is an API key as shown to the administrator. The key itself is
+// only returned when it is created.
+type APIKey struct {
+	ID string `json:"id"`
+	design.APIKey
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// apiKeyRecord is an API key as stored, with the hash of its key.
+type apiKeyRecord struct {
+	APIKey
+	KeyHash string `json:"keyHash"`
+}
+
+// UsageDay holds the usage counters of an API key for one day (UTC).
+type UsageDay struct {
+	Day      string             `json:"day" doc:"Date as YYYY-MM-DD."`
+	Requests int                `json:"requests"`
+	Units    float64            `json:"units" doc:"Units charged, counted against the daily quota."`
+	Limits   map[string]float64 `json:"limits" doc:"Units per rate limit: render, batch or api."`
+}
+
+// APIKeys returns every API key, sorted by name.
+func (s *Store) APIKeys() ([]APIKey, error) {
+	keys := []APIKey{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(apiKeysBucket).ForEach(func(_, data []byte) error {
+			var r apiKeyRecord
+			if err := json.Unmarshal(data, &r); err != nil {
+				return err
+			}
+			keys = append(keys, r.APIKey)
+			return nil
+		})
+	})
+	slices.SortFunc(keys, func(a, b APIKey) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return keys, err
+}
+
+// APIKey returns the API key with the given ID.
+func (s *Store) APIKey(id string) (APIKey, error) {
+	var r apiKeyRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return get(tx, apiKeysBucket, id, &r)
+	})
+	return r.APIKey, err
+}
+
+// CreateAPIKey saves a new API key and returns it with the key, which is
+// not kept, only its hash. Keys look like qrk_<id>_<secret>.
+func (s *Store) CreateAPIKey(doc design.APIKey) (APIKey, string, error) {
+	id, err := newID(6)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	secret, err := newID(24)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	key := APIKeyPrefix + id + "_" + secret
+	now := s.now().UTC()
+	r := apiKeyRecord{APIKey: APIKey{ID: id, APIKey: doc, CreatedAt: now, UpdatedAt: now}, KeyHash: hashKey(key)}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return put(tx, apiKeysBucket, id, r)
+	})
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	return r.APIKey, key, nil
+}
+
+// UpdateAPIKey replaces the name, scopes and quota of an API key. The key
+// stays the same.
+func (s *Store) UpdateAPIKey(id string, doc design.APIKey) (APIKey, error) {
+	var r apiKeyRecord
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := get(tx, apiKeysBucket, id, &r); err != nil {
+			return err
+		}
+		r.APIKey.APIKey = doc
+		r.UpdatedAt = s.now().UTC()
+		return put(tx, apiKeysBucket, id, r)
+	})
+	return r.APIKey, err
+}
+
+// DeleteAPIKey deletes an API key and its usage counters; the key stops
+// working at once.
+func (s *Store) DeleteAPIKey(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(apiKeysBucket)
+		if b.Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+		if err := deletePrefix(tx, usageBucket, id+"/"); err != nil {
+			return err
+		}
+		return b.Delete([]byte(id))
+	})
+}
+
+// Authenticate returns the API key key belongs to, or ErrNotFound.
+func (s *Store) Authenticate(key string) (APIKey, error) {
+	id, _, _ := strings.Cut(strings.TrimPrefix(key, APIKeyPrefix), "_")
+	if !strings.HasPrefix(key, APIKeyPrefix) || id == "" {
+		return APIKey{}, ErrNotFound
+	}
+	var r apiKeyRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return get(tx, apiKeysBucket, id, &r)
+	})
+	if err != nil {
+		return APIKey{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(r.KeyHash)) != 1 {
+		return APIKey{}, ErrNotFound
+	}
+	return r.APIKey, nil
+}
+
+// RecordUsage counts a charge of units to the API key with the given ID
+// under the named rate limit at time t; requests is 1 for the first
+// charge of a request and 0 for later ones. Concurrent charges are
+// written in batches.
+func (s *Store) RecordUsage(id string, t time.Time, limit string, requests int, units float64) error {
+	day := t.UTC().Format(time.DateOnly)
+	return s.db.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket(usageBucket)
+		d := UsageDay{Day: day, Limits: map[string]float64{}}
+		if data := b.Get(dayKey(id, day)); data != nil {
+			if err := json.Unmarshal(data, &d); err != nil {
+				return err
+			}
+		}
+		d.Requests += requests
+		// Rounded to hundredths, which render costs are counted in
+		d.Units = math.Round((d.Units+units)*100) / 100
+		d.Limits[limit] = math.Round((d.Limits[limit]+units)*100) / 100
+		data, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		return b.Put(dayKey(id, day), data)
+	})
+}
+
+// Usage returns the usage counters of an API key from the day of since
+// on, in date order. Days without use are left out.
+func (s *Store) Usage(id string, since time.Time) ([]UsageDay, error) {
+	days := []UsageDay{}
+	prefix := []byte(id + "/")
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(usageBucket).Cursor()
+		for k, v := c.Seek(dayKey(id, since.UTC().Format(time.DateOnly))); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var d UsageDay
+			if err := json.Unmarshal(v, &d); err != nil {
+				return err
+			}
+			days = append(days, d)
+		}
+		return nil
+	})
+	return days, err
+}
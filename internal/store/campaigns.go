@@ -0,0 +1,88 @@
+package store
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	bolt "go.etcd.io/bbolt"
+)
+
+var campaignsBucket = []byte("campaigns")
+
+// Campaign is a saved campaign template.
+type Campaign struct {
+	ID string `json:"id"`
+	design.CampaignTemplate
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Campaigns returns every campaign template, sorted by name.
+func (s *Store) Campaigns() ([]Campaign, error) {
+	campaigns := []Campaign{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(campaignsBucket).ForEach(func(_, data []byte) error {
+			var c Campaign
+			if err := json.Unmarshal(data, &c); err != nil {
+				return err
+			}
+			campaigns = append(campaigns, c)
+			return nil
+		})
+	})
+	slices.SortFunc(campaigns, func(a, b Campaign) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return campaigns, err
+}
+
+// Campaign returns the campaign template with the given ID.
+func (s *Store) Campaign(id string) (Campaign, error) {
+	var c Campaign
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return get(tx, campaignsBucket, id, &c)
+	})
+	return c, err
+}
+
+// CreateCampaign saves a new campaign template.
+func (s *Store) CreateCampaign(doc design.CampaignTemplate) (Campaign, error) {
+	id, err := newID(6)
+	if err != nil {
+		return Campaign{}, err
+	}
+	now := s.now().UTC()
+	c := Campaign{ID: id, CampaignTemplate: doc, CreatedAt: now, UpdatedAt: now}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return put(tx, campaignsBucket, id, c)
+	})
+	return c, err
+}
+
+// UpdateCampaign replaces the document of an existing campaign template.
+func (s *Store) UpdateCampaign(id string, doc design.CampaignTemplate) (Campaign, error) {
+	var c Campaign
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := get(tx, campaignsBucket, id, &c); err != nil {
+			return err
+		}
+		c.CampaignTemplate = doc
+		c.UpdatedAt = s.now().UTC()
+		return put(tx, campaignsBucket, id, c)
+	})
+	return c, err
+}
+
+// DeleteCampaign deletes a campaign template.
+func (s *Store) DeleteCampaign(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(campaignsBucket)
+		if b.Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+		return b.Delete([]byte(id))
+	})
+}
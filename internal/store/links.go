@@ -0,0 +1,204 @@
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var linksBucket = []byte("links")
+
+// slugAlphabet avoids characters that are easily confused when a short URL
+// is typed from print.
+const slugAlphabet = "23456789abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// ErrExhausted is returned by TakeScan for a link that has used up its
+// scans.
+var ErrExhausted = errors.New("scan limit reached")
+
+// Link is a dynamic code: a slug that redirects to an editable target.
+type Link struct {
+	Slug string `json:"slug"`
+	design.Link
+	Protected bool      `json:"protected" doc:"Whether visitors must enter a passcode."`
+	Scans     int       `json:"scans" doc:"Redirects so far, counted against maxScans."`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	keyHash      string
+	passcodeHash []byte
+}
+
+// linkRecord is a link as stored, with the hashes of its owner key and
+// passcode.
+type linkRecord struct {
+	Link
+	KeyHash      string `json:"keyHash"`
+	PasscodeHash []byte `json:"passcodeHash,omitempty"`
+}
+
+func (r linkRecord) link() Link {
+	l := r.Link
+	l.keyHash = r.KeyHash
+	l.passcodeHash = r.PasscodeHash
+	l.Protected = len(r.PasscodeHash) > 0
+	return l
+}
+
+// setPasscode applies the passcode of doc, which is hashed with bcrypt and
+// cleared: nil keeps the current one and "" removes it.
+func (r *linkRecord) setPasscode(doc *design.Link) error {
+	p := doc.Passcode
+	doc.Passcode = nil
+	switch {
+	case p == nil:
+		return nil
+	case *p == "":
+		r.PasscodeHash = nil
+		return nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(*p), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	r.PasscodeHash = hash
+	return nil
+}
+
+// Owns reports whether key is the owner key of the link.
+func (l Link) Owns(key string) bool {
+	return l.keyHash != "" && subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(l.keyHash)) == 1
+}
+
+// CheckPasscode reports whether passcode opens a protected link.
+func (l Link) CheckPasscode(passcode string) bool {
+	return len(l.passcodeHash) > 0 && bcrypt.CompareHashAndPassword(l.passcodeHash, []byte(passcode)) == nil
+}
+
+// Exhausted reports whether the link has used up its scans.
+func (l Link) Exhausted() bool {
+	return l.MaxScans > 0 && l.Scans >= l.MaxScans
+}
+
+// Link returns the link with the given slug.
+func (s *Store) Link(slug string) (Link, error) {
+	var r linkRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return get(tx, linksBucket, slug, &r)
+	})
+	return r.link(), err
+}
+
+// CreateLink saves a new link under slug, or under a random slug if it is
+// empty, and returns it with the owner key that may edit it. The key is
+// not kept, only its hash.
+func (s *Store) CreateLink(slug string, doc design.Link) (Link, string, error) {
+	key, err := newID(24)
+	if err != nil {
+		return Link{}, "", err
+	}
+	now := s.now().UTC()
+	r := linkRecord{KeyHash: hashKey(key)}
+	if err := r.setPasscode(&doc); err != nil {
+		return Link{}, "", err
+	}
+	r.Link = Link{Link: doc, CreatedAt: now, UpdatedAt: now}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(linksBucket)
+		if slug == "" {
+			for slug == "" || b.Get([]byte(slug)) != nil {
+				if slug, err = newSlug(7); err != nil {
+					return err
+				}
+			}
+		} else if b.Get([]byte(slug)) != nil {
+			return ErrExists
+		}
+		r.Slug = slug
+		return put(tx, linksBucket, slug, r)
+	})
+	if err != nil {
+		return Link{}, "", err
+	}
+	return r.link(), key, nil
+}
+
+// UpdateLink replaces the document of an existing link. The passcode is
+// only changed when doc has one; the scan count is kept.
+func (s *Store) UpdateLink(slug string, doc design.Link) (Link, error) {
+	var r linkRecord
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := get(tx, linksBucket, slug, &r); err != nil {
+			return err
+		}
+		if err := r.setPasscode(&doc); err != nil {
+			return err
+		}
+		r.Link.Link = doc
+		r.UpdatedAt = s.now().UTC()
+		return put(tx, linksBucket, slug, r)
+	})
+	return r.link(), err
+}
+
+// TakeScan counts a redirect of a link. When the link has used up its
+// scans it counts nothing and returns ErrExhausted.
+func (s *Store) TakeScan(slug string) error {
+	var exhausted bool
+	err := s.db.Batch(func(tx *bolt.Tx) error {
+		// Batch may run this more than once
+		exhausted = false
+		var r linkRecord
+		if err := get(tx, linksBucket, slug, &r); err != nil {
+			return err
+		}
+		if r.Exhausted() {
+			exhausted = true
+			return nil
+		}
+		r.Scans++
+		return put(tx, linksBucket, slug, r)
+	})
+	if err == nil && exhausted {
+		return ErrExhausted
+	}
+	return err
+}
+
+// DeleteLink deletes a link and its scan counters.
+func (s *Store) DeleteLink(slug string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(linksBucket)
+		if b.Get([]byte(slug)) == nil {
+			return ErrNotFound
+		}
+		if err := deleteScans(tx, slug); err != nil {
+			return err
+		}
+		return b.Delete([]byte(slug))
+	})
+}
+
+// newSlug returns a random slug of n characters from slugAlphabet.
+func newSlug(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = slugAlphabet[int(b[i])%len(slugAlphabet)]
+	}
+	return string(b), nil
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
+	bolt "go.etcd.io/bbolt"
+)
+
+var presetsBucket = []byte("presets")
+
+// Preset is a saved design preset or brand kit.
+type Preset struct {
+	ID string `json:"id"`
+	design.Preset
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	keyHash string
+}
+
+// presetRecord is a preset as stored, with the hash of its owner key.
+// Presets saved before owner keys have none.
+type presetRecord struct {
+	Preset
+	KeyHash string `json:"keyHash,omitempty"`
+}
+
+func (r presetRecord) preset() Preset {
+	p := r.Preset
+	p.keyHash = r.KeyHash
+	return p
+}
+
+// Owns reports whether key is the owner key of the preset.
+func (p Preset) Owns(key string) bool {
+	return p.keyHash != "" && subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(p.keyHash)) == 1
+}
+
+// Presets returns every preset, sorted by name.
+func (s *Store) Presets() ([]Preset, error) {
+	presets := []Preset{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(presetsBucket).ForEach(func(_, data []byte) error {
+			var r presetRecord
+			if err := json.Unmarshal(data, &r); err != nil {
+				return err
+			}
+			presets = append(presets, r.preset())
+			return nil
+		})
+	})
+	slices.SortFunc(presets, func(a, b Preset) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return presets, err
+}
+
+// Preset returns the preset with the given ID.
+func (s *Store) Preset(id string) (Preset, error) {
+	var r presetRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return get(tx, presetsBucket, id, &r)
+	})
+	return r.preset(), err
+}
+
+// CreatePreset saves a new preset and returns it with the owner key that
+// may replace or delete it. The key is not kept, only its hash.
+func (s *Store) CreatePreset(doc design.Preset) (Preset, string, error) {
+	id, err := newID(6)
+	if err != nil {
+		return Preset{}, "", err
+	}
+	key, err := newID(24)
+	if err != nil {
+		return Preset{}, "", err
+	}
+	now := s.now().UTC()
+	r := presetRecord{Preset: Preset{ID: id, Preset: doc, CreatedAt: now, UpdatedAt: now}, KeyHash: hashKey(key)}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return put(tx, presetsBucket, id, r)
+	})
+	if err != nil {
+		return Preset{}, "", err
+	}
+	return r.preset(), key, nil
+}
+
+// UpdatePreset replaces the document of an existing preset.
+func (s *Store) UpdatePreset(id string, doc design.Preset) (Preset, error) {
+	var r presetRecord
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := get(tx, presetsBucket, id, &r); err != nil {
+			return err
+		}
+		r.Preset.Preset = doc
+		r.UpdatedAt = s.now().UTC()
+		return put(tx, presetsBucket, id, r)
+	})
+	return r.preset(), err
+}
+
+// DeletePreset deletes a preset.
+func (s *Store) DeletePreset(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(presetsBucket)
+		if b.Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+		return b.Delete([]byte(id))
+	})
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var scansBucket = []byte("scans")
+
+// ScanDay holds the scan counters of a dynamic code for one day (UTC).
+// Only counters are kept: no addresses, user agents or other
+// per-visitor data.
+type ScanDay struct {
+	Day       string         `json:"day" doc:"Date as YYYY-MM-DD."`
+	Total     int            `json:"total"`
+	Devices   map[string]int `json:"devices" doc:"Scans per device class: mobile, tablet, desktop, bot or other."`
+	Countries map[string]int `json:"countries" doc:"Scans per ISO 3166 country code, or unknown."`
+}
+
+// dayKey returns the key of the daily counters of a record on day. Slugs
+// and IDs cannot contain '/', so the keys of a record sort together by
+// day.
+func dayKey(id, day string) []byte {
+	return []byte(id + "/" + day)
+}
+
+// RecordScan counts a scan of the link with the given slug at time t.
+// Concurrent scans are written in batches.
+func (s *Store) RecordScan(slug string, t time.Time, device, country string) error {
+	day := t.UTC().Format(time.DateOnly)
+	return s.db.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket(scansBucket)
+		d := ScanDay{Day: day, Devices: map[string]int{}, Countries: map[string]int{}}
+		if data := b.Get(dayKey(slug, day)); data != nil {
+			if err := json.Unmarshal(data, &d); err != nil {
+				return err
+			}
+		}
+		d.Total++
+		d.Devices[device]++
+		d.Countries[country]++
+		data, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		return b.Put(dayKey(slug, day), data)
+	})
+}
+
+// Scans returns the scan counters of a link from the day of since on, in
+// date order. Days without scans are left out.
+func (s *Store) Scans(slug string, since time.Time) ([]ScanDay, error) {
+	days := []ScanDay{}
+	prefix := []byte(slug + "/")
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(scansBucket).Cursor()
+		for k, v := c.Seek(dayKey(slug, since.UTC().Format(time.DateOnly))); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var d ScanDay
+			if err := json.Unmarshal(v, &d); err != nil {
+				return err
+			}
+			days = append(days, d)
+		}
+		return nil
+	})
+	return days, err
+}
+
+// deleteScans deletes the scan counters of a link.
+func deleteScans(tx *bolt.Tx, slug string) error {
+	return deletePrefix(tx, scansBucket, slug+"/")
+}
+
+// deletePrefix deletes the keys of bucket that start with prefix.
+func deletePrefix(tx *bolt.Tx, bucket []byte, p string) error {
+	prefix := []byte(p)
+	c := tx.Bucket(bucket).Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Seek(prefix) {
+		if err := c.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
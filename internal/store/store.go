@@ -0,0 +1,110 @@
+// Package store keeps the server's records, such as design presets, in an
+// embedded bbolt database file. Records are JSON values in one bucket per
+// kind, keyed by ID.
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// ErrNotFound is returned when a record does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrExists is returned when creating a record whose ID is taken.
+	ErrExists = errors.New("already exists")
+)
+
+var secretsBucket = []byte("secrets")
+
+var buckets = [][]byte{presetsBucket, campaignsBucket, linksBucket, scansBucket, apiKeysBucket, usageBucket, secretsBucket}
+
+// Store is an open database. It is safe for concurrent use.
+type Store struct {
+	db  *bolt.DB
+	now func() time.Time
+}
+
+// Open opens the database at path, creating it and its directory if
+// needed.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating database directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating buckets: %w", err)
+	}
+	return &Store{db: db, now: time.Now}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Secret returns the random secret of n bytes saved under name, creating
+// it on first use.
+func (s *Store) Secret(name string, n int) ([]byte, error) {
+	var secret []byte
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(secretsBucket)
+		if v := b.Get([]byte(name)); v != nil {
+			secret = append([]byte(nil), v...)
+			return nil
+		}
+		secret = make([]byte, n)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		return b.Put([]byte(name), secret)
+	})
+	return secret, err
+}
+
+// get decodes the record stored under id in bucket into v.
+func get(tx *bolt.Tx, bucket []byte, id string, v any) error {
+	data := tx.Bucket(bucket).Get([]byte(id))
+	if data == nil {
+		return ErrNotFound
+	}
+	return json.Unmarshal(data, v)
+}
+
+// put stores v under id in bucket.
+func put(tx *bolt.Tx, bucket []byte, id string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(bucket).Put([]byte(id), data)
+}
+
+// newID returns a random ID of n bytes, hex encoded.
+func newID(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,195 @@
+// Package token signs short, URL-safe tokens that carry a payload and an
+// optional expiry. Each token names the key that signed it, so keys can be
+// rotated: new tokens are signed with the first key while tokens from the
+// older ones keep verifying until those keys are removed.
+//
+// A token is the base64url encoding, without padding, of
+//
+//	version (1 byte) | key ID (1 byte) | flags (1 byte) |
+//	expiry (4 bytes, Unix seconds, if flagExpires) | payload | MAC
+//
+// where the MAC is the first 12 bytes of HMAC-SHA256 over everything
+// before it. Payloads that shrink under DEFLATE are stored compressed.
+package token
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	version = 1
+	macSize = 12
+
+	flagDeflate = 1 << 0
+	flagExpires = 1 << 1
+)
+
+// MaxPayload bounds the decompressed size of a payload.
+const MaxPayload = 16 << 10
+
+var (
+	// ErrInvalid is returned for malformed tokens, unknown keys and
+	// signatures that do not match.
+	ErrInvalid = errors.New("invalid token")
+	// ErrExpired is returned for a valid token past its expiry.
+	ErrExpired = errors.New("token expired")
+)
+
+var encoding = base64.RawURLEncoding
+
+// Key is an HMAC key and the ID recorded in the tokens it signs.
+type Key struct {
+	ID     byte
+	Secret []byte
+}
+
+// ParseKeys reads keys written as comma-separated id:secret pairs, with
+// IDs from 0 to 255 and secrets of at least 16 bytes, e.g.
+// "2:new-secret-value,1:old-secret-value".
+func ParseKeys(s string) ([]Key, error) {
+	var keys []Key
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("key %q is not written as id:secret", pair)
+		}
+		n, err := strconv.ParseUint(id, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("key ID %q must be a number from 0 to 255", id)
+		}
+		if len(secret) < 16 {
+			return nil, fmt.Errorf("key %d: secret must be at least 16 bytes", n)
+		}
+		keys = append(keys, Key{ID: byte(n), Secret: []byte(secret)})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys")
+	}
+	return keys, nil
+}
+
+// Signer signs and verifies tokens.
+type Signer struct {
+	keys []Key
+	// Now returns the current time; nil uses time.Now.
+	Now func() time.Time
+}
+
+// NewSigner returns a signer that signs with the first key and verifies
+// with any of them.
+func NewSigner(keys ...Key) (*Signer, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("token: no keys")
+	}
+	seen := map[byte]bool{}
+	for _, k := range keys {
+		if seen[k.ID] {
+			return nil, fmt.Errorf("token: duplicate key ID %d", k.ID)
+		}
+		seen[k.ID] = true
+	}
+	return &Signer{keys: keys}, nil
+}
+
+func (s *Signer) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// Sign returns a token for payload. A zero expires makes a token that does
+// not expire.
+func (s *Signer) Sign(payload []byte, expires time.Time) string {
+	key := s.keys[0]
+	var flags byte
+	body := payload
+	var z bytes.Buffer
+	if w, err := flate.NewWriter(&z, flate.BestCompression); err == nil {
+		w.Write(payload)
+		w.Close()
+		if z.Len() < len(payload) {
+			body = z.Bytes()
+			flags |= flagDeflate
+		}
+	}
+
+	buf := []byte{version, key.ID, 0}
+	if !expires.IsZero() {
+		flags |= flagExpires
+		buf = binary.BigEndian.AppendUint32(buf, uint32(expires.Unix()))
+	}
+	buf[2] = flags
+	buf = append(buf, body...)
+	buf = append(buf, mac(key.Secret, buf)...)
+	return encoding.EncodeToString(buf)
+}
+
+// Verify checks a token and returns its payload and expiry, zero for
+// tokens that do not expire.
+func (s *Signer) Verify(tok string) ([]byte, time.Time, error) {
+	buf, err := encoding.DecodeString(tok)
+	if err != nil || len(buf) < 3+macSize || buf[0] != version {
+		return nil, time.Time{}, ErrInvalid
+	}
+	key, ok := s.key(buf[1])
+	if !ok {
+		return nil, time.Time{}, ErrInvalid
+	}
+	signed, sum := buf[:len(buf)-macSize], buf[len(buf)-macSize:]
+	if !hmac.Equal(sum, mac(key.Secret, signed)) {
+		return nil, time.Time{}, ErrInvalid
+	}
+
+	flags, body := signed[2], signed[3:]
+	var expires time.Time
+	if flags&flagExpires != 0 {
+		if len(body) < 4 {
+			return nil, time.Time{}, ErrInvalid
+		}
+		expires = time.Unix(int64(binary.BigEndian.Uint32(body)), 0).UTC()
+		body = body[4:]
+		if !s.now().Before(expires) {
+			return nil, expires, ErrExpired
+		}
+	}
+	if flags&flagDeflate != 0 {
+		r := flate.NewReader(bytes.NewReader(body))
+		defer r.Close()
+		body, err = io.ReadAll(io.LimitReader(r, MaxPayload+1))
+		if err != nil || len(body) > MaxPayload {
+			return nil, time.Time{}, ErrInvalid
+		}
+	}
+	return body, expires, nil
+}
+
+func (s *Signer) key(id byte) (Key, bool) {
+	for _, k := range s.keys {
+		if k.ID == id {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+func mac(secret, data []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write(data)
+	return h.Sum(nil)[:macSize]
+}
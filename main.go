@@ -1,32 +1,236 @@
 package main
 
 import (
-	"log"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata" // time zones of redirect rules, even without system zoneinfo
 
+	"github.com/cristianadrielbraun/qrcreator.link/internal/analytics"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/config"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/design"
 	"github.com/cristianadrielbraun/qrcreator.link/internal/handlers"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/jobs"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/logging"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/metrics"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/ratelimit"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/rendercache"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/screen"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/store"
+	"github.com/cristianadrielbraun/qrcreator.link/internal/token"
 	"github.com/cristianadrielbraun/qrcreator.link/web/pages"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// Settings from the defaults, CONFIG_FILE, the environment and flags
+	cfg, printConfig, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if printConfig {
+		if err := cfg.Write(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Structured logs on stderr; the standard log package goes through it
+	logger, err := newLogger(cfg.Log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	// SIGTERM and SIGINT start a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
-	r.Use(gin.Logger())
-	r.Use(gin.Recovery())
+	r.Use(logging.Middleware(logger))
+
+	// Client addresses are read from X-Forwarded-For only when the request
+	// comes from one of the trusted proxies
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		fatal("server.trusted_proxies", err)
+	}
 
 	// Static assets
-	r.Static("/web/static", "web/static")
+	r.Static("/web/static", cfg.Server.StaticDir)
+
+	// Database
+	db, err := store.Open(cfg.Storage.DBPath)
+	if err != nil {
+		fatal("opening database", err)
+	}
+	defer db.Close()
+
+	// Background jobs
+	jm, err := jobs.NewManager(jobs.Options{
+		Dir:         cfg.Storage.JobsDir,
+		TTL:         time.Duration(cfg.Storage.JobTTL),
+		Concurrency: cfg.Storage.JobWorkers,
+	})
+	if err != nil {
+		fatal("starting jobs", err)
+	}
+	go jm.Run(ctx, time.Minute)
+
+	// Logo uploads, created so readiness can check them
+	if err := os.MkdirAll(cfg.Storage.UploadsDir, 0o755); err != nil {
+		fatal("creating uploads directory", err)
+	}
+
+	// Design token keys
+	signer, err := tokenSigner(db, cfg.Keys.TokenKeys)
+	if err != nil {
+		fatal("loading token keys", err)
+	}
+
+	// Country lookup for scan analytics
+	var geo *analytics.GeoDB
+	if path := cfg.Storage.GeoIPDB; path != "" {
+		if geo, err = analytics.LoadGeoCSV(path); err != nil {
+			fatal("loading GeoIP database", err)
+		}
+		slog.Info("GeoIP database loaded", "ranges", geo.Len())
+	}
+
+	// URL screening against phishing
+	screener, err := urlScreener(cfg.URLs)
+	if err != nil {
+		fatal("loading URL screening", err)
+	}
+
+	// Cache of rendered images
+	var renders *rendercache.Cache
+	if mb := cfg.Render.CacheMB; mb > 0 {
+		renders, err = rendercache.New(rendercache.Options{
+			MemoryBytes: int64(mb) << 20,
+			Dir:         cfg.Render.CacheDir,
+			DiskBytes:   int64(cfg.Render.CacheDiskMB) << 20,
+		})
+		if err != nil {
+			fatal("opening render cache", err)
+		}
+	}
+
+	// Rate limits per client address
+	limits, err := ratelimit.ParsePolicies(cfg.Limits.Rate, handlers.DefaultLimits)
+	if err != nil {
+		fatal("limits.rate", err)
+	}
+	keyLimits, err := ratelimit.ParsePolicies(cfg.Limits.KeyRate, handlers.DefaultKeyLimits)
+	if err != nil {
+		fatal("limits.key_rate", err)
+	}
 
 	// API routes
-	h := handlers.New()
+	reg := metrics.NewRegistry()
+	h := handlers.New(handlers.Options{
+		Jobs:              jm,
+		Store:             db,
+		Tokens:            signer,
+		Geo:               geo,
+		Screener:          screener,
+		Renders:           renders,
+		Limits:            limits,
+		KeyLimits:         keyLimits,
+		RenderConcurrency: cfg.Render.Concurrency,
+		AdminToken:        cfg.Keys.AdminToken,
+		Metrics:           reg,
+		DownloadSize:      cfg.Render.DownloadSize,
+		PaddingPercent:    cfg.Render.PaddingPercent,
+		ImageMaxAge:       time.Duration(cfg.Render.ImageMaxAge),
+		MaxURLLength:      cfg.Render.MaxURLLength,
+		UploadsDir:        cfg.Storage.UploadsDir,
+	})
+	r.Use(h.CountErrors())
 	api := r.Group("/api")
 	{
-		api.GET("/qr", h.QRCodeHandler)
+		// Image endpoints are charged by what they render. API keys are
+		// limited on their own and need the scope of the route
+		render := api.Group("", h.Scope(design.ScopeRender), h.RateLimit(handlers.LimitRender))
+		render.GET("/qr", h.QRCodeHandler)
+		render.GET("/qr/info", h.QRInfoHandler)
+		render.GET("/code", h.CodeHandler)
+		render.GET("/barcode", h.BarcodeHandler)
+		render.GET("/qr/append", h.QRAppendHandler)
+		render.POST("/qr/append", h.QRAppendHandler)
+		render.POST("/v1/qr", h.QRV1Handler)
 		api.POST("/htmx/toast", h.GenericToast)
+
+		batch := api.Group("/v1", h.Scope(design.ScopeBatch), h.RateLimit(handlers.LimitBatch))
+		batch.POST("/batch", h.BatchHandler)
+		batch.POST("/sheet", h.SheetHandler)
+
+		v1 := api.Group("/v1", h.RateLimit(handlers.LimitAPI))
+		v1.GET("/sheet/templates", h.SheetTemplatesHandler)
+		v1.GET("/openapi.json", h.OpenAPIHandler)
+		v1.GET("/presets", h.PresetsHandler)
+		v1.GET("/presets/:id", h.PresetHandler)
+		presets := v1.Group("/presets", h.Scope(design.ScopePresets))
+		presets.POST("", h.CreatePresetHandler)
+		presets.PUT("/:id", h.UpdatePresetHandler)
+		presets.DELETE("/:id", h.DeletePresetHandler)
+		v1.POST("/tokens", h.CreateTokenHandler)
+		links := v1.Group("/links", h.Scope(design.ScopeLinks))
+		links.POST("", h.CreateLinkHandler)
+		links.GET("/:slug", h.LinkHandler)
+		links.PUT("/:slug", h.UpdateLinkHandler)
+		links.DELETE("/:slug", h.DeleteLinkHandler)
+		links.GET("/:slug/scans", h.ScansHandler)
+		v1.GET("/campaigns", h.CampaignsHandler)
+		v1.GET("/campaigns/:id", h.CampaignHandler)
+		// Templates tag the codes of everyone who names them
+		campaigns := v1.Group("/campaigns", h.RequireAdmin())
+		campaigns.POST("", h.CreateCampaignHandler)
+		campaigns.PUT("/:id", h.UpdateCampaignHandler)
+		campaigns.DELETE("/:id", h.DeleteCampaignHandler)
+		// Not charged, so a key that used up its quota can still see it
+		api.GET("/v1/usage", h.UsageHandler)
+		keys := v1.Group("/keys", h.RequireAdmin())
+		keys.GET("", h.APIKeysHandler)
+		keys.POST("", h.CreateAPIKeyHandler)
+		keys.GET("/:id", h.APIKeyHandler)
+		keys.PUT("/:id", h.UpdateAPIKeyHandler)
+		keys.DELETE("/:id", h.DeleteAPIKeyHandler)
+		keys.GET("/:id/usage", h.APIKeyUsageHandler)
+		v1.GET("/jobs/:id", h.JobHandler)
+		v1.GET("/jobs/:id/artifact", h.JobArtifactHandler)
+		v1.DELETE("/jobs/:id", h.CancelJobHandler)
 	}
 
+	// Dynamic codes
+	r.GET("/r/:slug", h.RedirectHandler)
+	r.POST("/r/:slug", h.PasscodeHandler)
+	r.GET("/links/:slug", h.LinkDashboardPage)
+
+	// Design token images
+	r.GET("/q/:file", h.RateLimit(handlers.LimitRender), h.TokenImageHandler)
+
+	// Probes of the orchestrator
+	r.GET("/healthz", h.HealthzHandler)
+	r.GET("/readyz", h.ReadyzHandler)
+
 	// SEO assets
 	r.GET("/sitemap.xml", h.SitemapXML)
 	r.GET("/robots.txt", func(c *gin.Context) {
@@ -51,18 +255,159 @@ func main() {
 		}
 	})
 
-	addr := getAddr()
-	log.Printf("qrcreator.link listening on %s", addr)
-	if err := r.Run(addr); err != nil {
-		log.Fatal(err)
+	// Metrics, on their own address when metrics.addr is set so they can
+	// be kept off the public listener
+	mh := metricsHandler(reg, cfg.Metrics.Token)
+	var msrv *http.Server
+	if maddr := cfg.Metrics.Addr; maddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", mh)
+		msrv = newServer(cfg.Server, maddr, mux)
+	} else {
+		r.GET("/metrics", gin.WrapH(mh))
+	}
+
+	srv := newServer(cfg.Server, cfg.Addr(), r)
+	errc := make(chan error, 2)
+	serve := func(name string, s *http.Server) {
+		slog.Info(name+" listening", "addr", s.Addr)
+		go func() { errc <- s.ListenAndServe() }()
+	}
+	serve("qrcreator.link", srv)
+	if msrv != nil {
+		serve("metrics", msrv)
+	}
+	select {
+	case err := <-errc:
+		fatal("server stopped", err)
+	case <-ctx.Done():
+	}
+
+	// Fail readiness first, then stop accepting connections and let the
+	// requests and jobs in progress finish. Requests and jobs wind down
+	// side by side, each with its own deadline, so slow requests do not
+	// eat into the time of the jobs. A second signal exits at once
+	stop()
+	timeout := time.Duration(cfg.Server.ShutdownTimeout)
+	slog.Info("shutting down", "delay", time.Duration(cfg.Server.ShutdownDelay).String(), "timeout", timeout.String())
+	h.Drain()
+	time.Sleep(time.Duration(cfg.Server.ShutdownDelay))
+	jobsDone := make(chan error, 1)
+	go func() {
+		jctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		jobsDone <- jm.Shutdown(jctx)
+	}()
+	sctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(sctx); err != nil {
+		slog.Warn("requests in progress were cut off", "err", err)
+	}
+	if msrv != nil {
+		msrv.Shutdown(sctx)
+	}
+	if err := <-jobsDone; err != nil {
+		slog.Warn("jobs in progress were canceled", "err", err)
+	}
+	renders.Close()
+	slog.Info("stopped")
+}
+
+// newServer returns a server of handler on addr with the timeouts of c.
+func newServer(c config.Server, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(c.ReadHeaderTimeout),
+		ReadTimeout:       time.Duration(c.ReadTimeout),
+		WriteTimeout:      time.Duration(c.WriteTimeout),
+		IdleTimeout:       time.Duration(c.IdleTimeout),
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
 	}
 }
 
-func getAddr() string {
-	if port := os.Getenv("PORT"); port != "" {
-		return ":" + port
+// newLogger returns the logger configured by the log settings. Hashes are
+// keyed with hash_key, or a key made up at start, so they can only be
+// compared within one run.
+func newLogger(c config.Log) (*slog.Logger, error) {
+	level, err := logging.ParseLevel(c.Level)
+	if err != nil {
+		return nil, fmt.Errorf("log.level: %w", err)
+	}
+	key := []byte(c.HashKey)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	return logging.New(os.Stderr, logging.Options{
+		Level:   level,
+		Format:  c.Format,
+		Redact:  c.Redact,
+		HashKey: key,
+	})
+}
+
+// metricsHandler serves the metrics of reg, to requests carrying token as
+// a Bearer token when it is set.
+func metricsHandler(reg *metrics.Registry, token string) http.Handler {
+	if token == "" {
+		return reg
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "metrics token required", http.StatusUnauthorized)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	})
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+// tokenSigner returns the signer of design tokens. Keys come from
+// tokenKeys, the first one signing; a key generated on first start and
+// kept in the database is used as key 0 when no configured key has that
+// ID, so links keep working after keys are configured.
+func tokenSigner(db *store.Store, tokenKeys string) (*token.Signer, error) {
+	secret, err := db.Secret("token-key", 32)
+	if err != nil {
+		return nil, err
+	}
+	stored := token.Key{ID: 0, Secret: secret}
+	if tokenKeys == "" {
+		return token.NewSigner(stored)
+	}
+	keys, err := token.ParseKeys(tokenKeys)
+	if err != nil {
+		return nil, fmt.Errorf("keys.token_keys: %w", err)
+	}
+	if !slices.ContainsFunc(keys, func(k token.Key) bool { return k.ID == 0 }) {
+		keys = append(keys, stored)
+	}
+	return token.NewSigner(keys...)
+}
+
+// urlScreener returns the screener of the URLs to encode: the blocklist
+// files and the actions of the checks, like "ip=block,credentials=allow".
+func urlScreener(c config.URLs) (*screen.Screener, error) {
+	actions, err := screen.ParseActions(c.Screen)
+	if err != nil {
+		return nil, fmt.Errorf("urls.screen: %w", err)
+	}
+	s := &screen.Screener{Actions: actions}
+	if len(c.Blocklist) > 0 {
+		if s.List, err = screen.LoadBlocklist(c.Blocklist...); err != nil {
+			return nil, err
+		}
+		slog.Info("URL blocklist loaded", "entries", s.List.Len())
 	}
-	return ":8080"
+	return s, nil
 }
 
 // schemeFromReq returns https if TLS present, else http.
@@ -13,6 +13,7 @@ import (
 	"github.com/cristianadrielbraun/qrcreator.link/web/components/ui/card"
 	"github.com/cristianadrielbraun/qrcreator.link/web/components/ui/checkbox"
 	"github.com/cristianadrielbraun/qrcreator.link/web/components/ui/icon"
+	"github.com/cristianadrielbraun/qrcreator.link/web/components/ui/input"
 	"github.com/cristianadrielbraun/qrcreator.link/web/components/ui/label"
 	"github.com/cristianadrielbraun/qrcreator.link/web/components/ui/radio"
 	"github.com/cristianadrielbraun/qrcreator.link/web/components/ui/separator"
@@ -129,7 +130,7 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "Color Mode ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "Code Type ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
@@ -179,16 +180,16 @@ func QRCodeSection() templ.Component {
 								}()
 							}
 							ctx = templ.InitializeContext(ctx)
-							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "Flat ")
+							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "QR Code ")
 							if templ_7745c5c3_Err != nil {
 								return templ_7745c5c3_Err
 							}
 							return nil
 						})
 						templ_7745c5c3_Err = tabs.Trigger(tabs.TriggerProps{
-							Value:      "flat",
+							Value:      "qr",
 							IsActive:   true,
-							Attributes: templ.Attributes{"@click": "settings.colorMode = 'flat'; updateQRCode()"},
+							Attributes: templ.Attributes{"@click": "settings.codeType = 'qr'; updateQRCode()"},
 						}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var9), templ_7745c5c3_Buffer)
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
@@ -209,16 +210,16 @@ func QRCodeSection() templ.Component {
 								}()
 							}
 							ctx = templ.InitializeContext(ctx)
-							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "Gradient ")
+							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "Barcode ")
 							if templ_7745c5c3_Err != nil {
 								return templ_7745c5c3_Err
 							}
 							return nil
 						})
 						templ_7745c5c3_Err = tabs.Trigger(tabs.TriggerProps{
-							Value:      "gradient",
+							Value:      "barcode",
 							IsActive:   false,
-							Attributes: templ.Attributes{"@click": "settings.colorMode = 'gradient'; updateQRCode()"},
+							Attributes: templ.Attributes{"@click": "settings.codeType = 'barcode'; updateQRCode()"},
 						}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var10), templ_7745c5c3_Buffer)
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
@@ -235,7 +236,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "</div></div><template x-if=\"settings.colorMode === 'flat'\"><div x-cloak class=\"space-y-4\"><div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "</div></div><div x-show=\"settings.codeType === 'qr'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -251,7 +252,7 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "Foreground ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "Preset ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
@@ -261,7 +262,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "<input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer mt-2\" x-model=\"settings.foregroundColor\" @change=\"updateQRCode()\"></div><div><div class=\"flex items-center justify-between mb-2\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "<div class=\"flex gap-2 mt-2\"><select class=\"flex-1 min-w-0 h-10 px-3 text-sm bg-transparent border border-gray-300 dark:border-gray-600 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500\" x-model=\"presetId\" @change=\"applyPreset()\"><option value=\"\">Custom design</option><template x-for=\"p in presets\" :key=\"p.id\"><option x-bind:value=\"p.id\" x-text=\"p.kind === 'brand' ? p.name + ' (brand kit)' : p.name\"></option></template></select>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -277,17 +278,396 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "Background ")
+					templ_7745c5c3_Err = icon.Icon("save")(icon.Props{Size: 16}).Render(ctx, templ_7745c5c3_Buffer)
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					return nil
+				})
+				templ_7745c5c3_Err = button.Button(button.Props{Variant: button.VariantOutline, Size: button.SizeIcon, Class: "border shrink-0", Attributes: templ.Attributes{"@click": "savePreset()", "title": "Save the current design as a preset"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var12), templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Var13 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+					if !templ_7745c5c3_IsBuffer {
+						defer func() {
+							templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+							if templ_7745c5c3_Err == nil {
+								templ_7745c5c3_Err = templ_7745c5c3_BufErr
+							}
+						}()
+					}
+					ctx = templ.InitializeContext(ctx)
+					templ_7745c5c3_Err = icon.Icon("trash-2")(icon.Props{Size: 16}).Render(ctx, templ_7745c5c3_Buffer)
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					return nil
+				})
+				templ_7745c5c3_Err = button.Button(button.Props{Variant: button.VariantOutline, Size: button.SizeIcon, Class: "border shrink-0", Attributes: templ.Attributes{"@click": "deletePreset()", "x-bind:disabled": "!presetKey(presetId)", "title": "Delete the selected preset"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var13), templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "</div></div><div x-show=\"settings.codeType === 'barcode'\" x-cloak class=\"space-y-4\"><div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Var14 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+					if !templ_7745c5c3_IsBuffer {
+						defer func() {
+							templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+							if templ_7745c5c3_Err == nil {
+								templ_7745c5c3_Err = templ_7745c5c3_BufErr
+							}
+						}()
+					}
+					ctx = templ.InitializeContext(ctx)
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "Symbology ")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					return nil
+				})
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var14), templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<select class=\"w-full h-10 px-3 mt-2 text-sm bg-transparent border border-gray-300 dark:border-gray-600 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500\" x-model=\"settings.symbology\" @change=\"updateQRCode()\"><option value=\"code128\">Code 128</option> <option value=\"gs1-128\">GS1-128</option> <option value=\"ean13\">EAN-13</option> <option value=\"upca\">UPC-A</option> <option value=\"code39\">Code 39</option> <option value=\"itf14\">ITF-14</option></select></div><div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Var15 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+					if !templ_7745c5c3_IsBuffer {
+						defer func() {
+							templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+							if templ_7745c5c3_Err == nil {
+								templ_7745c5c3_Err = templ_7745c5c3_BufErr
+							}
+						}()
+					}
+					ctx = templ.InitializeContext(ctx)
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "Data ")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					return nil
+				})
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var15), templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "<div class=\"mt-2\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = input.Input(input.Props{
+					Placeholder: "Leave empty to encode the URL",
+					Attributes: templ.Attributes{
+						"x-model": "settings.barcodeData",
+						"@input":  "updateQRCode()",
+					},
+				}).Render(ctx, templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "</div><p class=\"text-xs text-gray-600 dark:text-gray-400 mt-1\" x-text=\"barcodeHint\"></p></div><div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Var16 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+					if !templ_7745c5c3_IsBuffer {
+						defer func() {
+							templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+							if templ_7745c5c3_Err == nil {
+								templ_7745c5c3_Err = templ_7745c5c3_BufErr
+							}
+						}()
+					}
+					ctx = templ.InitializeContext(ctx)
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "Bar Width Reduction (%) ")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					return nil
+				})
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var16), templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "<div class=\"mt-2\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = input.Input(input.Props{
+					Type: input.TypeNumber,
+					Attributes: templ.Attributes{
+						"x-model.number": "settings.barWidthReduction",
+						"min":            "0",
+						"max":            "50",
+						"@change":        "updateQRCode()",
+					},
+				}).Render(ctx, templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "</div></div><div class=\"flex items-center space-x-2\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = checkbox.Checkbox(checkbox.Props{
+					ID:   "barcode-hrt",
+					Name: "humanReadableText",
+					Attributes: templ.Attributes{
+						"x-model": "settings.humanReadableText",
+						"@change": "updateQRCode()",
+					},
+				}).Render(ctx, templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "<label for=\"barcode-hrt\" class=\"text-sm text-gray-700 dark:text-gray-300 cursor-pointer\">Show text under the bars</label></div></div><div x-show=\"settings.codeType === 'qr'\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Var17 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+					if !templ_7745c5c3_IsBuffer {
+						defer func() {
+							templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+							if templ_7745c5c3_Err == nil {
+								templ_7745c5c3_Err = templ_7745c5c3_BufErr
+							}
+						}()
+					}
+					ctx = templ.InitializeContext(ctx)
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "Campaign Tags ")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					return nil
+				})
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var17), templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "<div class=\"grid grid-cols-3 gap-2 mt-2\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = input.Input(input.Props{
+					Placeholder: "Source",
+					Attributes: templ.Attributes{
+						"x-model": "settings.utmSource",
+						"@input":  "updateQRCode()",
+					},
+				}).Render(ctx, templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = input.Input(input.Props{
+					Placeholder: "Medium",
+					Attributes: templ.Attributes{
+						"x-model": "settings.utmMedium",
+						"@input":  "updateQRCode()",
+					},
+				}).Render(ctx, templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = input.Input(input.Props{
+					Placeholder: "Campaign",
+					Attributes: templ.Attributes{
+						"x-model": "settings.utmCampaign",
+						"@input":  "updateQRCode()",
+					},
+				}).Render(ctx, templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "</div><p class=\"text-xs text-gray-600 dark:text-gray-400 mt-1\">Added to the URL as utm_source, utm_medium and utm_campaign</p></div><div x-show=\"settings.codeType === 'qr'\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Var18 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+					if !templ_7745c5c3_IsBuffer {
+						defer func() {
+							templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+							if templ_7745c5c3_Err == nil {
+								templ_7745c5c3_Err = templ_7745c5c3_BufErr
+							}
+						}()
+					}
+					ctx = templ.InitializeContext(ctx)
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "Color Mode ")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					return nil
+				})
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var18), templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, "<div class=\"mt-2\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Var19 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+					if !templ_7745c5c3_IsBuffer {
+						defer func() {
+							templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+							if templ_7745c5c3_Err == nil {
+								templ_7745c5c3_Err = templ_7745c5c3_BufErr
+							}
+						}()
+					}
+					ctx = templ.InitializeContext(ctx)
+					templ_7745c5c3_Var20 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+						templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+						templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+						if !templ_7745c5c3_IsBuffer {
+							defer func() {
+								templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+								if templ_7745c5c3_Err == nil {
+									templ_7745c5c3_Err = templ_7745c5c3_BufErr
+								}
+							}()
+						}
+						ctx = templ.InitializeContext(ctx)
+						templ_7745c5c3_Var21 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+							templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+							templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+							if !templ_7745c5c3_IsBuffer {
+								defer func() {
+									templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+									if templ_7745c5c3_Err == nil {
+										templ_7745c5c3_Err = templ_7745c5c3_BufErr
+									}
+								}()
+							}
+							ctx = templ.InitializeContext(ctx)
+							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, "Flat ")
+							if templ_7745c5c3_Err != nil {
+								return templ_7745c5c3_Err
+							}
+							return nil
+						})
+						templ_7745c5c3_Err = tabs.Trigger(tabs.TriggerProps{
+							Value:      "flat",
+							IsActive:   true,
+							Attributes: templ.Attributes{"@click": "settings.colorMode = 'flat'; updateQRCode()"},
+						}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var21), templ_7745c5c3_Buffer)
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 29, " ")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						templ_7745c5c3_Var22 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+							templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+							templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+							if !templ_7745c5c3_IsBuffer {
+								defer func() {
+									templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+									if templ_7745c5c3_Err == nil {
+										templ_7745c5c3_Err = templ_7745c5c3_BufErr
+									}
+								}()
+							}
+							ctx = templ.InitializeContext(ctx)
+							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 30, "Gradient ")
+							if templ_7745c5c3_Err != nil {
+								return templ_7745c5c3_Err
+							}
+							return nil
+						})
+						templ_7745c5c3_Err = tabs.Trigger(tabs.TriggerProps{
+							Value:      "gradient",
+							IsActive:   false,
+							Attributes: templ.Attributes{"@click": "settings.colorMode = 'gradient'; updateQRCode()"},
+						}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var22), templ_7745c5c3_Buffer)
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						return nil
+					})
+					templ_7745c5c3_Err = tabs.List(tabs.ListProps{Class: "w-full"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var20), templ_7745c5c3_Buffer)
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					return nil
+				})
+				templ_7745c5c3_Err = tabs.Tabs(tabs.Props{Class: "analytics-tabs"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var19), templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 31, "</div></div><template x-if=\"settings.colorMode === 'flat' || settings.codeType === 'barcode'\"><div x-cloak class=\"space-y-4\"><div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Var23 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+					if !templ_7745c5c3_IsBuffer {
+						defer func() {
+							templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+							if templ_7745c5c3_Err == nil {
+								templ_7745c5c3_Err = templ_7745c5c3_BufErr
+							}
+						}()
+					}
+					ctx = templ.InitializeContext(ctx)
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 32, "Foreground ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var12), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var23), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "<div class=\"flex items-center space-x-2\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 33, "<input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer mt-2\" x-model=\"settings.foregroundColor\" @change=\"updateQRCode()\"></div><div><div class=\"flex items-center justify-between mb-2\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Var24 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+					if !templ_7745c5c3_IsBuffer {
+						defer func() {
+							templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+							if templ_7745c5c3_Err == nil {
+								templ_7745c5c3_Err = templ_7745c5c3_BufErr
+							}
+						}()
+					}
+					ctx = templ.InitializeContext(ctx)
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 34, "Background ")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					return nil
+				})
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var24), templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 35, "<div class=\"flex items-center space-x-2\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -302,11 +682,11 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<label for=\"transparent-bg\" class=\"text-xs text-gray-600 dark:text-gray-400 cursor-pointer whitespace-nowrap\">Transparent</label></div></div><input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer transition-opacity\" x-model=\"settings.backgroundColor\" x-bind:disabled=\"settings.transparentBackground\" x-bind:class=\"{ 'opacity-50 cursor-not-allowed': settings.transparentBackground }\" @change=\"updateQRCode()\"></div></div></template><template x-if=\"settings.colorMode === 'gradient'\"><div x-cloak class=\"space-y-6\"><div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 36, "<label for=\"transparent-bg\" class=\"text-xs text-gray-600 dark:text-gray-400 cursor-pointer whitespace-nowrap\">Transparent</label></div></div><input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer transition-opacity\" x-model=\"settings.backgroundColor\" x-bind:disabled=\"settings.transparentBackground\" x-bind:class=\"{ 'opacity-50 cursor-not-allowed': settings.transparentBackground }\" @change=\"updateQRCode()\"></div></div></template><template x-if=\"settings.colorMode === 'gradient' && settings.codeType === 'qr'\"><div x-cloak class=\"space-y-6\"><div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var13 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var25 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -318,21 +698,21 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "Foreground Gradient ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 37, "Foreground Gradient ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var13), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var25), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "<div class=\"grid grid-cols-3 gap-3 mt-2\"><div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 38, "<div class=\"grid grid-cols-3 gap-3 mt-2\"><div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var14 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var26 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -344,21 +724,21 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "Start ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 39, "Start ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var14), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var26), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "<input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer\" x-model=\"settings.gradientStart\" @change=\"updateQRCode()\"></div><div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 40, "<input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer\" x-model=\"settings.gradientStart\" @change=\"updateQRCode()\"></div><div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var15 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var27 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -370,21 +750,21 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "Middle ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 41, "Middle ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var15), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var27), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "<input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer\" x-model=\"settings.gradientMiddle\" @change=\"updateQRCode()\"></div><div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 42, "<input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer\" x-model=\"settings.gradientMiddle\" @change=\"updateQRCode()\"></div><div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var16 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var28 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -396,21 +776,21 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "End ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 43, "End ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var16), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var28), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "<input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer\" x-model=\"settings.gradientEnd\" @change=\"updateQRCode()\"></div></div></div><div><div class=\"flex items-center justify-between mb-2\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 44, "<input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer\" x-model=\"settings.gradientEnd\" @change=\"updateQRCode()\"></div></div></div><div><div class=\"flex items-center justify-between mb-2\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var17 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var29 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -422,17 +802,17 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "Background ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 45, "Background ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var17), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var29), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "<div class=\"flex items-center space-x-2\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 46, "<div class=\"flex items-center space-x-2\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -447,7 +827,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "<label for=\"transparent-bg-gradient\" class=\"text-xs text-gray-600 dark:text-gray-400 cursor-pointer whitespace-nowrap\">Transparent</label></div></div><input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer transition-opacity\" x-model=\"settings.backgroundColor\" x-bind:disabled=\"settings.transparentBackground\" x-bind:class=\"{ 'opacity-50 cursor-not-allowed': settings.transparentBackground }\" @change=\"updateQRCode()\"></div></div></template>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 47, "<label for=\"transparent-bg-gradient\" class=\"text-xs text-gray-600 dark:text-gray-400 cursor-pointer whitespace-nowrap\">Transparent</label></div></div><input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer transition-opacity\" x-model=\"settings.backgroundColor\" x-bind:disabled=\"settings.transparentBackground\" x-bind:class=\"{ 'opacity-50 cursor-not-allowed': settings.transparentBackground }\" @change=\"updateQRCode()\"></div></div></template>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -455,11 +835,11 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, "<div class=\"space-y-4\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 48, "<div x-show=\"settings.codeType === 'qr'\" class=\"space-y-6\"><div class=\"space-y-4\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var18 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var30 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -471,21 +851,21 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, "Frame Style ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 49, "Frame Style ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var18), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var30), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 29, "<div class=\"mt-2\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 50, "<div class=\"mt-2\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var19 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var31 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -497,7 +877,7 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Var20 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_Var32 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 						templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 						templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 						if !templ_7745c5c3_IsBuffer {
@@ -509,7 +889,7 @@ func QRCodeSection() templ.Component {
 							}()
 						}
 						ctx = templ.InitializeContext(ctx)
-						templ_7745c5c3_Var21 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+						templ_7745c5c3_Var33 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 							templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 							templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 							if !templ_7745c5c3_IsBuffer {
@@ -521,21 +901,21 @@ func QRCodeSection() templ.Component {
 								}()
 							}
 							ctx = templ.InitializeContext(ctx)
-							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 30, "None ")
+							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 51, "None ")
 							if templ_7745c5c3_Err != nil {
 								return templ_7745c5c3_Err
 							}
 							return nil
 						})
-						templ_7745c5c3_Err = tabs.Trigger(tabs.TriggerProps{Value: "none", IsActive: true, Attributes: templ.Attributes{"@click": "settings.cornerStyle = 'none'; updateQRCode()"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var21), templ_7745c5c3_Buffer)
+						templ_7745c5c3_Err = tabs.Trigger(tabs.TriggerProps{Value: "none", IsActive: true, Attributes: templ.Attributes{"@click": "settings.cornerStyle = 'none'; updateQRCode()"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var33), templ_7745c5c3_Buffer)
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 31, " ")
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 52, " ")
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						templ_7745c5c3_Var22 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+						templ_7745c5c3_Var34 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 							templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 							templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 							if !templ_7745c5c3_IsBuffer {
@@ -547,21 +927,21 @@ func QRCodeSection() templ.Component {
 								}()
 							}
 							ctx = templ.InitializeContext(ctx)
-							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 32, "Straight ")
+							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 53, "Straight ")
 							if templ_7745c5c3_Err != nil {
 								return templ_7745c5c3_Err
 							}
 							return nil
 						})
-						templ_7745c5c3_Err = tabs.Trigger(tabs.TriggerProps{Value: "straight", Attributes: templ.Attributes{"@click": "settings.cornerStyle = 'straight'; updateQRCode()"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var22), templ_7745c5c3_Buffer)
+						templ_7745c5c3_Err = tabs.Trigger(tabs.TriggerProps{Value: "straight", Attributes: templ.Attributes{"@click": "settings.cornerStyle = 'straight'; updateQRCode()"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var34), templ_7745c5c3_Buffer)
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 33, " ")
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 54, " ")
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						templ_7745c5c3_Var23 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+						templ_7745c5c3_Var35 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 							templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 							templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 							if !templ_7745c5c3_IsBuffer {
@@ -573,29 +953,29 @@ func QRCodeSection() templ.Component {
 								}()
 							}
 							ctx = templ.InitializeContext(ctx)
-							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 34, "Rounded ")
+							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 55, "Rounded ")
 							if templ_7745c5c3_Err != nil {
 								return templ_7745c5c3_Err
 							}
 							return nil
 						})
-						templ_7745c5c3_Err = tabs.Trigger(tabs.TriggerProps{Value: "rounded", Attributes: templ.Attributes{"@click": "settings.cornerStyle = 'rounded'; updateQRCode()"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var23), templ_7745c5c3_Buffer)
+						templ_7745c5c3_Err = tabs.Trigger(tabs.TriggerProps{Value: "rounded", Attributes: templ.Attributes{"@click": "settings.cornerStyle = 'rounded'; updateQRCode()"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var35), templ_7745c5c3_Buffer)
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
 						return nil
 					})
-					templ_7745c5c3_Err = tabs.List(tabs.ListProps{Class: "w-full"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var20), templ_7745c5c3_Buffer)
+					templ_7745c5c3_Err = tabs.List(tabs.ListProps{Class: "w-full"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var32), templ_7745c5c3_Buffer)
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = tabs.Tabs(tabs.Props{Class: "analytics-tabs"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var19), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = tabs.Tabs(tabs.Props{Class: "analytics-tabs"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var31), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 35, "</div><div x-show=\"settings.cornerStyle !== 'none'\" x-transition:enter=\"transition ease-out duration-300\" x-transition:enter-start=\"opacity-0 transform translate-y-2\" x-transition:enter-end=\"opacity-100 transform translate-y-0\" x-transition:leave=\"transition ease-in duration-200\" x-transition:leave-start=\"opacity-100 transform translate-y-0\" x-transition:leave-end=\"opacity-0 transform translate-y-2\" class=\"space-y-2\"><div class=\"text-sm font-medium text-gray-700 dark:text-gray-300\">Border Pattern</div><div class=\"grid grid-cols-3 gap-2\"><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'simple' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 56, "</div><div x-show=\"settings.cornerStyle !== 'none'\" x-transition:enter=\"transition ease-out duration-300\" x-transition:enter-start=\"opacity-0 transform translate-y-2\" x-transition:enter-end=\"opacity-100 transform translate-y-0\" x-transition:leave=\"transition ease-in duration-200\" x-transition:leave-start=\"opacity-100 transform translate-y-0\" x-transition:leave-end=\"opacity-0 transform translate-y-2\" class=\"space-y-2\"><div class=\"text-sm font-medium text-gray-700 dark:text-gray-300\">Border Pattern</div><div class=\"grid grid-cols-3 gap-2\"><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'simple' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -603,7 +983,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 36, "<div class=\"w-6 h-6 border-2 border-gray-800 dark:border-gray-200\"></div><div class=\"text-xs font-medium\">Simple</div></label> <label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'irregular' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 57, "<div class=\"w-6 h-6 border-2 border-gray-800 dark:border-gray-200\"></div><div class=\"text-xs font-medium\">Simple</div></label> <label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'irregular' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -611,7 +991,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 37, "<div class=\"w-6 h-6 relative overflow-hidden grid place-items-center\"><svg class=\"w-full h-full\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><path d=\"M1 2 L5 1.8 L9 2.2 L13 1.9 L17 2.1 L21 1.8 L23 2\" stroke=\"currentColor\" stroke-width=\"1.5\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M2 2.8 L6 2.6 L10 3 L14 2.7 L18 2.9 L22 2.6\" stroke=\"currentColor\" stroke-width=\"0.8\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M22 2 L22.2 6 L21.8 10 L22.1 14 L21.9 18 L22.2 22\" stroke=\"currentColor\" stroke-width=\"1.5\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M21.2 3 L21.4 7 L21 11 L21.3 15 L21.1 19 L21.4 21\" stroke=\"currentColor\" stroke-width=\"0.8\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M22 22 L18 22.2 L14 21.8 L10 22.1 L6 21.9 L2 22.2 L1 22\" stroke=\"currentColor\" stroke-width=\"1.5\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M21 21.2 L17 21.4 L13 21 L9 21.3 L5 21.1 L2 21.4\" stroke=\"currentColor\" stroke-width=\"0.8\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M2 2 L2.2 6 L1.8 10 L2.1 14 L1.9 18 L2.2 22\" stroke=\"currentColor\" stroke-width=\"1.5\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M3 3 L3.2 7 L2.8 11 L3.1 15 L2.9 19 L3.2 21\" stroke=\"currentColor\" stroke-width=\"0.8\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path></svg></div><div class=\"text-xs font-medium\">Irregular</div></label> <label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'dashed' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 58, "<div class=\"w-6 h-6 relative overflow-hidden grid place-items-center\"><svg class=\"w-full h-full\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><path d=\"M1 2 L5 1.8 L9 2.2 L13 1.9 L17 2.1 L21 1.8 L23 2\" stroke=\"currentColor\" stroke-width=\"1.5\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M2 2.8 L6 2.6 L10 3 L14 2.7 L18 2.9 L22 2.6\" stroke=\"currentColor\" stroke-width=\"0.8\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M22 2 L22.2 6 L21.8 10 L22.1 14 L21.9 18 L22.2 22\" stroke=\"currentColor\" stroke-width=\"1.5\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M21.2 3 L21.4 7 L21 11 L21.3 15 L21.1 19 L21.4 21\" stroke=\"currentColor\" stroke-width=\"0.8\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M22 22 L18 22.2 L14 21.8 L10 22.1 L6 21.9 L2 22.2 L1 22\" stroke=\"currentColor\" stroke-width=\"1.5\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M21 21.2 L17 21.4 L13 21 L9 21.3 L5 21.1 L2 21.4\" stroke=\"currentColor\" stroke-width=\"0.8\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M2 2 L2.2 6 L1.8 10 L2.1 14 L1.9 18 L2.2 22\" stroke=\"currentColor\" stroke-width=\"1.5\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path> <path d=\"M3 3 L3.2 7 L2.8 11 L3.1 15 L2.9 19 L3.2 21\" stroke=\"currentColor\" stroke-width=\"0.8\" fill=\"none\" class=\"text-gray-800 dark:text-gray-200\"></path></svg></div><div class=\"text-xs font-medium\">Irregular</div></label> <label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'dashed' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -619,7 +999,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 38, "<div class=\"w-6 h-6 border-2 border-dashed border-gray-800 dark:border-gray-200\"></div><div class=\"text-xs font-medium\">Dashed</div></label> <label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'grid' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 59, "<div class=\"w-6 h-6 border-2 border-dashed border-gray-800 dark:border-gray-200\"></div><div class=\"text-xs font-medium\">Dashed</div></label> <label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'grid' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -627,7 +1007,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 39, "<div class=\"w-6 h-6 border-2 border-gray-800 dark:border-gray-200 relative overflow-hidden\"><div class=\"absolute inset-0 grid grid-cols-3 grid-rows-3 gap-px\"><div class=\"bg-gray-800 dark:bg-gray-200\"></div><div></div><div class=\"bg-gray-800 dark:bg-gray-200\"></div><div></div><div class=\"bg-gray-800 dark:bg-gray-200\"></div><div></div><div class=\"bg-gray-800 dark:bg-gray-200\"></div><div></div><div class=\"bg-gray-800 dark:bg-gray-200\"></div></div></div><div class=\"text-xs font-medium\">Grid</div></label> <label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'double' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 60, "<div class=\"w-6 h-6 border-2 border-gray-800 dark:border-gray-200 relative overflow-hidden\"><div class=\"absolute inset-0 grid grid-cols-3 grid-rows-3 gap-px\"><div class=\"bg-gray-800 dark:bg-gray-200\"></div><div></div><div class=\"bg-gray-800 dark:bg-gray-200\"></div><div></div><div class=\"bg-gray-800 dark:bg-gray-200\"></div><div></div><div class=\"bg-gray-800 dark:bg-gray-200\"></div><div></div><div class=\"bg-gray-800 dark:bg-gray-200\"></div></div></div><div class=\"text-xs font-medium\">Grid</div></label> <label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'double' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -635,7 +1015,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 40, "<div class=\"w-6 h-6 border-4 border-double border-gray-800 dark:border-gray-200\"></div><div class=\"text-xs font-medium\">Double</div></label> <label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'diagonal' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 61, "<div class=\"w-6 h-6 border-4 border-double border-gray-800 dark:border-gray-200\"></div><div class=\"text-xs font-medium\">Double</div></label> <label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.borderPattern === 'diagonal' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -643,11 +1023,11 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 41, "<div class=\"w-6 h-6 relative overflow-hidden border-2 border-gray-800 dark:border-gray-200\"><svg class=\"absolute inset-0 w-full h-full\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><path d=\"M1 23 L23 1\" stroke=\"currentColor\" stroke-width=\"3\" class=\"text-gray-800 dark:text-gray-200\"></path></svg></div><div class=\"text-xs font-medium\">Diagonal</div></label></div></div><div x-show=\"settings.cornerStyle !== 'none'\" x-transition:enter=\"transition ease-out duration-300\" x-transition:enter-start=\"opacity-0 transform translate-y-2\" x-transition:enter-end=\"opacity-100 transform translate-y-0\" x-transition:leave=\"transition ease-in duration-200\" x-transition:leave-start=\"opacity-100 transform translate-y-0\" x-transition:leave-end=\"opacity-0 transform translate-y-2\" class=\"space-y-2\"><div class=\"flex items-center justify-between mb-2\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 62, "<div class=\"w-6 h-6 relative overflow-hidden border-2 border-gray-800 dark:border-gray-200\"><svg class=\"absolute inset-0 w-full h-full\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><path d=\"M1 23 L23 1\" stroke=\"currentColor\" stroke-width=\"3\" class=\"text-gray-800 dark:text-gray-200\"></path></svg></div><div class=\"text-xs font-medium\">Diagonal</div></label></div></div><div x-show=\"settings.cornerStyle !== 'none'\" x-transition:enter=\"transition ease-out duration-300\" x-transition:enter-start=\"opacity-0 transform translate-y-2\" x-transition:enter-end=\"opacity-100 transform translate-y-0\" x-transition:leave=\"transition ease-in duration-200\" x-transition:leave-start=\"opacity-100 transform translate-y-0\" x-transition:leave-end=\"opacity-0 transform translate-y-2\" class=\"space-y-2\"><div class=\"flex items-center justify-between mb-2\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var24 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var36 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -659,17 +1039,17 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 42, "Border ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 63, "Border ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var24), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var36), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 43, "<div class=\"flex items-center space-x-2\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 64, "<div class=\"flex items-center space-x-2\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -677,7 +1057,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 44, "<label for=\"same-color-border\" class=\"text-xs text-gray-600 dark:text-gray-400 cursor-pointer whitespace-nowrap\">Same as QR</label></div></div><input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer transition-opacity\" x-model=\"settings.borderColor\" x-bind:disabled=\"settings.sameColorBorder\" x-bind:class=\"{ 'opacity-50 cursor-not-allowed': settings.sameColorBorder }\" @change=\"updateQRCode()\"></div></div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 65, "<label for=\"same-color-border\" class=\"text-xs text-gray-600 dark:text-gray-400 cursor-pointer whitespace-nowrap\">Same as QR</label></div></div><input type=\"color\" class=\"w-full h-10 rounded border border-gray-300 dark:border-gray-600 cursor-pointer transition-opacity\" x-model=\"settings.borderColor\" x-bind:disabled=\"settings.sameColorBorder\" x-bind:class=\"{ 'opacity-50 cursor-not-allowed': settings.sameColorBorder }\" @change=\"updateQRCode()\"></div></div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -685,11 +1065,11 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 45, "<div class=\"space-y-4\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 66, "<div class=\"space-y-4\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var25 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var37 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -701,17 +1081,17 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 46, "QR Style ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 67, "QR Style ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var25), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var37), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 47, "<div class=\"space-y-3\"><div><div class=\"text-sm font-medium text-gray-700 dark:text-gray-300 mb-2\">Shape</div><div class=\"grid grid-cols-3 gap-2\"><!-- Rectangle --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'rectangle' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 68, "<div class=\"space-y-3\"><div><div class=\"text-sm font-medium text-gray-700 dark:text-gray-300 mb-2\">Shape</div><div class=\"grid grid-cols-3 gap-2\"><!-- Rectangle --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'rectangle' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -719,7 +1099,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 48, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><rect x=\"2\" y=\"2\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"16\" y=\"2\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"2\" y=\"9\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"9\" y=\"9\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"9\" y=\"16\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"16\" y=\"16\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect></svg><div class=\"text-xs font-medium\">Rectangle</div></label><!-- Circle --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'circle' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 69, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><rect x=\"2\" y=\"2\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"16\" y=\"2\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"2\" y=\"9\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"9\" y=\"9\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"9\" y=\"16\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"16\" y=\"16\" width=\"6\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect></svg><div class=\"text-xs font-medium\">Rectangle</div></label><!-- Circle --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'circle' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -727,7 +1107,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 49, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><circle cx=\"5\" cy=\"5\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"19\" cy=\"5\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"5\" cy=\"12\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"12\" cy=\"12\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"12\" cy=\"19\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"19\" cy=\"19\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle></svg><div class=\"text-xs font-medium\">Circle</div></label><!-- Liquid (copy from example) --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'liquid' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 70, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><circle cx=\"5\" cy=\"5\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"19\" cy=\"5\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"5\" cy=\"12\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"12\" cy=\"12\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"12\" cy=\"19\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"19\" cy=\"19\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle></svg><div class=\"text-xs font-medium\">Circle</div></label><!-- Liquid (copy from example) --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'liquid' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -735,7 +1115,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 50, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><!-- L-shaped connection with very rounded corners --><path d=\"M 5 2 L 5 2 Q 8 2 8 5 L 8 4 Q 8 9 11 9 L 12 9 Q 15 9 15 12 L 15 12 Q 15 15 12 15 L 5 15 Q 2 15 2 12 L 2 5 Q 2 2 5 2 Z\" class=\"fill-gray-800 dark:fill-gray-200\"></path><!-- Top right circle --><circle cx=\"19\" cy=\"5\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle><!-- Bottom center and right connected squares with rounded corners --><path d=\"M 12 16 L 19 16 Q 22 16 22 19 L 22 19 Q 22 22 19 22 L 12 22 Q 9 22 9 19 L 9 19 Q 9 16 12 16 Z\" class=\"fill-gray-800 dark:fill-gray-200\"></path></svg><div class=\"text-xs font-medium\">Liquid</div></label><!-- Chain (copy from example) --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'chain' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 71, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><!-- L-shaped connection with very rounded corners --><path d=\"M 5 2 L 5 2 Q 8 2 8 5 L 8 4 Q 8 9 11 9 L 12 9 Q 15 9 15 12 L 15 12 Q 15 15 12 15 L 5 15 Q 2 15 2 12 L 2 5 Q 2 2 5 2 Z\" class=\"fill-gray-800 dark:fill-gray-200\"></path><!-- Top right circle --><circle cx=\"19\" cy=\"5\" r=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></circle><!-- Bottom center and right connected squares with rounded corners --><path d=\"M 12 16 L 19 16 Q 22 16 22 19 L 22 19 Q 22 22 19 22 L 12 22 Q 9 22 9 19 L 9 19 Q 9 16 12 16 Z\" class=\"fill-gray-800 dark:fill-gray-200\"></path></svg><div class=\"text-xs font-medium\">Liquid</div></label><!-- Chain (copy from example) --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'chain' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -743,7 +1123,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 51, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><!-- Row 1: fill empty fill --><circle cx=\"5\" cy=\"5\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"19\" cy=\"5\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle><!-- Vertical connection between top-left and middle-left --><line x1=\"5\" y1=\"7\" x2=\"5\" y2=\"10\" class=\"stroke-gray-800 dark:stroke-gray-200\" stroke-width=\"1.5\"></line><!-- Row 2: fill fill empty - with connection --><circle cx=\"5\" cy=\"12\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <line x1=\"7\" y1=\"12\" x2=\"10\" y2=\"12\" class=\"stroke-gray-800 dark:stroke-gray-200\" stroke-width=\"1.5\"></line> <circle cx=\"12\" cy=\"12\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle><!-- Row 3: empty fill fill - with connection --><circle cx=\"12\" cy=\"19\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <line x1=\"14\" y1=\"19\" x2=\"17\" y2=\"19\" class=\"stroke-gray-800 dark:stroke-gray-200\" stroke-width=\"1.5\"></line> <circle cx=\"19\" cy=\"19\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle></svg><div class=\"text-xs font-medium\">Chain</div></label><!-- H-Stripe (copy from example, value hstripe) --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'hstripe' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 72, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><!-- Row 1: fill empty fill --><circle cx=\"5\" cy=\"5\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <circle cx=\"19\" cy=\"5\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle><!-- Vertical connection between top-left and middle-left --><line x1=\"5\" y1=\"7\" x2=\"5\" y2=\"10\" class=\"stroke-gray-800 dark:stroke-gray-200\" stroke-width=\"1.5\"></line><!-- Row 2: fill fill empty - with connection --><circle cx=\"5\" cy=\"12\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <line x1=\"7\" y1=\"12\" x2=\"10\" y2=\"12\" class=\"stroke-gray-800 dark:stroke-gray-200\" stroke-width=\"1.5\"></line> <circle cx=\"12\" cy=\"12\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle><!-- Row 3: empty fill fill - with connection --><circle cx=\"12\" cy=\"19\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle> <line x1=\"14\" y1=\"19\" x2=\"17\" y2=\"19\" class=\"stroke-gray-800 dark:stroke-gray-200\" stroke-width=\"1.5\"></line> <circle cx=\"19\" cy=\"19\" r=\"2\" class=\"fill-gray-800 dark:fill-gray-200\"></circle></svg><div class=\"text-xs font-medium\">Chain</div></label><!-- H-Stripe (copy from example, value hstripe) --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'hstripe' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -751,7 +1131,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 52, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><rect x=\"2\" y=\"2\" width=\"20\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"2\" y=\"6\" width=\"12\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"16\" y=\"6\" width=\"6\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"2\" y=\"10\" width=\"8\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"12\" y=\"10\" width=\"10\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"2\" y=\"14\" width=\"16\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"2\" y=\"18\" width=\"10\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"14\" y=\"18\" width=\"8\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect></svg><div class=\"text-xs font-medium\">H-Stripe</div></label><!-- V-Stripe (copy from example, value vstripe) --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'vstripe' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 73, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><rect x=\"2\" y=\"2\" width=\"20\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"2\" y=\"6\" width=\"12\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"16\" y=\"6\" width=\"6\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"2\" y=\"10\" width=\"8\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"12\" y=\"10\" width=\"10\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"2\" y=\"14\" width=\"16\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"2\" y=\"18\" width=\"10\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"14\" y=\"18\" width=\"8\" height=\"3\" class=\"fill-gray-800 dark:fill-gray-200\"></rect></svg><div class=\"text-xs font-medium\">H-Stripe</div></label><!-- V-Stripe (copy from example, value vstripe) --><label class=\"p-2 rounded-md border cursor-pointer grid gap-1 place-items-center\" x-bind:class=\"settings.qrShape === 'vstripe' ? 'border-gray-900 dark:border-gray-100 ring-2 ring-primary' : 'border-gray-300 dark:border-gray-700'\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -759,7 +1139,7 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 53, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><rect x=\"2\" y=\"2\" width=\"3\" height=\"20\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"6\" y=\"2\" width=\"3\" height=\"12\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"6\" y=\"16\" width=\"3\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"10\" y=\"2\" width=\"3\" height=\"8\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"10\" y=\"12\" width=\"3\" height=\"10\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"14\" y=\"2\" width=\"3\" height=\"16\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"18\" y=\"2\" width=\"3\" height=\"10\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"18\" y=\"14\" width=\"3\" height=\"8\" class=\"fill-gray-800 dark:fill-gray-200\"></rect></svg><div class=\"text-xs font-medium\">V-Stripe</div></label></div></div></div></div></div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 74, "<svg class=\"w-6 h-6\" viewBox=\"0 0 24 24\" xmlns=\"http://www.w3.org/2000/svg\"><rect x=\"2\" y=\"2\" width=\"3\" height=\"20\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"6\" y=\"2\" width=\"3\" height=\"12\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"6\" y=\"16\" width=\"3\" height=\"6\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"10\" y=\"2\" width=\"3\" height=\"8\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"10\" y=\"12\" width=\"3\" height=\"10\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"14\" y=\"2\" width=\"3\" height=\"16\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"18\" y=\"2\" width=\"3\" height=\"10\" class=\"fill-gray-800 dark:fill-gray-200\"></rect> <rect x=\"18\" y=\"14\" width=\"3\" height=\"8\" class=\"fill-gray-800 dark:fill-gray-200\"></rect></svg><div class=\"text-xs font-medium\">V-Stripe</div></label></div></div></div></div></div></div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -775,11 +1155,11 @@ func QRCodeSection() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 54, "</div><!-- Center + Right wrapper: add spacing on mobile; split on lg --><div class=\"space-y-6 lg:contents\"><!-- Center: Preview --><div class=\"space-y-6\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 75, "</div><!-- Center + Right wrapper: add spacing on mobile; split on lg --><div class=\"space-y-6 lg:contents\"><!-- Center: Preview --><div class=\"space-y-6\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Var26 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_Var38 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 			if !templ_7745c5c3_IsBuffer {
@@ -791,7 +1171,7 @@ func QRCodeSection() templ.Component {
 				}()
 			}
 			ctx = templ.InitializeContext(ctx)
-			templ_7745c5c3_Var27 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+			templ_7745c5c3_Var39 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 				if !templ_7745c5c3_IsBuffer {
@@ -803,7 +1183,7 @@ func QRCodeSection() templ.Component {
 					}()
 				}
 				ctx = templ.InitializeContext(ctx)
-				templ_7745c5c3_Var28 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var40 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -815,27 +1195,27 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 55, "QR Preview ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 76, "QR Preview ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = card.Title().Render(templ.WithChildren(ctx, templ_7745c5c3_Var28), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = card.Title().Render(templ.WithChildren(ctx, templ_7745c5c3_Var40), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 				return nil
 			})
-			templ_7745c5c3_Err = card.Header().Render(templ.WithChildren(ctx, templ_7745c5c3_Var27), templ_7745c5c3_Buffer)
+			templ_7745c5c3_Err = card.Header().Render(templ.WithChildren(ctx, templ_7745c5c3_Var39), templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 56, " ")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 77, " ")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Var29 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+			templ_7745c5c3_Var41 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 				if !templ_7745c5c3_IsBuffer {
@@ -847,27 +1227,27 @@ func QRCodeSection() templ.Component {
 					}()
 				}
 				ctx = templ.InitializeContext(ctx)
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 57, "<div class=\"flex items-center justify-center\"><div class=\"relative\" x-data=\"{ observer: null }\" x-init=\"observer = new IntersectionObserver((entries) => { if (entries[0].isIntersecting) { initializeQR(); observer.disconnect(); } }, { threshold: 0.2 }); observer.observe($refs.previewContainer);\"><div class=\"flex items-center justify-center bg-transparent\" x-ref=\"previewContainer\" x-bind:style=\"'width:260px;height:290px'\"><img alt=\"QR Preview\" class=\"max-w-full max-h-full shadow-lg\" x-bind:src=\"previewImageUrl\"></div></div></div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 78, "<div class=\"flex items-center justify-center\"><div class=\"relative\" x-data=\"{ observer: null }\" x-init=\"observer = new IntersectionObserver((entries) => { if (entries[0].isIntersecting) { initializeQR(); observer.disconnect(); } }, { threshold: 0.2 }); observer.observe($refs.previewContainer);\"><div class=\"flex items-center justify-center bg-transparent\" x-ref=\"previewContainer\" x-bind:style=\"'width:260px;height:290px'\"><img alt=\"QR Preview\" class=\"max-w-full max-h-full shadow-lg\" x-bind:src=\"previewImageUrl\"></div></div></div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 				return nil
 			})
-			templ_7745c5c3_Err = card.Content().Render(templ.WithChildren(ctx, templ_7745c5c3_Var29), templ_7745c5c3_Buffer)
+			templ_7745c5c3_Err = card.Content().Render(templ.WithChildren(ctx, templ_7745c5c3_Var41), templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			return nil
 		})
-		templ_7745c5c3_Err = card.Card(card.Props{Class: "relative md:sticky md:top-6"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var26), templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = card.Card(card.Props{Class: "relative md:sticky md:top-6"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var38), templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 58, "</div><!-- Right: Actions --><div class=\"space-y-6\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 79, "</div><!-- Right: Actions --><div class=\"space-y-6\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Var30 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_Var42 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 			if !templ_7745c5c3_IsBuffer {
@@ -879,7 +1259,7 @@ func QRCodeSection() templ.Component {
 				}()
 			}
 			ctx = templ.InitializeContext(ctx)
-			templ_7745c5c3_Var31 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+			templ_7745c5c3_Var43 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 				if !templ_7745c5c3_IsBuffer {
@@ -891,7 +1271,7 @@ func QRCodeSection() templ.Component {
 					}()
 				}
 				ctx = templ.InitializeContext(ctx)
-				templ_7745c5c3_Var32 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var44 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -903,27 +1283,27 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 59, "Actions ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 80, "Actions ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = card.Title().Render(templ.WithChildren(ctx, templ_7745c5c3_Var32), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = card.Title().Render(templ.WithChildren(ctx, templ_7745c5c3_Var44), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 				return nil
 			})
-			templ_7745c5c3_Err = card.Header().Render(templ.WithChildren(ctx, templ_7745c5c3_Var31), templ_7745c5c3_Buffer)
+			templ_7745c5c3_Err = card.Header().Render(templ.WithChildren(ctx, templ_7745c5c3_Var43), templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 60, " ")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 81, " ")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Var33 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+			templ_7745c5c3_Var45 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 				if !templ_7745c5c3_IsBuffer {
@@ -935,11 +1315,11 @@ func QRCodeSection() templ.Component {
 					}()
 				}
 				ctx = templ.InitializeContext(ctx)
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 61, "<div class=\"space-y-5\"><div class=\"space-y-3\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 82, "<div class=\"space-y-5\"><div class=\"space-y-3\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var34 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var46 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -951,7 +1331,7 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 62, "<template x-if=\"isDownloading && downloadingFormat === 'PNG'\"><svg class=\"animate-spin h-4 w-4 mr-2\" xmlns=\"http://www.w3.org/2000/svg\" fill=\"none\" viewBox=\"0 0 24 24\"><circle class=\"opacity-25\" cx=\"12\" cy=\"12\" r=\"10\" stroke=\"currentColor\" stroke-width=\"4\"></circle><path class=\"opacity-75\" fill=\"currentColor\" d=\"M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 714 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z\"></path></svg></template><template x-if=\"!isDownloading || downloadingFormat !== 'PNG'\">")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 83, "<template x-if=\"isDownloading && downloadingFormat === 'PNG'\"><svg class=\"animate-spin h-4 w-4 mr-2\" xmlns=\"http://www.w3.org/2000/svg\" fill=\"none\" viewBox=\"0 0 24 24\"><circle class=\"opacity-25\" cx=\"12\" cy=\"12\" r=\"10\" stroke=\"currentColor\" stroke-width=\"4\"></circle><path class=\"opacity-75\" fill=\"currentColor\" d=\"M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 714 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z\"></path></svg></template><template x-if=\"!isDownloading || downloadingFormat !== 'PNG'\">")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
@@ -959,17 +1339,17 @@ func QRCodeSection() templ.Component {
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 63, "</template><span x-text=\"isDownloading && downloadingFormat === 'PNG' ? 'Generating PNG...' : 'Download PNG'\">Download PNG</span>")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 84, "</template><span x-text=\"isDownloading && downloadingFormat === 'PNG' ? 'Generating PNG...' : 'Download PNG'\">Download PNG</span>")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = button.Button(button.Props{FullWidth: true, Class: "border", Attributes: templ.Attributes{"@click": "download('PNG')", "x-bind:disabled": "isDownloading", "x-bind:class": "isDownloading ? 'opacity-50 cursor-not-allowed' : ''"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var34), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = button.Button(button.Props{FullWidth: true, Class: "border", Attributes: templ.Attributes{"@click": "download('PNG')", "x-bind:disabled": "isDownloading", "x-bind:class": "isDownloading ? 'opacity-50 cursor-not-allowed' : ''"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var46), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var35 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var47 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -981,7 +1361,7 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 64, "<template x-if=\"isDownloading && downloadingFormat === 'JPG'\"><svg class=\"animate-spin h-4 w-4 mr-2\" xmlns=\"http://www.w3.org/2000/svg\" fill=\"none\" viewBox=\"0 0 24 24\"><circle class=\"opacity-25\" cx=\"12\" cy=\"12\" r=\"10\" stroke=\"currentColor\" stroke-width=\"4\"></circle><path class=\"opacity-75\" fill=\"currentColor\" d=\"M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 714 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z\"></path></svg></template><template x-if=\"!isDownloading || downloadingFormat !== 'JPG'\">")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 85, "<template x-if=\"isDownloading && downloadingFormat === 'JPG'\"><svg class=\"animate-spin h-4 w-4 mr-2\" xmlns=\"http://www.w3.org/2000/svg\" fill=\"none\" viewBox=\"0 0 24 24\"><circle class=\"opacity-25\" cx=\"12\" cy=\"12\" r=\"10\" stroke=\"currentColor\" stroke-width=\"4\"></circle><path class=\"opacity-75\" fill=\"currentColor\" d=\"M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 714 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z\"></path></svg></template><template x-if=\"!isDownloading || downloadingFormat !== 'JPG'\">")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
@@ -989,11 +1369,11 @@ func QRCodeSection() templ.Component {
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 65, "</template><span x-text=\"isDownloading && downloadingFormat === 'JPG' ? 'Generating JPG...' : 'Download JPG'\">Download JPG</span>")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 86, "</template><span x-text=\"isDownloading && downloadingFormat === 'JPG' ? 'Generating JPG...' : 'Download JPG'\">Download JPG</span>")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					templ_7745c5c3_Var36 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_Var48 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 						templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 						templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 						if !templ_7745c5c3_IsBuffer {
@@ -1005,7 +1385,7 @@ func QRCodeSection() templ.Component {
 							}()
 						}
 						ctx = templ.InitializeContext(ctx)
-						templ_7745c5c3_Var37 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+						templ_7745c5c3_Var49 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 							templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 							templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 							if !templ_7745c5c3_IsBuffer {
@@ -1023,15 +1403,15 @@ func QRCodeSection() templ.Component {
 							}
 							return nil
 						})
-						templ_7745c5c3_Err = tooltip.Trigger(tooltip.TriggerProps{For: "jpg-tip", Class: "ml-2 inline-flex items-center text-slate-600 dark:text-slate-300"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var37), templ_7745c5c3_Buffer)
+						templ_7745c5c3_Err = tooltip.Trigger(tooltip.TriggerProps{For: "jpg-tip", Class: "ml-2 inline-flex items-center text-slate-600 dark:text-slate-300"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var49), templ_7745c5c3_Buffer)
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 66, " ")
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 87, " ")
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						templ_7745c5c3_Var38 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+						templ_7745c5c3_Var50 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 							templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 							templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 							if !templ_7745c5c3_IsBuffer {
@@ -1043,29 +1423,29 @@ func QRCodeSection() templ.Component {
 								}()
 							}
 							ctx = templ.InitializeContext(ctx)
-							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 67, "<div class=\"text-xs leading-snug\"><div>JPG is a lossy format, and it doesn't support transparency.</div><div>Therefore, transparent backgrounds are flattened to white</div><div>For better results, consider using SVG or PNG</div></div>")
+							templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 88, "<div class=\"text-xs leading-snug\"><div>JPG is a lossy format, and it doesn't support transparency.</div><div>Therefore, transparent backgrounds are flattened to white</div><div>For better results, consider using SVG or PNG</div></div>")
 							if templ_7745c5c3_Err != nil {
 								return templ_7745c5c3_Err
 							}
 							return nil
 						})
-						templ_7745c5c3_Err = tooltip.Content(tooltip.ContentProps{ID: "jpg-tip", Position: tooltip.PositionTop, ShowArrow: false, Class: "text-xs"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var38), templ_7745c5c3_Buffer)
+						templ_7745c5c3_Err = tooltip.Content(tooltip.ContentProps{ID: "jpg-tip", Position: tooltip.PositionTop, ShowArrow: false, Class: "text-xs"}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var50), templ_7745c5c3_Buffer)
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
 						return nil
 					})
-					templ_7745c5c3_Err = tooltip.Tooltip().Render(templ.WithChildren(ctx, templ_7745c5c3_Var36), templ_7745c5c3_Buffer)
+					templ_7745c5c3_Err = tooltip.Tooltip().Render(templ.WithChildren(ctx, templ_7745c5c3_Var48), templ_7745c5c3_Buffer)
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = button.Button(button.Props{FullWidth: true, Class: "border", Attributes: templ.Attributes{"@click": "download('JPG')", "x-bind:disabled": "isDownloading", "x-bind:class": "isDownloading ? 'opacity-50 cursor-not-allowed' : ''"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var35), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = button.Button(button.Props{FullWidth: true, Class: "border", Attributes: templ.Attributes{"@click": "download('JPG')", "x-bind:disabled": "isDownloading", "x-bind:class": "isDownloading ? 'opacity-50 cursor-not-allowed' : ''"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var47), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var39 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var51 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -1077,7 +1457,7 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 68, "<template x-if=\"isDownloading && downloadingFormat === 'SVG'\"><svg class=\"animate-spin h-4 w-4 mr-2\" xmlns=\"http://www.w3.org/2000/svg\" fill=\"none\" viewBox=\"0 0 24 24\"><circle class=\"opacity-25\" cx=\"12\" cy=\"12\" r=\"10\" stroke=\"currentColor\" stroke-width=\"4\"></circle><path class=\"opacity-75\" fill=\"currentColor\" d=\"M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 714 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z\"></path></svg></template><template x-if=\"!isDownloading || downloadingFormat !== 'SVG'\">")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 89, "<template x-if=\"isDownloading && downloadingFormat === 'SVG'\"><svg class=\"animate-spin h-4 w-4 mr-2\" xmlns=\"http://www.w3.org/2000/svg\" fill=\"none\" viewBox=\"0 0 24 24\"><circle class=\"opacity-25\" cx=\"12\" cy=\"12\" r=\"10\" stroke=\"currentColor\" stroke-width=\"4\"></circle><path class=\"opacity-75\" fill=\"currentColor\" d=\"M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 714 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z\"></path></svg></template><template x-if=\"!isDownloading || downloadingFormat !== 'SVG'\">")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
@@ -1085,21 +1465,55 @@ func QRCodeSection() templ.Component {
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 69, "</template><span x-text=\"isDownloading && downloadingFormat === 'SVG' ? 'Generating SVG...' : 'Download SVG'\">Download SVG</span>")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 90, "</template><span x-text=\"isDownloading && downloadingFormat === 'SVG' ? 'Generating SVG...' : 'Download SVG'\">Download SVG</span>")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = button.Button(button.Props{FullWidth: true, Variant: button.VariantSecondary, Class: "border bg-teal-600 hover:bg-teal-700 text-white", Attributes: templ.Attributes{"@click": "isSVGAvailable ? download('SVG') : showSVGLimitation()", "x-bind:disabled": "isDownloading", "x-bind:class": "isDownloading ? 'opacity-50 cursor-not-allowed' : ''"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var39), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = button.Button(button.Props{FullWidth: true, Variant: button.VariantSecondary, Class: "border bg-teal-600 hover:bg-teal-700 text-white", Attributes: templ.Attributes{"@click": "isSVGAvailable ? download('SVG') : showSVGLimitation()", "x-bind:disabled": "isDownloading", "x-bind:class": "isDownloading ? 'opacity-50 cursor-not-allowed' : ''"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var51), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 70, "</div><div x-show=\"showSVGTooltip\" x-transition:enter=\"transition ease-out duration-200\" x-transition:enter-start=\"opacity-0 transform scale-95\" x-transition:enter-end=\"opacity-100 transform scale-100\" x-transition:leave=\"transition ease-in duration-150\" x-transition:leave-start=\"opacity-100 transform scale-100\" x-transition:leave-end=\"opacity-0 transform scale-95\" class=\"relative\"><div class=\"px-4 py-1 bg-gray-900 dark:bg-gray-700 text-white rounded-lg shadow-lg max-w-xs text-sm border border-gray-800 dark:border-gray-600\"><div class=\"font-medium mb-1\">SVG Not Available</div><div class=\"text-xs opacity-90\">SVG download is only available with flat colors and simple frame styles. Please change your settings to use SVG format.</div></div></div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 91, "<div x-show=\"settings.codeType === 'barcode'\" x-cloak>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var40 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var52 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+					if !templ_7745c5c3_IsBuffer {
+						defer func() {
+							templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+							if templ_7745c5c3_Err == nil {
+								templ_7745c5c3_Err = templ_7745c5c3_BufErr
+							}
+						}()
+					}
+					ctx = templ.InitializeContext(ctx)
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 92, "<template x-if=\"isDownloading && downloadingFormat === 'PDF'\"><svg class=\"animate-spin h-4 w-4 mr-2\" xmlns=\"http://www.w3.org/2000/svg\" fill=\"none\" viewBox=\"0 0 24 24\"><circle class=\"opacity-25\" cx=\"12\" cy=\"12\" r=\"10\" stroke=\"currentColor\" stroke-width=\"4\"></circle><path class=\"opacity-75\" fill=\"currentColor\" d=\"M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 714 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z\"></path></svg></template><template x-if=\"!isDownloading || downloadingFormat !== 'PDF'\">")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = icon.Icon("download")(icon.Props{Size: 16, Class: "mr-2"}).Render(ctx, templ_7745c5c3_Buffer)
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 93, "</template><span x-text=\"isDownloading && downloadingFormat === 'PDF' ? 'Generating PDF...' : 'Download PDF'\">Download PDF</span>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					return nil
+				})
+				templ_7745c5c3_Err = button.Button(button.Props{FullWidth: true, Class: "border", Attributes: templ.Attributes{"@click": "download('PDF')", "x-bind:disabled": "isDownloading", "x-bind:class": "isDownloading ? 'opacity-50 cursor-not-allowed' : ''"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var52), templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 94, "</div></div><div x-show=\"showSVGTooltip\" x-transition:enter=\"transition ease-out duration-200\" x-transition:enter-start=\"opacity-0 transform scale-95\" x-transition:enter-end=\"opacity-100 transform scale-100\" x-transition:leave=\"transition ease-in duration-150\" x-transition:leave-start=\"opacity-100 transform scale-100\" x-transition:leave-end=\"opacity-0 transform scale-95\" class=\"relative\"><div class=\"px-4 py-1 bg-gray-900 dark:bg-gray-700 text-white rounded-lg shadow-lg max-w-xs text-sm border border-gray-800 dark:border-gray-600\"><div class=\"font-medium mb-1\">SVG Not Available</div><div class=\"text-xs opacity-90\">SVG download is only available with flat colors and simple frame styles. Please change your settings to use SVG format.</div></div></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Var53 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -1115,13 +1529,13 @@ func QRCodeSection() templ.Component {
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 71, " Copy to Clipboard ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 95, " Copy to Clipboard ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = button.Button(button.Props{Variant: button.VariantOutline, Size: button.SizeSm, Class: "w-full transition-all", Attributes: templ.Attributes{"@click": "copyQR()", "x-bind:disabled": "isDownloading", "x-bind:class": "isDownloading ? 'opacity-50 cursor-not-allowed' : ''"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var40), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = button.Button(button.Props{Variant: button.VariantOutline, Size: button.SizeSm, Class: "w-full transition-all", Attributes: templ.Attributes{"@click": "copyQR()", "x-bind:disabled": "isDownloading", "x-bind:class": "isDownloading ? 'opacity-50 cursor-not-allowed' : ''"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var53), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -1129,11 +1543,11 @@ func QRCodeSection() templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 72, "<div class=\"space-y-4 pt-2\"><div><div class=\"flex items-center justify-between mb-2\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 96, "<div class=\"space-y-4 pt-2\"><div><div class=\"flex items-center justify-between mb-2\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var41 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var54 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -1145,17 +1559,17 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 73, "HTML Embed ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 97, "HTML Embed ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var41), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var54), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var42 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var55 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -1173,15 +1587,15 @@ func QRCodeSection() templ.Component {
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = button.Button(button.Props{Variant: button.VariantGhost, Size: button.SizeIcon, Attributes: templ.Attributes{"@click": "copyEmbed()"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var42), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = button.Button(button.Props{Variant: button.VariantGhost, Size: button.SizeIcon, Attributes: templ.Attributes{"@click": "copyEmbed()"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var55), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 74, "</div><textarea readonly rows=\"2\" class=\"w-full h-[120px] px-3 py-2 text-xs font-mono bg-gray-50 dark:bg-gray-800 border border-gray-300 dark:border-gray-600 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500 resize-none\" x-model=\"embedCode\"></textarea></div><div><div class=\"flex items-center justify-between mb-2\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 98, "</div><textarea readonly rows=\"2\" class=\"w-full h-[120px] px-3 py-2 text-xs font-mono bg-gray-50 dark:bg-gray-800 border border-gray-300 dark:border-gray-600 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500 resize-none\" x-model=\"embedCode\"></textarea></div><div><div class=\"flex items-center justify-between mb-2\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var43 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var56 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -1193,17 +1607,17 @@ func QRCodeSection() templ.Component {
 						}()
 					}
 					ctx = templ.InitializeContext(ctx)
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 75, "Direct Image URL ")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 99, "Direct Image URL ")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var43), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = label.Label().Render(templ.WithChildren(ctx, templ_7745c5c3_Var56), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Var44 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_Var57 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 					templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 					templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 					if !templ_7745c5c3_IsBuffer {
@@ -1221,27 +1635,27 @@ func QRCodeSection() templ.Component {
 					}
 					return nil
 				})
-				templ_7745c5c3_Err = button.Button(button.Props{Variant: button.VariantGhost, Size: button.SizeIcon, Attributes: templ.Attributes{"@click": "copyDirectUrl()"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var44), templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = button.Button(button.Props{Variant: button.VariantGhost, Size: button.SizeIcon, Attributes: templ.Attributes{"@click": "copyDirectUrl()"}}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var57), templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 76, "</div><textarea readonly rows=\"2\" class=\"w-full h-[120px] px-3 py-2 text-xs font-mono bg-gray-50 dark:bg-gray-800 border border-gray-300 dark:border-gray-600 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500 resize-none\" x-model=\"directImageUrl\"></textarea></div></div></div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 100, "</div><textarea readonly rows=\"2\" class=\"w-full h-[120px] px-3 py-2 text-xs font-mono bg-gray-50 dark:bg-gray-800 border border-gray-300 dark:border-gray-600 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500 resize-none\" x-model=\"directImageUrl\"></textarea></div></div></div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 				return nil
 			})
-			templ_7745c5c3_Err = card.Content().Render(templ.WithChildren(ctx, templ_7745c5c3_Var33), templ_7745c5c3_Buffer)
+			templ_7745c5c3_Err = card.Content().Render(templ.WithChildren(ctx, templ_7745c5c3_Var45), templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			return nil
 		})
-		templ_7745c5c3_Err = card.Card().Render(templ.WithChildren(ctx, templ_7745c5c3_Var30), templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = card.Card().Render(templ.WithChildren(ctx, templ_7745c5c3_Var42), templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 77, "</div></div></div><!-- Removed slider script since preview size is fixed --><script>\n            function qrCodeTabManager() {\n                return {\n                    url: '',\n                    previewSize: 528,\n                    previewImageUrl: '',\n                    settings: {\n                        colorMode: 'flat',\n                        foregroundColor: '#000000',\n                        backgroundColor: '#ffffff',\n                        transparentBackground: false,\n                        gradientStart: '#000000',\n                        gradientMiddle: '#808080',\n                        gradientEnd: '#ff0000',\n                        cornerStyle: 'none',\n                        borderPattern: 'simple',\n                        borderColor: '#000000',\n                        sameColorBorder: true,\n                        qrShape: 'rectangle',\n                        removeBranding: false,\n                        enableLogo: false,\n                        logoFile: null\n                    },\n                    embedCode: '',\n                    directImageUrl: '',\n                    updateTimeout: null,\n                    initialized: false,\n                    isDownloading: false,\n                    downloadingFormat: '',\n                    showSVGTooltip: false,\n                    get isSVGAvailable() {\n                        return this.settings.colorMode === 'flat' && (this.settings.cornerStyle === 'none' || this.settings.borderPattern === 'simple');\n                    },\n                    showSVGLimitation() {\n                        this.showSVGTooltip = true;\n                        setTimeout(() => { this.showSVGTooltip = false; }, 3000);\n                    },\n                    setUrl(linkUrl) { this.url = linkUrl; },\n                    initializeQR() {\n                        if (!this.initialized && this.url) {\n                            this.initialized = true;\n                            this.updateQRCode();\n                            this.updateEmbedCode();\n                        }\n                    },\n                    handleTabChange(tabValue) { if (tabValue === 'qr') { this.initializeQR(); } },\n                    updateQRCode() {\n                        if (!this.initialized) return;\n                        if (this.updateTimeout) { clearTimeout(this.updateTimeout); }\n                        this.updateTimeout = setTimeout(() => {\n                            this.updateEmbedCode();\n                            this.updateDirectUrl();\n                            \n                            this.loadQRPreview();\n                        }, 150);\n                    },\n                    updateEmbedCode() {\n                        const params = this.buildQRParams('download');\n                        this.embedCode = `<img src=\"${window.location.origin}/api/qr?${params}\" alt=\"QR Code\" style=\"max-width: 100%; height: auto;\" />`;\n                    },\n                    updateDirectUrl() {\n                        const params = this.buildQRParams('download');\n                        this.directImageUrl = `${window.location.origin}/api/qr?${params}`;\n                        \n                    },\n                    buildQRParams(size = 'preview') {\n                        const params = new URLSearchParams({\n                            url: this.url,\n                            colorMode: this.settings.colorMode,\n                            cornerStyle: this.settings.cornerStyle,\n                            borderPattern: this.settings.borderPattern,\n                            qrShape: this.settings.qrShape,\n                            size: size\n                        });\n                        if (this.settings.removeBranding) { params.set('branding', 'none'); } else { params.set('branding', 'default'); }\n                        if (this.settings.enableLogo && this.settings.logoFile) {\n                            params.set('centerLogo', 'true');\n                            if (typeof this.settings.logoFile === 'string') { params.set('logoFile', this.settings.logoFile); }\n                        }\n                        if (this.settings.transparentBackground) { params.set('bg', 'transparent'); } else { params.set('bg', this.settings.backgroundColor.replace('#', '')); }\n                        if (this.settings.colorMode === 'flat') {\n                            params.set('fg', this.settings.foregroundColor.replace('#', ''));\n                        } else {\n                            params.set('gradientStart', this.settings.gradientStart.replace('#', ''));\n                            params.set('gradientMiddle', this.settings.gradientMiddle.replace('#', ''));\n                            params.set('gradientEnd', this.settings.gradientEnd.replace('#', ''));\n                        }\n                        if (this.settings.cornerStyle !== 'none') {\n                            if (!this.settings.sameColorBorder) { params.set('borderColor', this.settings.borderColor.replace('#', '')); }\n                            params.set('borderPattern', this.settings.borderPattern);\n                        }\n                        params.set('previewSize', this.previewSize.toString());\n                        return params.toString();\n                    },\n                    async loadQRPreview() {\n                        try {\n                            const params = this.buildQRParams('preview');\n                            const url = `/api/qr?${params}`;\n                            this.previewImageUrl = url;\n                            \n                        } catch (e) { }\n                    },\n                    async download(format) {\n                        try {\n                            this.isDownloading = true; this.downloadingFormat = format;\n                            const params = this.buildQRParams('download');\n                            const fmt = (format || 'PNG').toLowerCase();\n                            const response = await fetch(`/api/qr?${params}&format=${fmt}`);\n                            if (!response.ok) throw new Error(`HTTP error! status: ${response.status}`);\n                            const blob = await response.blob();\n                            const url = window.URL.createObjectURL(blob);\n                            const a = document.createElement('a'); a.href = url; a.download = `qr.${format.toLowerCase()}`; a.click(); window.URL.revokeObjectURL(url);\n                        } catch (e) { }\n                        finally { this.isDownloading = false; this.downloadingFormat = ''; window.dispatchEvent(new CustomEvent('qr-download-end')); }\n                    },\n                    async copyQR() {\n                        try {\n                            this.isDownloading = true; this.downloadingFormat = 'PNG';\n                            const params = this.buildQRParams('download');\n                            const response = await fetch(`/api/qr?${params}`);\n                            if (!response.ok) throw new Error(`HTTP error! status: ${response.status}`);\n                            const blob = await response.blob();\n                            await navigator.clipboard.write([ new ClipboardItem({ [blob.type]: blob }) ]);\n                            this.showToast('Success', 'QR code copied to clipboard!', 'success');\n                        } catch (e) { this.showToast('Error', 'Failed to copy QR code', 'error'); }\n                        finally { this.isDownloading = false; this.downloadingFormat = ''; window.dispatchEvent(new CustomEvent('qr-download-end')); }\n                    },\n                    copyEmbed() { navigator.clipboard.writeText(this.embedCode); this.showToast('Success', 'Embed code copied to clipboard!', 'success'); },\n                    copyDirectUrl() { navigator.clipboard.writeText(this.directImageUrl); this.showToast('Success', 'Direct URL copied to clipboard!', 'success'); },\n                    shareQR() { if (typeof openQRShareModal === 'function') { openQRShareModal(this.directImageUrl, 'Check out this QR code'); } },\n                    showToast(title, description, variant) {\n                        const form = document.createElement('form'); form.style.display = 'none';\n                        const ti = document.createElement('input'); ti.name = 'title'; ti.value = title; form.appendChild(ti);\n                        const di = document.createElement('input'); di.name = 'description'; di.value = description; form.appendChild(di);\n                        const vi = document.createElement('input'); vi.name = 'variant'; vi.value = variant; form.appendChild(vi);\n                        const ds = document.createElement('input'); ds.name = 'dismissible'; ds.value = 'on'; form.appendChild(ds);\n                        document.body.appendChild(form);\n                        if (window.htmx) { htmx.ajax('POST', '/api/htmx/toast', { source: form, target: '#toast-container', swap: 'afterbegin' }); }\n                        document.body.removeChild(form);\n                    },\n                }\n            }\n            // Minimal stub to avoid errors if not defined elsewhere\n            window.openQRShareModal = window.openQRShareModal || function(url, text){ try { navigator.share && navigator.share({ url, text }); } catch(e){} };\n        </script></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 101, "</div></div></div><!-- Removed slider script since preview size is fixed --><script>\n            function qrCodeTabManager() {\n                return {\n                    url: '',\n                    previewSize: 528,\n                    previewImageUrl: '',\n                    settings: {\n                        codeType: 'qr',\n                        symbology: 'code128',\n                        barcodeData: '',\n                        barWidthReduction: 0,\n                        utmSource: '',\n                        utmMedium: '',\n                        utmCampaign: '',\n                        humanReadableText: true,\n                        colorMode: 'flat',\n                        foregroundColor: '#000000',\n                        backgroundColor: '#ffffff',\n                        transparentBackground: false,\n                        gradientStart: '#000000',\n                        gradientMiddle: '#808080',\n                        gradientEnd: '#ff0000',\n                        cornerStyle: 'none',\n                        borderPattern: 'simple',\n                        borderColor: '#000000',\n                        sameColorBorder: true,\n                        qrShape: 'rectangle',\n                        removeBranding: false,\n                        enableLogo: false,\n                        logoFile: null\n                    },\n                    presets: [],\n                    presetId: '',\n                    embedCode: '',\n                    shareSeq: 0,\n                    directImageUrl: '',\n                    updateTimeout: null,\n                    initialized: false,\n                    isDownloading: false,\n                    downloadingFormat: '',\n                    showSVGTooltip: false,\n                    get apiPath() {\n                        return this.settings.codeType === 'barcode' ? '/api/barcode' : '/api/qr';\n                    },\n                    get barcodeHint() {\n                        switch (this.settings.symbology) {\n                            case 'gs1-128': return 'Write (AI)value pairs, e.g. (01)09501101530003(10)AB12';\n                            case 'ean13': return '12 digits; the check digit is added for you';\n                            case 'upca': return '11 digits; the check digit is added for you';\n                            case 'itf14': return '13 digits; the check digit is added for you';\n                            case 'code39': return 'Digits, uppercase letters and - . $ / + % and space';\n                            default: return 'Any ASCII text';\n                        }\n                    },\n                    get isSVGAvailable() {\n                        if (this.settings.codeType === 'barcode') return true;\n                        return this.settings.colorMode === 'flat' && (this.settings.cornerStyle === 'none' || this.settings.borderPattern === 'simple');\n                    },\n                    showSVGLimitation() {\n                        this.showSVGTooltip = true;\n                        setTimeout(() => { this.showSVGTooltip = false; }, 3000);\n                    },\n                    setUrl(linkUrl) { this.url = linkUrl; },\n                    init() { this.loadPresets(); },\n                    async loadPresets() {\n                        try {\n                            const response = await fetch('/api/v1/presets');\n                            if (response.ok) { this.presets = await response.json(); }\n                        } catch (e) { }\n                    },\n                    // presetKey returns the owner key of a preset created in this\n                    // browser, which is needed to overwrite or delete it.\n                    presetKey(id) { return id ? localStorage.getItem(`preset-key:${id}`) : null; },\n                    presetDesign() {\n                        const s = this.settings;\n                        const design = {\n                            colors: { mode: s.colorMode, background: s.transparentBackground ? 'transparent' : s.backgroundColor },\n                            shape: s.qrShape,\n                            frame: { style: s.cornerStyle, pattern: s.borderPattern },\n                        };\n                        if (s.colorMode === 'gradient') {\n                            design.colors.gradient = { start: s.gradientStart, middle: s.gradientMiddle, end: s.gradientEnd };\n                        } else {\n                            design.colors.foreground = s.foregroundColor;\n                        }\n                        if (!s.sameColorBorder) { design.frame.color = s.borderColor; }\n                        if (s.enableLogo && typeof s.logoFile === 'string') { design.logo = { file: s.logoFile }; }\n                        return design;\n                    },\n                    applyPreset() {\n                        const preset = this.presets.find(p => p.id === this.presetId);\n                        if (!preset) return;\n                        const d = preset.design, s = this.settings;\n                        s.colorMode = d.colors.mode;\n                        s.foregroundColor = d.colors.foreground;\n                        s.transparentBackground = d.colors.background === 'transparent';\n                        if (!s.transparentBackground) { s.backgroundColor = d.colors.background; }\n                        if (d.colors.gradient) {\n                            s.gradientStart = d.colors.gradient.start;\n                            s.gradientMiddle = d.colors.gradient.middle;\n                            s.gradientEnd = d.colors.gradient.end;\n                        }\n                        s.qrShape = d.shape;\n                        s.cornerStyle = d.frame.style;\n                        s.borderPattern = d.frame.pattern;\n                        s.sameColorBorder = !d.frame.color;\n                        if (d.frame.color) { s.borderColor = d.frame.color; }\n                        s.enableLogo = !!d.logo;\n                        s.logoFile = d.logo ? d.logo.file : null;\n                        this.syncTabs([s.colorMode, s.cornerStyle]);\n                        this.updateQRCode();\n                    },\n                    syncTabs(values) {\n                        values.forEach(value => {\n                            const trigger = this.$root.querySelector(`[data-tui-tabs-trigger][data-tui-tabs-value=\"${value}\"]`);\n                            if (!trigger) return;\n                            this.$root.querySelectorAll(`[data-tui-tabs-trigger][data-tui-tabs-id=\"${trigger.dataset.tuiTabsId}\"]`).forEach(t => {\n                                t.dataset.tuiTabsState = t === trigger ? 'active' : 'inactive';\n                            });\n                        });\n                    },\n                    async savePreset() {\n                        const current = this.presets.find(p => p.id === this.presetId);\n                        const name = window.prompt('Preset name', current ? current.name : '');\n                        if (!name) return;\n                        const key = current && this.presetKey(current.id);\n                        const overwrite = key && current.name === name;\n                        const headers = { 'Content-Type': 'application/json' };\n                        if (overwrite) { headers['Authorization'] = `Bearer ${key}`; }\n                        try {\n                            const response = await fetch(overwrite ? `/api/v1/presets/${current.id}` : '/api/v1/presets', {\n                                method: overwrite ? 'PUT' : 'POST',\n                                headers: headers,\n                                body: JSON.stringify({ name: name, kind: overwrite ? current.kind : 'preset', design: this.presetDesign() }),\n                            });\n                            if (!response.ok) throw new Error(`HTTP error! status: ${response.status}`);\n                            const saved = await response.json();\n                            if (saved.key) { localStorage.setItem(`preset-key:${saved.id}`, saved.key); }\n                            await this.loadPresets();\n                            this.presetId = saved.id;\n                            this.showToast('Success', `Preset \"${saved.name}\" saved`, 'success');\n                        } catch (e) { this.showToast('Error', 'Failed to save preset', 'error'); }\n                    },\n                    async deletePreset() {\n                        const current = this.presets.find(p => p.id === this.presetId);\n                        const key = current && this.presetKey(current.id);\n                        if (!key || !window.confirm(`Delete preset \"${current.name}\"?`)) return;\n                        try {\n                            const response = await fetch(`/api/v1/presets/${current.id}`, { method: 'DELETE', headers: { 'Authorization': `Bearer ${key}` } });\n                            if (!response.ok) throw new Error(`HTTP error! status: ${response.status}`);\n                            localStorage.removeItem(`preset-key:${current.id}`);\n                            this.presetId = '';\n                            await this.loadPresets();\n                        } catch (e) { this.showToast('Error', 'Failed to delete preset', 'error'); }\n                    },\n                    initializeQR() {\n                        if (!this.initialized && this.url) {\n                            this.initialized = true;\n                            this.updateQRCode();\n                            this.updateShareLinks();\n                        }\n                    },\n                    handleTabChange(tabValue) { if (tabValue === 'qr') { this.initializeQR(); } },\n                    updateQRCode() {\n                        if (!this.initialized) return;\n                        if (this.updateTimeout) { clearTimeout(this.updateTimeout); }\n                        this.updateTimeout = setTimeout(() => {\n                            this.updateShareLinks();\n                            this.loadQRPreview();\n                        }, 150);\n                    },\n                    async updateShareLinks() {\n                        const seq = ++this.shareSeq;\n                        const src = await this.shareUrl();\n                        if (seq !== this.shareSeq) return;\n                        this.directImageUrl = src;\n                        this.embedCode = `<img src=\"${src}\" alt=\"${this.settings.codeType === 'barcode' ? 'Barcode' : 'QR Code'}\" style=\"max-width: 100%; height: auto;\" />`;\n                    },\n                    // shareUrl returns a short signed link to the current QR code,\n                    // or the long GET /api/qr link for barcodes and on errors.\n                    async shareUrl() {\n                        const longUrl = `${window.location.origin}${this.apiPath}?${this.buildQRParams('download')}`;\n                        if (this.settings.codeType === 'barcode' || !this.url) return longUrl;\n                        try {\n                            const response = await fetch('/api/v1/tokens', {\n                                method: 'POST',\n                                headers: { 'Content-Type': 'application/json' },\n                                body: JSON.stringify({ ...this.presetDesign(), content: { type: 'url', url: this.url, campaign: this.campaign() }, output: { size: 'download' } }),\n                            });\n                            if (!response.ok) throw new Error(`HTTP error! status: ${response.status}`);\n                            const data = await response.json();\n                            return `${window.location.origin}${data.url}`;\n                        } catch (e) { return longUrl; }\n                    },\n                    // campaign returns the UTM fields as a v1 campaign, or\n                    // undefined when none is set.\n                    campaign() {\n                        const c = { source: this.settings.utmSource.trim(), medium: this.settings.utmMedium.trim(), campaign: this.settings.utmCampaign.trim() };\n                        return c.source || c.medium || c.campaign ? c : undefined;\n                    },\n                    buildQRParams(size = 'preview') {\n                        if (this.settings.codeType === 'barcode') { return this.buildBarcodeParams(size); }\n                        const params = new URLSearchParams({\n                            url: this.url,\n                            colorMode: this.settings.colorMode,\n                            cornerStyle: this.settings.cornerStyle,\n                            borderPattern: this.settings.borderPattern,\n                            qrShape: this.settings.qrShape,\n                            size: size\n                        });\n                        if (this.settings.removeBranding) { params.set('branding', 'none'); } else { params.set('branding', 'default'); }\n                        if (this.settings.enableLogo && this.settings.logoFile) {\n                            params.set('centerLogo', 'true');\n                            if (typeof this.settings.logoFile === 'string') { params.set('logoFile', this.settings.logoFile); }\n                        }\n                        if (this.settings.transparentBackground) { params.set('bg', 'transparent'); } else { params.set('bg', this.settings.backgroundColor.replace('#', '')); }\n                        if (this.settings.colorMode === 'flat') {\n                            params.set('fg', this.settings.foregroundColor.replace('#', ''));\n                        } else {\n                            params.set('gradientStart', this.settings.gradientStart.replace('#', ''));\n                            params.set('gradientMiddle', this.settings.gradientMiddle.replace('#', ''));\n                            params.set('gradientEnd', this.settings.gradientEnd.replace('#', ''));\n                        }\n                        if (this.settings.cornerStyle !== 'none') {\n                            if (!this.settings.sameColorBorder) { params.set('borderColor', this.settings.borderColor.replace('#', '')); }\n                            params.set('borderPattern', this.settings.borderPattern);\n                        }\n                        const c = this.campaign();\n                        if (c) {\n                            if (c.source) { params.set('utm_source', c.source); }\n                            if (c.medium) { params.set('utm_medium', c.medium); }\n                            if (c.campaign) { params.set('utm_campaign', c.campaign); }\n                        }\n                        params.set('previewSize', this.previewSize.toString());\n                        return params.toString();\n                    },\n                    buildBarcodeParams(size = 'preview') {\n                        const params = new URLSearchParams({ symbology: this.settings.symbology, size: size });\n                        if (this.settings.barcodeData) { params.set('data', this.settings.barcodeData); } else { params.set('url', this.url); }\n                        if (!this.settings.humanReadableText) { params.set('hrt', 'false'); }\n                        if (this.settings.barWidthReduction > 0) { params.set('bwr', this.settings.barWidthReduction.toString()); }\n                        params.set('fg', this.settings.foregroundColor.replace('#', ''));\n                        if (this.settings.transparentBackground) { params.set('bg', 'transparent'); } else { params.set('bg', this.settings.backgroundColor.replace('#', '')); }\n                        params.set('previewSize', this.previewSize.toString());\n                        return params.toString();\n                    },\n                    async loadQRPreview() {\n                        try {\n                            const params = this.buildQRParams('preview');\n                            const url = `${this.apiPath}?${params}`;\n                            this.previewImageUrl = url;\n                            \n                        } catch (e) { }\n                    },\n                    async download(format) {\n                        try {\n                            this.isDownloading = true; this.downloadingFormat = format;\n                            const params = this.buildQRParams('download');\n                            const fmt = (format || 'PNG').toLowerCase();\n                            const response = await fetch(`${this.apiPath}?${params}&format=${fmt}`);\n                            if (!response.ok) throw new Error(`HTTP error! status: ${response.status}`);\n                            const blob = await response.blob();\n                            const url = window.URL.createObjectURL(blob);\n                            const a = document.createElement('a'); a.href = url; a.download = `${this.settings.codeType === 'barcode' ? 'barcode' : 'qr'}.${format.toLowerCase()}`; a.click(); window.URL.revokeObjectURL(url);\n                        } catch (e) { }\n                        finally { this.isDownloading = false; this.downloadingFormat = ''; window.dispatchEvent(new CustomEvent('qr-download-end')); }\n                    },\n                    async copyQR() {\n                        try {\n                            this.isDownloading = true; this.downloadingFormat = 'PNG';\n                            const params = this.buildQRParams('download');\n                            const response = await fetch(`${this.apiPath}?${params}`);\n                            if (!response.ok) throw new Error(`HTTP error! status: ${response.status}`);\n                            const blob = await response.blob();\n                            await navigator.clipboard.write([ new ClipboardItem({ [blob.type]: blob }) ]);\n                            this.showToast('Success', 'QR code copied to clipboard!', 'success');\n                        } catch (e) { this.showToast('Error', 'Failed to copy QR code', 'error'); }\n                        finally { this.isDownloading = false; this.downloadingFormat = ''; window.dispatchEvent(new CustomEvent('qr-download-end')); }\n                    },\n                    copyEmbed() { navigator.clipboard.writeText(this.embedCode); this.showToast('Success', 'Embed code copied to clipboard!', 'success'); },\n                    copyDirectUrl() { navigator.clipboard.writeText(this.directImageUrl); this.showToast('Success', 'Direct URL copied to clipboard!', 'success'); },\n                    shareQR() { if (typeof openQRShareModal === 'function') { openQRShareModal(this.directImageUrl, 'Check out this QR code'); } },\n                    showToast(title, description, variant) {\n                        const form = document.createElement('form'); form.style.display = 'none';\n                        const ti = document.createElement('input'); ti.name = 'title'; ti.value = title; form.appendChild(ti);\n                        const di = document.createElement('input'); di.name = 'description'; di.value = description; form.appendChild(di);\n                        const vi = document.createElement('input'); vi.name = 'variant'; vi.value = variant; form.appendChild(vi);\n                        const ds = document.createElement('input'); ds.name = 'dismissible'; ds.value = 'on'; form.appendChild(ds);\n                        document.body.appendChild(form);\n                        if (window.htmx) { htmx.ajax('POST', '/api/htmx/toast', { source: form, target: '#toast-container', swap: 'afterbegin' }); }\n                        document.body.removeChild(form);\n                    },\n                }\n            }\n            // Minimal stub to avoid errors if not defined elsewhere\n            window.openQRShareModal = window.openQRShareModal || function(url, text){ try { navigator.share && navigator.share({ url, text }); } catch(e){} };\n        </script></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -0,0 +1,172 @@
+// Code generated by templ - DO NOT EDIT.
+
+// templ: version: v0.3.943
+package pages
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+import (
+	"github.com/cristianadrielbraun/qrcreator.link/web/components/ui/button"
+	"github.com/cristianadrielbraun/qrcreator.link/web/components/ui/input"
+	"github.com/cristianadrielbraun/qrcreator.link/web/layouts"
+)
+
+func linkDashboardContent(slug string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<section class=\"mx-auto max-w-3xl py-8\" x-data=\"linkDashboard()\" data-slug=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var2 string
+		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(slug)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/link_dashboard.templ`, Line: 10, Col: 85}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\" x-init=\"init()\"><h1 class=\"text-2xl md:text-3xl font-semibold tracking-tight\">Scans of /r/")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var3 string
+		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(slug)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/link_dashboard.templ`, Line: 11, Col: 92}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</h1><p class=\"mt-2 text-slate-600 dark:text-slate-400\">Only daily totals, device classes and countries are counted. No addresses or visitor identifiers are kept.</p><form class=\"mt-6 flex items-center gap-2\" x-show=\"!stats\" @submit.prevent=\"load()\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = input.Input(input.Props{
+			Type:        input.TypePassword,
+			Placeholder: "Owner key",
+			Attributes: templ.Attributes{
+				"x-model":      "key",
+				"autocomplete": "off",
+			},
+		}).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Var4 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+			if !templ_7745c5c3_IsBuffer {
+				defer func() {
+					templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+					if templ_7745c5c3_Err == nil {
+						templ_7745c5c3_Err = templ_7745c5c3_BufErr
+					}
+				}()
+			}
+			ctx = templ.InitializeContext(ctx)
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "Show scans")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			return nil
+		})
+		templ_7745c5c3_Err = button.Button(button.Props{
+			Type: button.TypeSubmit,
+		}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var4), templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</form><p class=\"mt-2 text-sm\" style=\"color:#dc2626\" x-show=\"error\" x-text=\"error\"></p><template x-if=\"stats\"><div><div class=\"mt-6 flex items-center justify-between gap-3\"><p class=\"text-lg\"><span class=\"font-semibold\" x-text=\"stats.total\"></span> scans since <span x-text=\"stats.since\"></span></p><div class=\"flex items-center gap-2\"><select class=\"h-9 rounded-md border px-3 text-sm bg-transparent\" x-model.number=\"days\" @change=\"load()\"><option value=\"7\">7 days</option> <option value=\"30\">30 days</option> <option value=\"90\">90 days</option> <option value=\"366\">1 year</option></select>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Var5 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+			if !templ_7745c5c3_IsBuffer {
+				defer func() {
+					templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+					if templ_7745c5c3_Err == nil {
+						templ_7745c5c3_Err = templ_7745c5c3_BufErr
+					}
+				}()
+			}
+			ctx = templ.InitializeContext(ctx)
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "Export CSV")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			return nil
+		})
+		templ_7745c5c3_Err = button.Button(button.Props{
+			Variant: button.VariantOutline,
+			Attributes: templ.Attributes{
+				"@click": "exportCSV()",
+			},
+		}).Render(templ.WithChildren(ctx, templ_7745c5c3_Var5), templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "</div></div><h2 class=\"mt-8 text-lg font-semibold\">Per day</h2><p class=\"mt-2 text-sm text-slate-600 dark:text-slate-400\" x-show=\"stats.days.length === 0\">No scans in this period.</p><div class=\"mt-2\"><template x-for=\"d in stats.days\" :key=\"d.day\"><div class=\"flex items-center gap-3 py-1 text-sm\"><span class=\"font-mono\" x-text=\"d.day\"></span><div class=\"h-2 rounded-full\" style=\"background:#2563eb\" :style=\"`width: ${bar(d.total, maxDay)}%`\"></div><span x-text=\"d.total\"></span></div></template></div><div class=\"mt-8 grid gap-4\" style=\"grid-template-columns: repeat(2, minmax(0, 1fr))\"><div><h2 class=\"text-lg font-semibold\">Devices</h2><template x-for=\"[name, n] in sorted(stats.devices)\" :key=\"name\"><div class=\"flex items-center justify-between border-b py-1 text-sm\"><span class=\"capitalize\" x-text=\"name\"></span><span x-text=\"n\"></span></div></template></div><div><h2 class=\"text-lg font-semibold\">Countries</h2><template x-for=\"[name, n] in sorted(stats.countries)\" :key=\"name\"><div class=\"flex items-center justify-between border-b py-1 text-sm\"><span x-text=\"name\"></span><span x-text=\"n\"></span></div></template></div></div></div></template></section><script>\n        function linkDashboard() {\n            return {\n                slug: '',\n                key: '',\n                days: 30,\n                stats: null,\n                error: '',\n                get maxDay() { return Math.max(1, ...this.stats.days.map(d => d.total)); },\n                init() {\n                    this.slug = this.$el.dataset.slug;\n                    // The key can be passed in the fragment, which is never sent to the server\n                    const fromHash = new URLSearchParams(location.hash.slice(1)).get('key');\n                    if (fromHash) { history.replaceState(null, '', location.pathname); }\n                    this.key = fromHash || sessionStorage.getItem(`link-key:${this.slug}`) || '';\n                    if (this.key) { this.load(); }\n                },\n                async fetchScans(format) {\n                    const response = await fetch(`/api/v1/links/${encodeURIComponent(this.slug)}/scans?days=${this.days}&format=${format}`, {\n                        headers: { 'Authorization': `Bearer ${this.key}` },\n                    });\n                    if (!response.ok) {\n                        const body = await response.json().catch(() => ({}));\n                        throw new Error(body.error || `HTTP error! status: ${response.status}`);\n                    }\n                    return response;\n                },\n                async load() {\n                    try {\n                        this.stats = await (await this.fetchScans('json')).json();\n                        this.error = '';\n                        sessionStorage.setItem(`link-key:${this.slug}`, this.key);\n                    } catch (e) {\n                        this.stats = null;\n                        this.error = e.message;\n                    }\n                },\n                async exportCSV() {\n                    try {\n                        const blob = await (await this.fetchScans('csv')).blob();\n                        const a = document.createElement('a');\n                        a.href = URL.createObjectURL(blob);\n                        a.download = `scans-${this.slug}.csv`;\n                        a.click();\n                        URL.revokeObjectURL(a.href);\n                    } catch (e) { this.error = e.message; }\n                },\n                sorted(counts) { return Object.entries(counts).sort((a, b) => b[1] - a[1]); },\n                bar(n, max) { return Math.max(2, Math.round(n / max * 70)); },\n            };\n        }\n    </script>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// LinkDashboardPage shows the scan counts of a dynamic code to its owner.
+func LinkDashboardPage(slug string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var6 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var6 == nil {
+			templ_7745c5c3_Var6 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = layouts.Layout("Scans – qrcreator.link", linkDashboardContent(slug)).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate
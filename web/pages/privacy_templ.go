@@ -33,7 +33,7 @@ func privacyContent() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<section class=\"mx-auto max-w-3xl py-8\"><h1 class=\"text-2xl md:text-3xl font-semibold tracking-tight\">Privacy</h1><p class=\"mt-4 text-slate-700 dark:text-slate-300\">Last updated: 13-09-25</p><p class=\"mt-6\">I built qrcreator.link to be fast, simple, open‑source, and privacy‑friendly. You can always review the full source code on GitHub.</p><h2 class=\"mt-8 text-lg font-semibold\">Data collection</h2><p class=\"mt-2\">I do not use tracking cookies and I do not require accounts. The URL you enter is processed only to generate a QR code and is not stored by me.</p><h2 class=\"mt-6 text-lg font-semibold\">Logs</h2><p class=\"mt-2\">I only keep real‑time runtime logs in memory for operational purposes. I do not persist access or application logs to disk or external storage.</p><h2 class=\"mt-6 text-lg font-semibold\">Generated QR codes</h2><p class=\"mt-2\">QR codes are generated on demand and streamed back to your browser. I do not persist generated images.</p><h2 class=\"mt-6 text-lg font-semibold\">Third‑party services</h2><p class=\"mt-2\">The site is served through my infrastructure with a reverse proxy for TLS/edge routing. No analytics or advertising trackers are included.</p><h2 class=\"mt-6 text-lg font-semibold\">Contact</h2><p class=\"mt-2\">Questions or feedback? Reach me on <a class=\"underline\" href=\"https://github.com/cristianadrielbraun\" target=\"_blank\" rel=\"noopener\">GitHub</a>, <a class=\"underline\" href=\"https://twitter.com/MonitoBraun\" target=\"_blank\" rel=\"noopener\">Twitter</a>, or <a class=\"underline\" href=\"https://bsky.app/profile/monitobraun.bsky.social\" target=\"_blank\" rel=\"noopener\">Bluesky</a>.</p></section>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<section class=\"mx-auto max-w-3xl py-8\"><h1 class=\"text-2xl md:text-3xl font-semibold tracking-tight\">Privacy</h1><p class=\"mt-4 text-slate-700 dark:text-slate-300\">Last updated: 13-09-25</p><p class=\"mt-6\">I built qrcreator.link to be fast, simple, open‑source, and privacy‑friendly. You can always review the full source code on GitHub.</p><h2 class=\"mt-8 text-lg font-semibold\">Data collection</h2><p class=\"mt-2\">I do not use tracking cookies and I do not require accounts. The URL you enter is processed only to generate a QR code and is not stored by me.</p><h2 class=\"mt-6 text-lg font-semibold\">Logs</h2><p class=\"mt-2\">I only keep real‑time runtime logs in memory for operational purposes. I do not persist access or application logs to disk or external storage.</p><p class=\"mt-2\">Logs record which endpoint was called, the response status and how long it took. The URLs and text you encode, query strings and IP addresses are redacted before anything is logged.</p><h2 class=\"mt-6 text-lg font-semibold\">Generated QR codes</h2><p class=\"mt-2\">QR codes are generated on demand and streamed back to your browser. I do not persist generated images.</p><h2 class=\"mt-6 text-lg font-semibold\">Dynamic QR codes</h2><p class=\"mt-2\">If you create a dynamic QR code, I store its destination URL, settings and a hash of its owner key so the short link can redirect. Deleting the code removes them.</p><p class=\"mt-2\">When someone scans a dynamic QR code, only aggregate counters are updated: the number of scans per day, a coarse device class (mobile, tablet, desktop) and the country. The country is looked up from the IP address on the server, and the address itself is never stored. No cookies or per-visitor identifiers are used.</p><p class=\"mt-2\">To slow down guessing, failed passcode attempts on protected codes are limited per visitor using a hash of the IP address that is kept only in memory, for a few minutes.</p><h2 class=\"mt-6 text-lg font-semibold\">Third‑party services</h2><p class=\"mt-2\">The site is served through my infrastructure with a reverse proxy for TLS/edge routing. No analytics or advertising trackers are included.</p><h2 class=\"mt-6 text-lg font-semibold\">Contact</h2><p class=\"mt-2\">Questions or feedback? Reach me on <a class=\"underline\" href=\"https://github.com/cristianadrielbraun\" target=\"_blank\" rel=\"noopener\">GitHub</a>, <a class=\"underline\" href=\"https://twitter.com/MonitoBraun\" target=\"_blank\" rel=\"noopener\">Twitter</a>, or <a class=\"underline\" href=\"https://bsky.app/profile/monitobraun.bsky.social\" target=\"_blank\" rel=\"noopener\">Bluesky</a>.</p></section>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}